@@ -0,0 +1,176 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/stakater/alert-az-do/pkg/alertmanager"
+	"github.com/stakater/alert-az-do/pkg/azure"
+	"github.com/stakater/alert-az-do/pkg/config"
+	"github.com/stakater/alert-az-do/pkg/notify"
+	"github.com/stakater/alert-az-do/pkg/poller"
+	"github.com/stakater/alert-az-do/pkg/template"
+)
+
+const (
+	// defaultSLAInterval is how often overdue work items are looked up when escalation.sla.interval is not set.
+	defaultSLAInterval = 5 * time.Minute
+
+	// defaultReconcileInterval is how often work items are reconciled when reconcile.interval is not set.
+	defaultReconcileInterval = 10 * time.Minute
+
+	// defaultPollingInterval is how often alert groups are polled when polling.interval is not set.
+	defaultPollingInterval = time.Minute
+)
+
+// startEscalators starts a background evaluator for every receiver with an escalation SLA.
+func startEscalators(ctx context.Context, logger log.Logger, config *config.Config, tmpl *template.Template) {
+	for _, conf := range config.Receivers {
+		if conf.Escalation == nil || conf.Escalation.SLA == nil {
+			continue
+		}
+		interval := defaultSLAInterval
+		if conf.Escalation.SLA.Interval != nil {
+			interval = *conf.Escalation.SLA.Interval
+		}
+		level.Info(logger).Log("msg", "starting SLA escalation", "receiver", conf.Name, "interval", interval)
+		for _, conf := range conf.TargetConfigs() {
+			conf := conf
+			go runEvery(ctx, interval, func() {
+				logger := targetLogger(logger, conf)
+				receiver, err := newReceiver(ctx, logger, conf, tmpl, nil)
+				if err == nil {
+					err = receiver.EscalateOverdue(ctx)
+				}
+				if err != nil {
+					level.Error(logger).Log("msg", "failed to escalate overdue work items", "err", err)
+					slaEvaluationsTotal.WithLabelValues(conf.Name, "failure").Inc()
+					return
+				}
+				slaEvaluationsTotal.WithLabelValues(conf.Name, "success").Inc()
+			})
+		}
+	}
+}
+
+// startReconcilers starts a background reconciler for every receiver with reconciliation enabled.
+func startReconcilers(ctx context.Context, logger log.Logger, config *config.Config, tmpl *template.Template, lister notify.AlertLister) {
+	for _, conf := range config.Receivers {
+		if conf.Reconcile == nil {
+			continue
+		}
+		interval := defaultReconcileInterval
+		if conf.Reconcile.Interval != nil {
+			interval = *conf.Reconcile.Interval
+		}
+		level.Info(logger).Log("msg", "starting reconciliation", "receiver", conf.Name, "interval", interval)
+		for _, conf := range conf.TargetConfigs() {
+			conf := conf
+			go runEvery(ctx, interval, func() {
+				logger := targetLogger(logger, conf)
+				receiver, err := newReceiver(ctx, logger, conf, tmpl, nil)
+				if err != nil {
+					level.Error(logger).Log("msg", "failed to reconcile work items", "err", err)
+					return
+				}
+				resolved, err := receiver.Reconcile(ctx, lister)
+				if err != nil {
+					level.Error(logger).Log("msg", "failed to reconcile work items", "err", err)
+				}
+				if resolved > 0 {
+					level.Warn(logger).Log("msg", "resolved work items whose alerts are gone", "count", resolved)
+				}
+				reconcileDrift.WithLabelValues(conf.Name).Observe(float64(resolved))
+			})
+		}
+	}
+}
+
+// startPoller starts polling the alert groups of the configured receivers from Alertmanager, dispatching them like
+// webhook notifications.
+func startPoller(ctx context.Context, logger log.Logger, config *config.Config, tmpl *template.Template, lister poller.GroupLister) {
+	if config.Polling == nil {
+		return
+	}
+	receivers := config.Polling.Receivers
+	if len(receivers) == 0 {
+		for _, conf := range config.Receivers {
+			receivers = append(receivers, conf.Name)
+		}
+	}
+	interval := defaultPollingInterval
+	if config.Polling.Interval != nil {
+		interval = *config.Polling.Interval
+	}
+
+	level.Info(logger).Log("msg", "starting polling", "receivers", strings.Join(receivers, ","), "interval", interval)
+	p := poller.New(log.With(logger, "mode", "polling"), lister, receivers, config.Alertmanager.URL, func(ctx context.Context, data *alertmanager.Data) error {
+		receiver, status, err := dispatch(ctx, logger, config, tmpl, data, true)
+		requestTotal.WithLabelValues(receiver, strconv.Itoa(status)).Inc()
+		return err
+	})
+	go p.Run(ctx, interval)
+}
+
+// targetLogger returns the logger for the receiver or, for receivers with targets, for one of their targets.
+func targetLogger(logger log.Logger, conf *config.ReceiverConfig) log.Logger {
+	logger = log.With(logger, "receiver", conf.Name)
+	if conf.Target != "" {
+		logger = log.With(logger, "target", conf.Target)
+	}
+	return logger
+}
+
+// runEvery calls fn every interval until the context is done.
+func runEvery(ctx context.Context, interval time.Duration, fn func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			fn()
+		}
+	}
+}
+
+// newReceiver creates the receiver for the configuration with the receiver's templates, accessing Azure DevOps with the
+// organization and credentials rendered with the notification. Without notification, e.g. in background tasks, they must not be templated.
+func newReceiver(ctx context.Context, logger log.Logger, conf *config.ReceiverConfig, tmpl *template.Template, data *alertmanager.Data) (*notify.Receiver, error) {
+	tmpl = tmpl.ForReceiver(conf.Name)
+	conf, err := conf.Access(func(text string) (string, error) {
+		return tmpl.Execute(text, data)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "resolve Azure DevOps access")
+	}
+	conn, err := azure.GetConnection(ctx, logger, conf)
+	if err != nil {
+		return nil, err
+	}
+	receiver := notify.NewReceiver(ctx, logger, conf, tmpl, conn)
+	if receiver == nil {
+		return nil, errors.New("failed to create receiver")
+	}
+	return receiver, nil
+}
@@ -16,17 +16,24 @@ package main
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
 	"html/template"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
 	"github.com/stakater/alert-az-do/pkg/alertmanager"
-	"github.com/stakater/alert-az-do/pkg/azure"
+	"github.com/stakater/alert-az-do/pkg/azuremonitor"
+	"github.com/stakater/alert-az-do/pkg/cloudevents"
 	"github.com/stakater/alert-az-do/pkg/config"
+	"github.com/stakater/alert-az-do/pkg/input"
 	"github.com/stakater/alert-az-do/pkg/notify"
+	"github.com/stakater/alert-az-do/pkg/schema"
 	tmpl "github.com/stakater/alert-az-do/pkg/template"
 
 	_ "net/http/pprof"
@@ -58,6 +65,7 @@ const (
         <div class="navbar">
           <div class="navbar-header"><a href="/">alert-az-do</a></div>
           <div><a href="/config">Configuration</a></div>
+          <div><a href="/config/schema">Schema</a></div>
           <div><a href="/metrics">Metrics</a></div>
           <div><a href="/debug/pprof">Profiling</a></div>
           <div><a href="{{ .DocsURL }}">Help</a></div>
@@ -142,6 +150,27 @@ func ConfigHandlerFunc(config *config.Config) func(http.ResponseWriter, *http.Re
 	}
 }
 
+// ConfigSchemaHandlerFunc is the HTTP handler for the `/config/schema` page. It outputs the JSON Schema of the
+// configuration file.
+func ConfigSchemaHandlerFunc(logger log.Logger) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("only GET allowed"))
+			return
+		}
+
+		b, err := schema.JSON()
+		if err != nil {
+			level.Error(logger).Log("msg", "error generating configuration schema", "err", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/schema+json")
+		_, _ = w.Write(b)
+	}
+}
+
 func AlertHandlerFunc(ctx context.Context, logger log.Logger, config *config.Config, tmpl *tmpl.Template) func(w http.ResponseWriter, req *http.Request) {
 	return func(w http.ResponseWriter, req *http.Request) {
 		level.Debug(logger).Log("msg", "handling /alert webhook request")
@@ -153,25 +182,317 @@ func AlertHandlerFunc(ctx context.Context, logger log.Logger, config *config.Con
 			errorHandler(w, http.StatusBadRequest, err, unknownReceiver, &data, logger)
 			return
 		}
+		if data.IsGrafana() {
+			level.Debug(logger).Log("msg", "  Grafana unified alerting payload", "orgId", data.OrgID, "state", data.State)
+		}
 
-		conf := config.ReceiverByName(data.Receiver)
-		if conf == nil {
-			errorHandler(w, http.StatusNotFound, fmt.Errorf("receiver missing: %s", data.Receiver), unknownReceiver, &data, logger)
+		receiver, status, err := dispatch(ctx, logger, config, tmpl, &data, true)
+		if err != nil {
+			errorHandler(w, status, err, receiver, &data, logger)
+			return
+		}
+		requestTotal.WithLabelValues(receiver, "200").Inc()
+	}
+}
+
+// AzureMonitorHandlerFunc is the HTTP handler for Azure Monitor Action Group webhooks (`/alert/azure-monitor`). It
+// converts alerts in the Common Alert Schema and notifies the receiver named by the `receiver` query parameter.
+func AzureMonitorHandlerFunc(ctx context.Context, logger log.Logger, config *config.Config, tmpl *tmpl.Template) func(w http.ResponseWriter, req *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		level.Debug(logger).Log("msg", "handling /alert/azure-monitor webhook request")
+		defer func() { _ = req.Body.Close() }()
+
+		if req.Method != http.MethodPost {
+			errorHandler(w, http.StatusBadRequest, fmt.Errorf("only POST allowed"), unknownReceiver, &alertmanager.Data{}, logger)
+			return
+		}
+		name := req.URL.Query().Get("receiver")
+		if name == "" {
+			errorHandler(w, http.StatusBadRequest, fmt.Errorf("missing receiver query parameter"), unknownReceiver, &alertmanager.Data{}, logger)
+			return
+		}
+
+		payload := azuremonitor.Payload{}
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+			errorHandler(w, http.StatusBadRequest, err, unknownReceiver, &alertmanager.Data{}, logger)
+			return
+		}
+		data, err := payload.ToData(name)
+		if err != nil {
+			errorHandler(w, http.StatusBadRequest, err, unknownReceiver, &alertmanager.Data{}, logger)
+			return
+		}
+
+		receiver, status, err := dispatch(ctx, logger, config, tmpl, data, false)
+		if err != nil {
+			errorHandler(w, status, err, receiver, data, logger)
+			return
+		}
+		requestTotal.WithLabelValues(receiver, "200").Inc()
+	}
+}
+
+// InputHandlerFunc is the HTTP handler for generic JSON webhook inputs (`/input/<name>`). It maps the posted JSON
+// document with the field mapping of the named input and notifies the input's receiver.
+func InputHandlerFunc(ctx context.Context, logger log.Logger, config *config.Config, tmpl *tmpl.Template, inputs map[string]*input.Input) func(w http.ResponseWriter, req *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		level.Debug(logger).Log("msg", "handling /input webhook request", "path", req.URL.Path)
+		defer func() { _ = req.Body.Close() }()
+
+		if req.Method != http.MethodPost {
+			errorHandler(w, http.StatusBadRequest, fmt.Errorf("only POST allowed"), unknownReceiver, &alertmanager.Data{}, logger)
+			return
+		}
+		name := strings.TrimPrefix(req.URL.Path, "/input/")
+		in, ok := inputs[name]
+		if !ok {
+			errorHandler(w, http.StatusNotFound, fmt.Errorf("input missing: %s", name), unknownReceiver, &alertmanager.Data{}, logger)
+			return
+		}
+
+		data, err := in.ToData(req.Body)
+		if err != nil {
+			errorHandler(w, http.StatusBadRequest, err, in.Receiver(), &alertmanager.Data{}, logger)
+			return
+		}
+
+		receiver, status, err := dispatch(ctx, logger, config, tmpl, data, false)
+		if err != nil {
+			errorHandler(w, status, err, receiver, data, logger)
+			return
+		}
+		requestTotal.WithLabelValues(receiver, "200").Inc()
+	}
+}
+
+// CloudEventsHandlerFunc is the HTTP handler for CloudEvents (`/cloudevents`), in binary and structured content mode.
+// It answers the CloudEvents webhook validation handshake, and notifies the receiver the event is addressed to.
+// Malformed events and events of unknown receivers are answered with 400, unsupported content modes and data content
+// types with 415, events that failed to be delivered with 5xx and processed events with 204, as defined by the
+// CloudEvents HTTP webhook specification.
+func CloudEventsHandlerFunc(ctx context.Context, logger log.Logger, config *config.Config, tmpl *tmpl.Template) func(w http.ResponseWriter, req *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		level.Debug(logger).Log("msg", "handling /cloudevents request")
+		defer func() { _ = req.Body.Close() }()
+
+		switch req.Method {
+		case http.MethodOptions:
+			// Abuse protection handshake, see
+			// https://github.com/cloudevents/spec/blob/v1.0.2/cloudevents/http-webhook.md#4-abuse-protection
+			w.Header().Set("Allow", "POST")
+			if origin := req.Header.Get("WebHook-Request-Origin"); origin != "" {
+				w.Header().Set("WebHook-Allowed-Origin", origin)
+				w.Header().Set("WebHook-Allowed-Rate", "*")
+			}
+			return
+		case http.MethodPost:
+		default:
+			w.Header().Set("Allow", "OPTIONS, POST")
+			errorHandler(w, http.StatusMethodNotAllowed, fmt.Errorf("only OPTIONS and POST allowed"), unknownReceiver, &alertmanager.Data{}, logger)
 			return
 		}
-		level.Debug(logger).Log("msg", "  matched receiver", "receiver", conf.Name)
 
-		conn, err := azure.GetConnection(ctx, logger, conf)
+		event, err := cloudevents.ReadRequest(req)
 		if err != nil {
-			errorHandler(w, http.StatusInternalServerError, err, conf.Name, &data, logger)
+			status := http.StatusBadRequest
+			if errors.Cause(err) == cloudevents.ErrUnsupportedMediaType {
+				status = http.StatusUnsupportedMediaType
+			}
+			errorHandler(w, status, err, unknownReceiver, &alertmanager.Data{}, logger)
 			return
 		}
+		name := event.Receiver(config.CloudEvents)
+		if name == "" {
+			errorHandler(w, http.StatusBadRequest, fmt.Errorf("missing %s extension attribute", config.CloudEvents.ReceiverExtension), unknownReceiver, &alertmanager.Data{}, logger)
+			return
+		}
+		data, err := event.ToData(config.CloudEvents, name)
+		if err != nil {
+			errorHandler(w, http.StatusBadRequest, err, unknownReceiver, &alertmanager.Data{}, logger)
+			return
+		}
+
+		receiver, status, err := dispatch(ctx, logger, config, tmpl, data, false)
+		if err != nil {
+			errorHandler(w, cloudEventsStatus(status), err, receiver, data, logger)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		requestTotal.WithLabelValues(receiver, "204").Inc()
+	}
+}
+
+// cloudEventsStatus returns the status answering a CloudEvent that failed to be dispatched with the given status.
+// Unlike Alertmanager, which is answered with 400 on failed notifications so that it does not notify again, CloudEvents
+// senders drop events answered with 4xx and retry those answered with 5xx: only events of unknown receivers are
+// rejected, and failures to deliver the event to Azure DevOps are answered with 503.
+func cloudEventsStatus(status int) int {
+	switch {
+	case status == http.StatusNotFound:
+		return http.StatusBadRequest
+	case status >= http.StatusInternalServerError:
+		return status
+	default:
+		return http.StatusServiceUnavailable
+	}
+}
+
+// dispatch notifies the receivers of the notification, see receiversFor. It is shared by the webhook and the polling
+// mode, and returns the names of the receivers and, on error, the HTTP status to report. When one receiver fails, the
+// others are notified nonetheless.
+func dispatch(ctx context.Context, logger log.Logger, config *config.Config, tmpl *tmpl.Template, data *alertmanager.Data, routed bool) (string, int, error) {
+	confs := receiversFor(config, data, routed)
+	if len(confs) == 0 {
+		return unknownReceiver, http.StatusNotFound, fmt.Errorf("receiver missing: %s", data.Receiver)
+	}
 
-		if err := notify.NewReceiver(ctx, logger, conf, tmpl, conn).Notify(ctx, &data); err != nil {
+	var (
+		names  []string
+		status = http.StatusOK
+		errs   []string
+	)
+	for _, conf := range confs {
+		level.Debug(logger).Log("msg", "  matched receiver", "receiver", conf.Name)
+		names = append(names, conf.Name)
+
+		filtered, dropped := notify.Filter(conf, data)
+		for reason, n := range dropped {
+			filteredAlertsTotal.WithLabelValues(conf.Name, reason).Add(float64(n))
+		}
+		if filtered == nil {
+			level.Debug(logger).Log("msg", "  all alerts filtered out", "receiver", conf.Name)
+			continue
+		}
+		if len(conf.Targets) > 0 {
+			if err := notifyTargets(ctx, logger, conf, tmpl, filtered); err != nil {
+				// Failed targets were retried already, Alertmanager should not retry the others.
+				if status == http.StatusOK {
+					status = http.StatusBadRequest
+				}
+				errs = append(errs, fmt.Sprintf("receiver %q: %s", conf.Name, err))
+			}
+			continue
+		}
+
+		receiver, err := newReceiver(ctx, logger, conf, tmpl, filtered)
+		if err != nil {
+			status = http.StatusInternalServerError
+			errs = append(errs, fmt.Sprintf("receiver %q: %s", conf.Name, err))
+			continue
+		}
+		if err := receiver.Notify(ctx, filtered); err != nil {
 			// Inaccurate, just letting Alertmanager know that it should not retry.
-			errorHandler(w, http.StatusBadRequest, err, conf.Name, &data, logger)
+			if status == http.StatusOK {
+				status = http.StatusBadRequest
+			}
+			errs = append(errs, fmt.Sprintf("receiver %q: %s", conf.Name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return strings.Join(names, ","), status, errors.New(strings.Join(errs, "; "))
+	}
+	return strings.Join(names, ","), http.StatusOK, nil
+}
+
+// receiversFor returns the receivers of the notification. Notifications of Alertmanager, by webhook or polling, are
+// routed by the routing tree, whereas notifications addressed to a receiver explicitly, by Azure Monitor, inputs and
+// CloudEvents, are handled by that receiver only.
+func receiversFor(cfg *config.Config, data *alertmanager.Data, routed bool) []*config.ReceiverConfig {
+	if !routed {
+		if conf := cfg.ReceiverByName(data.Receiver); conf != nil {
+			return []*config.ReceiverConfig{conf}
+		}
+		return nil
+	}
+	labels := alertmanager.KV{}
+	for k, v := range data.GroupLabels {
+		labels[k] = v
+	}
+	for k, v := range data.CommonLabels {
+		labels[k] = v
+	}
+	return cfg.ReceiversFor(data.Receiver, labels)
+}
+
+// notifyTargets notifies all targets of the receiver, counting the notifications of every target.
+func notifyTargets(ctx context.Context, logger log.Logger, conf *config.ReceiverConfig, tmpl *tmpl.Template, data *alertmanager.Data) error {
+	err := notify.NotifyTargets(ctx, log.With(logger, "receiver", conf.Name), conf, data, func(ctx context.Context, logger log.Logger, conf *config.ReceiverConfig) (*notify.Receiver, error) {
+		return newReceiver(ctx, logger, conf, tmpl, data)
+	})
+	var failed map[string]error
+	if targetsErr, ok := err.(*notify.TargetsError); ok {
+		failed = targetsErr.Failed
+	}
+	for _, target := range conf.Targets {
+		result := "success"
+		if _, ok := failed[target.Name]; ok {
+			result = "failure"
+		}
+		targetNotificationsTotal.WithLabelValues(conf.Name, target.Name, result).Inc()
+	}
+	return err
+}
+
+// ServiceHookHandlerFunc is the HTTP handler for Azure DevOps service hooks (`/hooks/azure-devops`). It creates and
+// expires Alertmanager silences on "workitem.updated" events of work items created by alert-az-do.
+func ServiceHookHandlerFunc(ctx context.Context, logger log.Logger, config *config.Config, tmpl *tmpl.Template, silencer notify.Silencer) func(w http.ResponseWriter, req *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		level.Debug(logger).Log("msg", "handling /hooks/azure-devops request")
+		defer func() { _ = req.Body.Close() }()
+
+		if req.Method != http.MethodPost {
+			hookErrorHandler(w, http.StatusBadRequest, fmt.Errorf("only POST allowed"), unknownReceiver, logger)
+			return
+		}
+		if hook := config.ServiceHook; hook != nil && hook.Username != "" {
+			username, password, ok := req.BasicAuth()
+			if !ok || subtle.ConstantTimeCompare([]byte(username), []byte(hook.Username)) != 1 ||
+				subtle.ConstantTimeCompare([]byte(password), []byte(hook.Password)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Basic realm="alert-az-do"`)
+				hookErrorHandler(w, http.StatusUnauthorized, fmt.Errorf("invalid credentials"), unknownReceiver, logger)
+				return
+			}
+		}
+
+		event := notify.WorkItemUpdatedEvent{}
+		if err := json.NewDecoder(req.Body).Decode(&event); err != nil {
+			hookErrorHandler(w, http.StatusBadRequest, err, unknownReceiver, logger)
 			return
 		}
-		requestTotal.WithLabelValues(conf.Name, "200").Inc()
+		if event.EventType != notify.WorkItemUpdatedEventType {
+			level.Debug(logger).Log("msg", "ignoring service hook event", "eventType", event.EventType)
+			return
+		}
+
+		// Work items not created by alert-az-do, or by a receiver without silences, are of no interest.
+		conf := config.ReceiverByName(event.ReceiverName())
+		if conf == nil || conf.Silence == nil {
+			level.Debug(logger).Log("msg", "ignoring update of untracked work item", "id", event.Resource.WorkItemID)
+			return
+		}
+
+		// Work items of receivers with targets are updated in the target of their project.
+		project, _ := event.Resource.Revision.Fields[notify.WorkItemFieldTeamProject.String()].(string)
+		if conf = conf.TargetByProject(project); conf == nil {
+			level.Debug(logger).Log("msg", "ignoring update of work item outside of the receiver's targets", "id", event.Resource.WorkItemID, "project", project)
+			return
+		}
+
+		receiver, err := newReceiver(ctx, logger, conf, tmpl, nil)
+		if err != nil {
+			hookErrorHandler(w, http.StatusInternalServerError, err, conf.Name, logger)
+			return
+		}
+		if err := receiver.HandleWorkItemUpdate(ctx, &event, silencer); err != nil {
+			hookErrorHandler(w, http.StatusInternalServerError, err, conf.Name, logger)
+			return
+		}
+		serviceHookTotal.WithLabelValues(conf.Name, "200").Inc()
 	}
 }
+
+func hookErrorHandler(w http.ResponseWriter, status int, err error, receiver string, logger log.Logger) {
+	http.Error(w, err.Error(), status)
+	level.Error(logger).Log("msg", "error handling service hook", "statusCode", status, "statusText", http.StatusText(status), "err", err, "receiver", receiver)
+	serviceHookTotal.WithLabelValues(receiver, strconv.FormatInt(int64(status), 10)).Inc()
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/stakater/alert-az-do/pkg/alertmanager"
+	"github.com/stakater/alert-az-do/pkg/config"
+	"github.com/stakater/alert-az-do/pkg/template"
+	"github.com/stretchr/testify/require"
+)
+
+const routedConfigYAML = `
+defaults:
+  organization: test-org
+  personal_access_token: test-token
+  project: test-project
+  issue_type: Bug
+  summary: Test Summary
+  reopen_state: Active
+  reopen_duration: 5m
+
+receivers:
+  - name: monitor
+  - name: secops
+template: test.tmpl
+route:
+  routes:
+    - matchers: ['category="security"']
+      receiver: secops
+`
+
+func TestReceiversFor(t *testing.T) {
+	cfg, err := config.Load(routedConfigYAML)
+	require.NoError(t, err)
+
+	data := &alertmanager.Data{
+		Receiver:     "monitor",
+		CommonLabels: alertmanager.KV{"category": "security"},
+	}
+
+	tests := []struct {
+		name     string
+		receiver string
+		routed   bool
+		expected []string
+	}{
+		{"Alertmanager notification is routed", "monitor", true, []string{"secops"}},
+		{"addressed notification is not routed", "monitor", false, []string{"monitor"}},
+		{"unknown addressed receiver", "unknown", false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data.Receiver = tt.receiver
+			var names []string
+			for _, conf := range receiversFor(cfg, data, tt.routed) {
+				names = append(names, conf.Name)
+			}
+			require.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestCloudEventsHandlerFunc_UnknownReceiver(t *testing.T) {
+	cfg, err := config.Load(routedConfigYAML)
+	require.NoError(t, err)
+	cfg.CloudEvents = &config.CloudEventsConfig{ReceiverExtension: "receiver"}
+	handler := CloudEventsHandlerFunc(context.Background(), log.NewNopLogger(), cfg, template.SimpleTemplate())
+
+	req := httptest.NewRequest(http.MethodPost, "/cloudevents", strings.NewReader(`{"alertname": "DiskFull"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("ce-specversion", "1.0")
+	req.Header.Set("ce-type", "com.example.disk.firing")
+	req.Header.Set("ce-source", "https://monitor.example.com/checks")
+	req.Header.Set("ce-id", "1234")
+	req.Header.Set("ce-receiver", "unknown")
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestCloudEventsStatus(t *testing.T) {
+	require.Equal(t, http.StatusBadRequest, cloudEventsStatus(http.StatusNotFound))
+	// Failed notifications, answered with 400 to Alertmanager.
+	require.Equal(t, http.StatusServiceUnavailable, cloudEventsStatus(http.StatusBadRequest))
+	require.Equal(t, http.StatusInternalServerError, cloudEventsStatus(http.StatusInternalServerError))
+}
@@ -23,11 +23,15 @@ import (
 	"os"
 	"runtime"
 	"strconv"
+	"strings"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/stakater/alert-az-do/pkg/alertmanager"
 	"github.com/stakater/alert-az-do/pkg/config"
+	"github.com/stakater/alert-az-do/pkg/input"
+	"github.com/stakater/alert-az-do/pkg/preflight"
+	"github.com/stakater/alert-az-do/pkg/schema"
 	"github.com/stakater/alert-az-do/pkg/template"
 
 	_ "net/http/pprof"
@@ -43,10 +47,13 @@ const (
 )
 
 var (
-	listenAddress = flag.String("listen-address", ":9097", "The address to listen on for HTTP requests.")
-	configFile    = flag.String("config", "config/alert-az-do.yml", "The alert-az-do configuration file")
-	logLevel      = flag.String("log.level", "info", "Log filtering level (debug, info, warn, error)")
-	logFormat     = flag.String("log.format", logFormatLogfmt, "Log format to use ("+logFormatLogfmt+", "+logFormatJSON+")")
+	listenAddress   = flag.String("listen-address", ":9097", "The address to listen on for HTTP requests.")
+	configFile      = flag.String("config", "config/alert-az-do.yml", "The alert-az-do configuration file")
+	strictEnv       = flag.Bool("config.strict-env", false, "Fail on environment variables referenced in the configuration file without default value that are not set, instead of using empty values.")
+	preflightRun    = flag.Bool("preflight", false, "Check the receivers against Azure DevOps at startup: projects, work item types, states, fields and permissions.")
+	preflightStrict = flag.Bool("preflight.strict", false, "Refuse to start when the preflight checks find problems. Implies -preflight.")
+	logLevel        = flag.String("log.level", "info", "Log filtering level (debug, info, warn, error)")
+	logFormat       = flag.String("log.format", logFormatLogfmt, "Log format to use ("+logFormatLogfmt+", "+logFormatJSON+")")
 	//updateSummary        = flag.Bool("update-summary", true, "When false, alert-az-do does not update the summary of the existing work item, even when changes are spotted.")
 	//updateDescription    = flag.Bool("update-description", true, "When false, alert-az-do does not update the description of the existing work item, even when changes are spotted.")
 	//reopenTickets        = flag.Bool("reopen-tickets", true, "When false, alert-az-do does not reopen tickets.")
@@ -65,23 +72,91 @@ func main() {
 	flag.Parse()
 
 	var logger = setupLogger(*logLevel, *logFormat)
+
+	switch flag.Arg(0) {
+	case "dump-schema":
+		// Prints the JSON Schema of the configuration file, for editors and CI pipelines to validate configurations.
+		b, err := schema.JSON()
+		if err != nil {
+			level.Error(logger).Log("msg", "error generating configuration schema", "err", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(b))
+		return
+	}
+
 	level.Info(logger).Log("msg", "starting alert-az-do", "version", Version)
 
-	config, _, err := config.LoadFile(*configFile, logger)
+	config, _, err := config.LoadFile(*configFile, *strictEnv, logger)
 	if err != nil {
 		level.Error(logger).Log("msg", "error loading configuration", "path", *configFile, "err", err)
 		os.Exit(1)
 	}
 
-	tmpl, err := template.LoadTemplate(config.Template, logger)
+	tmpl, err := template.LoadTemplates(config.Template, logger)
 	if err != nil {
-		level.Error(logger).Log("msg", "error loading templates", "path", config.Template, "err", err)
+		level.Error(logger).Log("msg", "error loading templates", "path", strings.Join(config.Template, ","), "err", err)
 		os.Exit(1)
 	}
+	for _, rc := range config.Receivers {
+		if len(rc.Template) > 0 {
+			if err := tmpl.AddReceiver(rc.Name, rc.Template); err != nil {
+				level.Error(logger).Log("msg", "error loading receiver templates", "receiver", rc.Name, "path", strings.Join(rc.Template, ","), "err", err)
+				os.Exit(1)
+			}
+		}
+	}
+
+	switch {
+	case flag.Arg(0) == "preflight":
+		// Checks the receivers and prints the results, failing when there are problems.
+		results := runPreflight(ctx, logger, config)
+		preflight.Report(os.Stdout, results)
+		if preflight.Failed(results) {
+			os.Exit(1)
+		}
+		return
+	case *preflightRun || *preflightStrict:
+		results := runPreflight(ctx, logger, config)
+		preflight.Log(logger, results)
+		if *preflightStrict && preflight.Failed(results) {
+			level.Error(logger).Log("msg", "preflight checks failed, refusing to start")
+			os.Exit(1)
+		}
+	}
+
+	startEscalators(ctx, logger, config, tmpl)
 
 	http.HandleFunc("/", HomeHandlerFunc())
 	http.HandleFunc("/alert", AlertHandlerFunc(ctx, logger, config, tmpl))
+	http.HandleFunc("/alert/azure-monitor", AzureMonitorHandlerFunc(ctx, logger, config, tmpl))
 	http.HandleFunc("/config", ConfigHandlerFunc(config))
+	http.HandleFunc("/config/schema", ConfigSchemaHandlerFunc(logger))
+	if config.CloudEvents != nil {
+		http.HandleFunc("/cloudevents", CloudEventsHandlerFunc(ctx, logger, config, tmpl))
+	}
+	if len(config.Inputs) > 0 {
+		inputs := map[string]*input.Input{}
+		for _, conf := range config.Inputs {
+			in, err := input.New(conf, tmpl)
+			if err != nil {
+				level.Error(logger).Log("msg", "error compiling input", "input", conf.Name, "err", err)
+				os.Exit(1)
+			}
+			inputs[conf.Name] = in
+		}
+		http.HandleFunc("/input/", InputHandlerFunc(ctx, logger, config, tmpl, inputs))
+	}
+	if config.Alertmanager != nil {
+		am, err := alertmanager.NewClient(config.Alertmanager.URL, nil)
+		if err != nil {
+			level.Error(logger).Log("msg", "error creating Alertmanager client", "url", config.Alertmanager.URL, "err", err)
+			os.Exit(1)
+		}
+		http.HandleFunc("/hooks/azure-devops", ServiceHookHandlerFunc(ctx, logger, config, tmpl, am))
+		startReconcilers(ctx, logger, config, tmpl, am)
+		startPoller(ctx, logger, config, tmpl, am)
+	}
 	http.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) { http.Error(w, "OK", http.StatusOK) })
 	http.Handle("/metrics", promhttp.Handler())
 
@@ -97,6 +172,11 @@ func main() {
 	}
 }
 
+// runPreflight checks the receivers of the configuration against Azure DevOps.
+func runPreflight(ctx context.Context, logger log.Logger, config *config.Config) []*preflight.Result {
+	return preflight.Run(ctx, logger, config, preflight.Connect)
+}
+
 func errorHandler(w http.ResponseWriter, status int, err error, receiver string, data *alertmanager.Data, logger log.Logger) {
 	w.WriteHeader(status)
 
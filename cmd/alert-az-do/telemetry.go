@@ -24,8 +24,43 @@ var (
 		},
 		[]string{"receiver", "code"},
 	)
+	serviceHookTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "alert_az_do_service_hooks_total",
+			Help: "Azure DevOps service hooks processed, by receiver and status code.",
+		},
+		[]string{"receiver", "code"},
+	)
+	slaEvaluationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "alert_az_do_sla_evaluations_total",
+			Help: "Evaluations of escalation SLAs, by receiver and result.",
+		},
+		[]string{"receiver", "result"},
+	)
+	reconcileDrift = prometheus.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Name: "alert_az_do_reconcile_drift",
+			Help: "Open work items resolved by reconciliation because their alerts were gone, per run and by receiver.",
+		},
+		[]string{"receiver"},
+	)
+	filteredAlertsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "alert_az_do_filtered_alerts_total",
+			Help: "Alerts left out of notifications by the receiver filters and relabel configs, by receiver and reason.",
+		},
+		[]string{"receiver", "reason"},
+	)
+	targetNotificationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "alert_az_do_target_notifications_total",
+			Help: "Notifications of the targets of receivers, by receiver, target and result.",
+		},
+		[]string{"receiver", "target", "result"},
+	)
 )
 
 func init() {
-	prometheus.MustRegister(requestTotal)
+	prometheus.MustRegister(requestTotal, serviceHookTotal, slaEvaluationsTotal, reconcileDrift, filteredAlertsTotal, targetNotificationsTotal)
 }
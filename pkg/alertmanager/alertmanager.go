@@ -19,6 +19,7 @@ package alertmanager
 
 import (
 	"fmt"
+	"hash/fnv"
 	"sort"
 	"time"
 )
@@ -32,6 +33,9 @@ const (
 
 	// AlertResolved is the status value for a resolved alert.
 	AlertResolved = "resolved"
+
+	// labelSeparator separates label names and values when computing fingerprints.
+	labelSeparator = byte(255)
 )
 
 // Pair is a key/value string pair.
@@ -112,6 +116,46 @@ func (kv KV) Values() []string {
 	return kv.SortedPairs().Values()
 }
 
+// Fingerprint returns the fingerprint of the label set, as computed by Alertmanager for the labels of an alert: the
+// FNV-1a hash of the sorted label names and values.
+func (kv KV) Fingerprint() string {
+	names := make([]string, 0, len(kv))
+	for name := range kv {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := fnv.New64a()
+	for _, name := range names {
+		_, _ = h.Write([]byte(name))
+		_, _ = h.Write([]byte{labelSeparator})
+		_, _ = h.Write([]byte(kv[name]))
+		_, _ = h.Write([]byte{labelSeparator})
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// CommonKV returns the key/value pairs shared by all sets.
+func CommonKV(sets []KV) KV {
+	res := KV{}
+	if len(sets) == 0 {
+		return res
+	}
+	for k, v := range sets[0] {
+		common := true
+		for _, set := range sets[1:] {
+			if set[k] != v {
+				common = false
+				break
+			}
+		}
+		if common {
+			res[k] = v
+		}
+	}
+	return res
+}
+
 // Data is the data passed to notification templates and webhook pushes.
 //
 // End-users should not be exposed to Go's type system, as this will confuse them and prevent
@@ -130,6 +174,19 @@ type Data struct {
 	CommonAnnotations KV `json:"commonAnnotations"`
 
 	ExternalURL string `json:"externalURL"`
+
+	// Fields only sent by Grafana unified alerting contact points.
+	OrgID           int64  `json:"orgId,omitempty"`
+	State           string `json:"state,omitempty"`
+	Title           string `json:"title,omitempty"`
+	Message         string `json:"message,omitempty"`
+	TruncatedAlerts int    `json:"truncatedAlerts,omitempty"`
+}
+
+// IsGrafana reports whether the notification was sent by a Grafana unified alerting contact point rather than by
+// Alertmanager.
+func (d *Data) IsGrafana() bool {
+	return d.OrgID != 0 || d.State != "" || d.Title != ""
 }
 
 // Alert holds one alert for notification templates.
@@ -141,6 +198,15 @@ type Alert struct {
 	EndsAt       time.Time `json:"endsAt"`
 	GeneratorURL string    `json:"generatorURL"`
 	Fingerprint  string    `json:"fingerprint"`
+
+	// Fields only sent by Grafana unified alerting contact points: the evaluated values of the rule's expressions
+	// by reference ID, their textual form, and links to the dashboard, panel, silence form and screenshot.
+	Values       map[string]float64 `json:"values,omitempty"`
+	ValueString  string             `json:"valueString,omitempty"`
+	DashboardURL string             `json:"dashboardURL,omitempty"`
+	PanelURL     string             `json:"panelURL,omitempty"`
+	SilenceURL   string             `json:"silenceURL,omitempty"`
+	ImageURL     string             `json:"imageURL,omitempty"`
 }
 
 // Alerts is a list of Alert objects.
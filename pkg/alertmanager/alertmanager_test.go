@@ -504,3 +504,87 @@ func TestAlerts_FingerprintsOnlyResolved(t *testing.T) {
 	require.Equal(t, allFingerprints, resolvedFingerprints)
 	require.Empty(t, firingFingerprints)
 }
+
+func TestData_Grafana_Unmarshal(t *testing.T) {
+	payload := `{
+  "receiver": "grafana",
+  "status": "firing",
+  "orgId": 1,
+  "alerts": [{
+    "status": "firing",
+    "labels": {"alertname": "HighCPU", "grafana_folder": "Infra"},
+    "annotations": {"summary": "CPU usage above 80%"},
+    "startsAt": "2023-01-01T12:00:00Z",
+    "endsAt": "0001-01-01T00:00:00Z",
+    "generatorURL": "http://grafana/alerting/grafana/abc/view",
+    "fingerprint": "57c6d9296de2ad39",
+    "silenceURL": "http://grafana/alerting/silence/new",
+    "dashboardURL": "http://grafana/d/dash",
+    "panelURL": "http://grafana/d/dash?viewPanel=2",
+    "imageURL": "http://grafana/render/image.png",
+    "values": {"B": 92.5, "C": 1},
+    "valueString": "[ var='B' labels={} value=92.5 ], [ var='C' labels={} value=1 ]"
+  }],
+  "groupLabels": {"alertname": "HighCPU"},
+  "commonLabels": {"alertname": "HighCPU", "grafana_folder": "Infra"},
+  "commonAnnotations": {"summary": "CPU usage above 80%"},
+  "externalURL": "http://grafana/",
+  "version": "1",
+  "groupKey": "{}:{alertname=\"HighCPU\"}",
+  "truncatedAlerts": 2,
+  "title": "[FIRING:1] HighCPU Infra",
+  "state": "alerting",
+  "message": "**Firing**\n\nValue: B=92.5, C=1"
+}`
+
+	var data Data
+	require.NoError(t, json.Unmarshal([]byte(payload), &data))
+	require.True(t, data.IsGrafana())
+	require.Equal(t, int64(1), data.OrgID)
+	require.Equal(t, "alerting", data.State)
+	require.Equal(t, "[FIRING:1] HighCPU Infra", data.Title)
+	require.Equal(t, "**Firing**\n\nValue: B=92.5, C=1", data.Message)
+	require.Equal(t, 2, data.TruncatedAlerts)
+
+	require.Len(t, data.Alerts, 1)
+	alert := data.Alerts[0]
+	require.Equal(t, AlertFiring, alert.Status)
+	require.Equal(t, "57c6d9296de2ad39", alert.Fingerprint)
+	require.Equal(t, map[string]float64{"B": 92.5, "C": 1}, alert.Values)
+	require.Contains(t, alert.ValueString, "value=92.5")
+	require.Equal(t, "http://grafana/d/dash", alert.DashboardURL)
+	require.Equal(t, "http://grafana/d/dash?viewPanel=2", alert.PanelURL)
+	require.Equal(t, "http://grafana/alerting/silence/new", alert.SilenceURL)
+	require.Equal(t, "http://grafana/render/image.png", alert.ImageURL)
+}
+
+func TestData_Grafana_NotDetected(t *testing.T) {
+	data := &Data{
+		Receiver: "webhook",
+		Status:   AlertFiring,
+		Alerts:   Alerts{{Status: AlertFiring, Fingerprint: "abc123"}},
+	}
+	require.False(t, data.IsGrafana())
+
+	// Alertmanager payloads are marshaled without the Grafana fields, e.g. when attached to work items.
+	jsonData, err := json.Marshal(data)
+	require.NoError(t, err)
+	for _, field := range []string{"orgId", "state", "title", "message", "truncatedAlerts", "values", "valueString", "dashboardURL", "panelURL", "silenceURL", "imageURL"} {
+		require.NotContains(t, string(jsonData), `"`+field+`"`)
+	}
+}
+
+func TestCommonKV(t *testing.T) {
+	require.Equal(t, KV{}, CommonKV(nil))
+	require.Equal(t, KV{"a": "1"}, CommonKV([]KV{{"a": "1", "b": "2"}, {"a": "1", "b": "3"}}))
+}
+
+func TestKV_Fingerprint(t *testing.T) {
+	// As in github.com/prometheus/common/model, the empty label set hashes to the FNV-1a offset basis.
+	require.Equal(t, "cbf29ce484222325", KV{}.Fingerprint())
+	fingerprint := KV{"alertname": "HighCPU", "instance": "server1"}.Fingerprint()
+	require.Len(t, fingerprint, 16)
+	require.Equal(t, fingerprint, KV{"instance": "server1", "alertname": "HighCPU"}.Fingerprint())
+	require.NotEqual(t, fingerprint, KV{"alertname": "HighCPU", "instance": "server2"}.Fingerprint())
+	require.NotEqual(t, KV{"a": "bc"}.Fingerprint(), KV{"ab": "c"}.Fingerprint())
+}
@@ -0,0 +1,183 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alertmanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// GettableAlert is an alert as returned by the Alertmanager v2 API.
+type GettableAlert struct {
+	Labels       KV          `json:"labels"`
+	Annotations  KV          `json:"annotations"`
+	StartsAt     time.Time   `json:"startsAt"`
+	EndsAt       time.Time   `json:"endsAt"`
+	UpdatedAt    time.Time   `json:"updatedAt"`
+	GeneratorURL string      `json:"generatorURL"`
+	Fingerprint  string      `json:"fingerprint"`
+	Status       AlertStatus `json:"status"`
+	Receivers    []Receiver  `json:"receivers"`
+}
+
+// AlertStatus is the status of an alert as returned by the Alertmanager v2 API.
+type AlertStatus struct {
+	State       string   `json:"state"`
+	SilencedBy  []string `json:"silencedBy"`
+	InhibitedBy []string `json:"inhibitedBy"`
+}
+
+// Receiver is a receiver reference as returned by the Alertmanager v2 API.
+type Receiver struct {
+	Name string `json:"name"`
+}
+
+// AlertGroup is a group of alerts as returned by the Alertmanager v2 API.
+type AlertGroup struct {
+	Labels   KV              `json:"labels"`
+	Receiver Receiver        `json:"receiver"`
+	Alerts   []GettableAlert `json:"alerts"`
+}
+
+// Matcher matches the alerts of a silence.
+type Matcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+	IsEqual bool   `json:"isEqual"`
+}
+
+// Silence is a silence as created through the Alertmanager v2 API.
+type Silence struct {
+	ID        string    `json:"id,omitempty"`
+	Matchers  []Matcher `json:"matchers"`
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	CreatedBy string    `json:"createdBy"`
+	Comment   string    `json:"comment"`
+}
+
+// Client is a minimal client of the Alertmanager v2 API.
+type Client struct {
+	url        *url.URL
+	httpClient *http.Client
+}
+
+// NewClient creates a client of the Alertmanager at the given URL, e.g. http://alertmanager:9093.
+func NewClient(rawURL string, httpClient *http.Client) (*Client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid alertmanager url %q: %w", rawURL, err)
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{url: u, httpClient: httpClient}, nil
+}
+
+// ListAlerts returns the alerts routed to the given receiver, or all alerts when receiver is empty. Silenced and
+// inhibited alerts are included.
+func (c *Client) ListAlerts(ctx context.Context, receiver string) ([]GettableAlert, error) {
+	var alerts []GettableAlert
+	if err := c.do(ctx, http.MethodGet, "/api/v2/alerts", receiverQuery(receiver), nil, &alerts); err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// ListAlertGroups returns the alert groups of the given receiver, or all alert groups when receiver is empty.
+func (c *Client) ListAlertGroups(ctx context.Context, receiver string) ([]AlertGroup, error) {
+	var groups []AlertGroup
+	if err := c.do(ctx, http.MethodGet, "/api/v2/alerts/groups", receiverQuery(receiver), nil, &groups); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// CreateSilence creates the silence and returns its ID.
+func (c *Client) CreateSilence(ctx context.Context, silence Silence) (string, error) {
+	var res struct {
+		SilenceID string `json:"silenceID"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/api/v2/silences", nil, silence, &res); err != nil {
+		return "", err
+	}
+	return res.SilenceID, nil
+}
+
+// ExpireSilence expires the silence with the given ID.
+func (c *Client) ExpireSilence(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v2/silence/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// receiverQuery returns the query parameters selecting the alerts of exactly the given receiver, which the API
+// matches as a regular expression.
+func receiverQuery(receiver string) url.Values {
+	q := url.Values{}
+	if receiver != "" {
+		q.Set("receiver", "^"+regexp.QuoteMeta(receiver)+"$")
+	}
+	return q
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, result interface{}) error {
+	u := *c.url
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+	u.RawQuery = query.Encode()
+
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("marshal request: %w", err)
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s: unexpected status %s: %s", method, path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("%s %s: decode response: %w", method, path, err)
+	}
+	return nil
+}
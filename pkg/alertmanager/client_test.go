@@ -0,0 +1,120 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alertmanager
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_ListAlerts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		require.Equal(t, "/prefix/api/v2/alerts", r.URL.Path)
+		require.Equal(t, `^team\.a$`, r.URL.Query().Get("receiver"))
+		_, _ = w.Write([]byte(`[{"fingerprint":"fp1","labels":{"alertname":"A"},"status":{"state":"active"},"receivers":[{"name":"team.a"}]}]`))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL+"/prefix/", nil)
+	require.NoError(t, err)
+
+	alerts, err := client.ListAlerts(context.Background(), "team.a")
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	require.Equal(t, "fp1", alerts[0].Fingerprint)
+	require.Equal(t, "active", alerts[0].Status.State)
+	require.Equal(t, KV{"alertname": "A"}, alerts[0].Labels)
+}
+
+func TestClient_ListAlertGroups(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v2/alerts/groups", r.URL.Path)
+		require.Empty(t, r.URL.Query().Get("receiver"))
+		_, _ = w.Write([]byte(`[{"labels":{"alertname":"A"},"receiver":{"name":"r"},"alerts":[{"fingerprint":"fp1"}]}]`))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, nil)
+	require.NoError(t, err)
+
+	groups, err := client.ListAlertGroups(context.Background(), "")
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	require.Equal(t, "r", groups[0].Receiver.Name)
+	require.Equal(t, "fp1", groups[0].Alerts[0].Fingerprint)
+}
+
+func TestClient_CreateAndExpireSilence(t *testing.T) {
+	var created Silence
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v2/silences":
+			require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+			_, _ = w.Write([]byte(`{"silenceID":"abc-123"}`))
+		case r.Method == http.MethodDelete && r.URL.Path == "/api/v2/silence/abc-123":
+			w.WriteHeader(http.StatusOK)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, nil)
+	require.NoError(t, err)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	id, err := client.CreateSilence(context.Background(), Silence{
+		Matchers:  []Matcher{{Name: "alertname", Value: "A", IsEqual: true}},
+		StartsAt:  now,
+		EndsAt:    now.Add(time.Hour),
+		CreatedBy: "alert-az-do",
+		Comment:   "acknowledged",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "abc-123", id)
+	require.Equal(t, "alertname", created.Matchers[0].Name)
+	require.True(t, created.Matchers[0].IsEqual)
+	require.Equal(t, now.Add(time.Hour), created.EndsAt)
+
+	require.NoError(t, client.ExpireSilence(context.Background(), "abc-123"))
+
+	err = client.ExpireSilence(context.Background(), "unknown")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unexpected status 404")
+}
+
+func TestClient_ErrorPaths(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`not json`))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, nil)
+	require.NoError(t, err)
+	_, err = client.ListAlerts(context.Background(), "")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "decode response")
+
+	_, err = NewClient("://invalid", nil)
+	require.Error(t, err)
+}
@@ -18,8 +18,10 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"os"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/go-kit/log"
@@ -53,11 +55,59 @@ func (c *BasicCredential) GetToken(ctx context.Context, options policy.TokenRequ
 	return token, nil
 }
 
-func getScopes() []string {
-	return []string{"499b84ac-1321-427f-aa17-267ca6975798/.default"}
+// devOpsScope is the scope of tokens for the Azure DevOps resource.
+const devOpsScope = "499b84ac-1321-427f-aa17-267ca6975798/.default"
+
+// legacyTokenFile is the token file of workload identity without explicit token file, kept from earlier versions.
+const legacyTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// getScopes returns the scopes of the Azure DevOps tokens, the Azure DevOps resource unless configured otherwise.
+func getScopes(az *config.AzureConfig) []string {
+	if az != nil && az.Scope != "" {
+		return []string{az.Scope}
+	}
+	return []string{devOpsScope}
+}
+
+// clientOptions returns the options of the credentials authenticating in the configured cloud. Without cloud and
+// authority host, the Azure SDK uses AZURE_AUTHORITY_HOST or the public cloud.
+func clientOptions(az *config.AzureConfig) azcore.ClientOptions {
+	var options azcore.ClientOptions
+	if az == nil {
+		return options
+	}
+	switch az.Cloud {
+	case config.CloudAzurePublic:
+		options.Cloud = cloud.AzurePublic
+	case config.CloudAzureChina:
+		options.Cloud = cloud.AzureChina
+	case config.CloudAzureUSGovernment:
+		options.Cloud = cloud.AzureGovernment
+	}
+	if az.AuthorityHost != "" {
+		options.Cloud.ActiveDirectoryAuthorityHost = az.AuthorityHost
+	}
+	return options
+}
+
+// federatedTokenFile returns the configured workload identity token file, else the one of AZURE_FEDERATED_TOKEN_FILE
+// as set up by the Azure workload identity webhook, else fallback.
+func federatedTokenFile(az *config.AzureConfig, fallback string) string {
+	if az != nil && az.FederatedTokenFile != "" {
+		return az.FederatedTokenFile
+	}
+	if file := os.Getenv("AZURE_FEDERATED_TOKEN_FILE"); file != "" {
+		return file
+	}
+	return fallback
 }
 
 func GetConnection(ctx context.Context, logger log.Logger, conf *config.ReceiverConfig) (*v7.Connection, error) {
+	conf, err := ResolveSecrets(ctx, logger, conf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure DevOps client: %w", err)
+	}
+
 	// Azure credential selection with proper authentication patterns
 	cred, err := GetAuthenticationCredential(logger, conf)
 
@@ -66,11 +116,11 @@ func GetConnection(ctx context.Context, logger log.Logger, conf *config.Receiver
 	}
 
 	authPrefix := "Bearer"
-	if conf.PersonalAccessToken != "" {
+	if conf.PersonalAccessToken != "" || (conf.Auth != nil && conf.Auth.Type == config.AuthPAT) {
 		authPrefix = "Basic"
 	}
 	token, err := cred.GetToken(ctx, policy.TokenRequestOptions{
-		Scopes: getScopes(),
+		Scopes: getScopes(conf.Azure),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Azure DevOps client: %w", err)
@@ -84,25 +134,35 @@ func GetConnection(ctx context.Context, logger log.Logger, conf *config.Receiver
 	return conn, nil
 }
 
+// GetAuthenticationCredential returns the credential of the receiver's auth profile or, without profile, of the
+// authentication method implied by the credential fields that are set.
 func GetAuthenticationCredential(logger log.Logger, conf *config.ReceiverConfig) (azcore.TokenCredential, error) {
+	if conf.Auth != nil {
+		return getProfileCredential(logger, conf.Auth, conf.Azure)
+	}
+	options := clientOptions(conf.Azure)
+
 	switch true {
-	// Service Principal authentication (TenantID + ClientID + ClientSecret)
-	case conf.TenantID != "" && conf.ClientID != "" && conf.ClientSecret != "" && conf.SubscriptionID == "" && conf.PersonalAccessToken == "":
+	// Service Principal authentication (TenantID + ClientID + ClientSecret), ignoring the SubscriptionID as the
+	// configuration does
+	case conf.TenantID != "" && conf.ClientID != "" && conf.ClientSecret != "" && conf.PersonalAccessToken == "":
 		level.Debug(logger).Log("msg", "using Service Principal authentication")
-		return azidentity.NewClientSecretCredential(string(conf.TenantID), string(conf.ClientID), string(conf.ClientSecret), nil)
+		return azidentity.NewClientSecretCredential(string(conf.TenantID), string(conf.ClientID), string(conf.ClientSecret), &azidentity.ClientSecretCredentialOptions{ClientOptions: options})
 		// Workload Identity authentication (ClientID + TenantID + Service Account Token)
 	case conf.TenantID != "" && conf.ClientID != "" && conf.ClientSecret == "" && conf.SubscriptionID == "" && conf.PersonalAccessToken == "":
 		level.Debug(logger).Log("msg", "using Workload Identity authentication")
 		return azidentity.NewWorkloadIdentityCredential(&azidentity.WorkloadIdentityCredentialOptions{
+			ClientOptions: options,
 			TenantID:      string(conf.TenantID),
 			ClientID:      string(conf.ClientID),
-			TokenFilePath: "/var/run/secrets/kubernetes.io/serviceaccount/token",
+			TokenFilePath: federatedTokenFile(conf.Azure, legacyTokenFile),
 		})
 		// Managed Identity authentication (ClientID + SubscriptionID)
 	case conf.TenantID == "" && conf.ClientID != "" && conf.ClientSecret == "" && conf.SubscriptionID != "" && conf.PersonalAccessToken == "":
 		level.Debug(logger).Log("msg", "using Managed Identity authentication")
 		return azidentity.NewManagedIdentityCredential(&azidentity.ManagedIdentityCredentialOptions{
-			ID: azidentity.ClientID(string(conf.ClientID)),
+			ClientOptions: options,
+			ID:            azidentity.ClientID(string(conf.ClientID)),
 		})
 		// Personal Access Token (PAT) authentication
 	case conf.TenantID == "" && conf.ClientID == "" && conf.ClientSecret == "" && conf.SubscriptionID == "" && conf.PersonalAccessToken != "":
@@ -113,3 +173,41 @@ func GetAuthenticationCredential(logger log.Logger, conf *config.ReceiverConfig)
 		return nil, fmt.Errorf("no valid authentication method configured")
 	}
 }
+
+// getProfileCredential returns the credential of an explicit auth profile in the Azure environment az.
+func getProfileCredential(logger log.Logger, auth *config.AuthConfig, az *config.AzureConfig) (azcore.TokenCredential, error) {
+	level.Debug(logger).Log("msg", "using authentication profile", "type", auth.Type)
+	options := clientOptions(az)
+	switch auth.Type {
+	case config.AuthServicePrincipalSecret:
+		return azidentity.NewClientSecretCredential(auth.TenantID, auth.ClientID, string(auth.ClientSecret), &azidentity.ClientSecretCredentialOptions{ClientOptions: options})
+	case config.AuthServicePrincipalCertificate:
+		cred, err := getCertificateCredential(logger, auth, options)
+		if err != nil {
+			return nil, err
+		}
+		return cred, nil
+	case config.AuthWorkloadIdentity:
+		// Empty fields are taken from the environment set up by the Azure workload identity webhook.
+		return azidentity.NewWorkloadIdentityCredential(&azidentity.WorkloadIdentityCredentialOptions{
+			ClientOptions: options,
+			TenantID:      auth.TenantID,
+			ClientID:      auth.ClientID,
+			TokenFilePath: federatedTokenFile(az, ""),
+		})
+	case config.AuthManagedIdentity:
+		miOptions := &azidentity.ManagedIdentityCredentialOptions{ClientOptions: options}
+		if auth.ClientID != "" {
+			miOptions.ID = azidentity.ClientID(auth.ClientID)
+		}
+		return azidentity.NewManagedIdentityCredential(miOptions)
+	case config.AuthAzureCLI:
+		return azidentity.NewAzureCLICredential(&azidentity.AzureCLICredentialOptions{TenantID: auth.TenantID})
+	case config.AuthPAT:
+		return NewBasicCredential("", string(auth.PersonalAccessToken))
+	case config.AuthDefaultChain:
+		return azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{ClientOptions: options, TenantID: auth.TenantID})
+	default:
+		return nil, fmt.Errorf("unknown auth type %q", auth.Type)
+	}
+}
@@ -15,10 +15,22 @@
 package azure
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/go-kit/log"
 	"github.com/stakater/alert-az-do/pkg/config"
 	"github.com/stretchr/testify/assert"
@@ -86,7 +98,7 @@ func TestBasicCredential_GetToken(t *testing.T) {
 
 	ctx := context.Background()
 	token, err := cred.GetToken(ctx, policy.TokenRequestOptions{
-		Scopes: getScopes(),
+		Scopes: getScopes(nil),
 	})
 	require.NoError(t, err)
 
@@ -186,15 +198,15 @@ func TestGetAuthenticationCredential(t *testing.T) {
 			description: "Should fail when no authentication method is configured",
 		},
 		{
-			name: "mixed_authentication_service_principal_with_subscription",
+			name: "service_principal_ignores_subscription",
 			config: &config.ReceiverConfig{
 				TenantID:       "tenant-123",
 				ClientID:       "client-123",
 				ClientSecret:   config.Secret("secret-123"),
-				SubscriptionID: "sub-123", // This creates ambiguity
+				SubscriptionID: "sub-123", // Ignored, as by the configuration
 			},
-			wantErr:     true,
-			description: "Should fail when multiple authentication methods are mixed",
+			wantErr:     false,
+			description: "Should use Service Principal and ignore the SubscriptionID",
 		},
 		{
 			name: "mixed_authentication_service_principal_with_pat",
@@ -279,9 +291,12 @@ func TestGetConnection(t *testing.T) {
 }
 
 func TestGetScopes(t *testing.T) {
-	scopes := getScopes()
+	scopes := getScopes(nil)
 	assert.Len(t, scopes, 1)
 	assert.Equal(t, "499b84ac-1321-427f-aa17-267ca6975798/.default", scopes[0])
+
+	assert.Equal(t, []string{devOpsScope}, getScopes(&config.AzureConfig{Cloud: config.CloudAzureChina}))
+	assert.Equal(t, []string{"api://devops/.default"}, getScopes(&config.AzureConfig{Scope: "api://devops/.default"}))
 }
 
 // Integration test helper to test actual authentication flow (requires environment setup)
@@ -341,10 +356,138 @@ func BenchmarkBasicCredentialGetToken(b *testing.B) {
 	b.ResetTimer()
 	for b.Loop() {
 		_, err := cred.GetToken(ctx, policy.TokenRequestOptions{
-			Scopes: getScopes(),
+			Scopes: getScopes(nil),
 		})
 		if err != nil {
 			b.Fatal(err)
 		}
 	}
 }
+
+// writeTestCertificate writes a self-signed certificate and its private key to a PEM file.
+func writeTestCertificate(t *testing.T) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "alert-az-do"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	require.NoError(t, pem.Encode(&buf, &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+	path := filepath.Join(t.TempDir(), "client.pem")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+	return path
+}
+
+func TestGetAuthenticationCredential_ServicePrincipalWithSubscription(t *testing.T) {
+	// The configuration accepts a subscription ID next to the Service Principal fields.
+	cfg, err := config.Load(`
+receivers:
+  - name: r
+    organization: test-org
+    tenant_id: tenant-123
+    client_id: client-123
+    client_secret: secret-123
+    subscription_id: sub-123
+    project: p
+    issue_type: Bug
+    summary: s
+    reopen_state: Active
+    reopen_duration: 0h
+template: test.tmpl
+`)
+	require.NoError(t, err)
+
+	cred, err := GetAuthenticationCredential(log.NewNopLogger(), cfg.Receivers[0])
+	require.NoError(t, err)
+	require.IsType(t, &azidentity.ClientSecretCredential{}, cred)
+}
+
+func TestGetAuthenticationCredential_Profiles(t *testing.T) {
+	logger := log.NewNopLogger()
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("token"), 0o600))
+	t.Setenv("AZURE_FEDERATED_TOKEN_FILE", tokenFile)
+	certificate := writeTestCertificate(t)
+
+	tests := []struct {
+		name string
+		auth *config.AuthConfig
+		err  string
+	}{
+		{name: "service principal secret", auth: &config.AuthConfig{Type: config.AuthServicePrincipalSecret, TenantID: "tenant-123", ClientID: "client-123", ClientSecret: "secret"}},
+		{name: "service principal certificate", auth: &config.AuthConfig{Type: config.AuthServicePrincipalCertificate, TenantID: "tenant-123", ClientID: "client-123", CertificatePath: certificate}},
+		{name: "missing certificate", auth: &config.AuthConfig{Type: config.AuthServicePrincipalCertificate, TenantID: "tenant-123", ClientID: "client-123", CertificatePath: certificate + ".missing"}, err: "failed to read certificate"},
+		{name: "workload identity", auth: &config.AuthConfig{Type: config.AuthWorkloadIdentity, TenantID: "tenant-123", ClientID: "client-123"}},
+		{name: "system-assigned managed identity", auth: &config.AuthConfig{Type: config.AuthManagedIdentity}},
+		{name: "user-assigned managed identity", auth: &config.AuthConfig{Type: config.AuthManagedIdentity, ClientID: "client-123"}},
+		{name: "azure cli", auth: &config.AuthConfig{Type: config.AuthAzureCLI}},
+		{name: "pat", auth: &config.AuthConfig{Type: config.AuthPAT, PersonalAccessToken: "pat-token-123"}},
+		{name: "default chain", auth: &config.AuthConfig{Type: config.AuthDefaultChain, TenantID: "tenant-123"}},
+		{name: "unknown", auth: &config.AuthConfig{Type: "password"}, err: `unknown auth type "password"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cred, err := GetAuthenticationCredential(logger, &config.ReceiverConfig{Auth: tt.auth})
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, cred)
+		})
+	}
+}
+
+func TestGetConnection_PATProfile(t *testing.T) {
+	conn, err := GetConnection(context.Background(), log.NewNopLogger(), &config.ReceiverConfig{
+		Organization: "test-org",
+		Auth:         &config.AuthConfig{Type: config.AuthPAT, PersonalAccessToken: "test-pat"},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, conn.AuthorizationString, "Basic ")
+}
+
+func TestClientOptions(t *testing.T) {
+	require.Equal(t, cloud.Configuration{}, clientOptions(nil).Cloud)
+	require.Equal(t, cloud.AzureChina, clientOptions(&config.AzureConfig{Cloud: config.CloudAzureChina}).Cloud)
+	require.Equal(t, cloud.AzureGovernment, clientOptions(&config.AzureConfig{Cloud: config.CloudAzureUSGovernment}).Cloud)
+
+	options := clientOptions(&config.AzureConfig{Cloud: config.CloudAzureUSGovernment, AuthorityHost: "https://login.example.com/"})
+	require.Equal(t, "https://login.example.com/", options.Cloud.ActiveDirectoryAuthorityHost)
+	require.Equal(t, cloud.AzureGovernment.Services, options.Cloud.Services)
+	// The cloud configurations of the Azure SDK are not modified.
+	require.Equal(t, "https://login.microsoftonline.us/", cloud.AzureGovernment.ActiveDirectoryAuthorityHost)
+
+	// Only the authority host, e.g. for an Azure Stack environment.
+	require.Equal(t, cloud.Configuration{ActiveDirectoryAuthorityHost: "https://login.example.com/"}, clientOptions(&config.AzureConfig{AuthorityHost: "https://login.example.com/"}).Cloud)
+}
+
+func TestFederatedTokenFile(t *testing.T) {
+	t.Setenv("AZURE_FEDERATED_TOKEN_FILE", "")
+	require.Equal(t, legacyTokenFile, federatedTokenFile(nil, legacyTokenFile))
+	require.Equal(t, "", federatedTokenFile(&config.AzureConfig{}, ""))
+
+	t.Setenv("AZURE_FEDERATED_TOKEN_FILE", "/var/run/secrets/azure/tokens/azure-identity-token")
+	require.Equal(t, "/var/run/secrets/azure/tokens/azure-identity-token", federatedTokenFile(nil, legacyTokenFile))
+	require.Equal(t, "/etc/token", federatedTokenFile(&config.AzureConfig{FederatedTokenFile: "/etc/token"}, legacyTokenFile))
+}
+
+func TestGetAuthenticationCredential_AzureConfig(t *testing.T) {
+	t.Setenv("AZURE_FEDERATED_TOKEN_FILE", "")
+	az := &config.AzureConfig{Cloud: config.CloudAzureChina, FederatedTokenFile: "/etc/token"}
+
+	cred, err := GetAuthenticationCredential(log.NewNopLogger(), &config.ReceiverConfig{TenantID: "tenant-123", ClientID: "client-123", Azure: az})
+	require.NoError(t, err)
+	require.NotNil(t, cred)
+
+	cred, err = GetAuthenticationCredential(log.NewNopLogger(), &config.ReceiverConfig{Auth: &config.AuthConfig{Type: config.AuthWorkloadIdentity, TenantID: "tenant-123", ClientID: "client-123"}, Azure: az})
+	require.NoError(t, err)
+	require.NotNil(t, cred)
+}
@@ -0,0 +1,161 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package azure
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/stakater/alert-az-do/pkg/config"
+)
+
+// certificateKey identifies the certificate credentials shared by receivers.
+type certificateKey struct {
+	tenantID, clientID, path, password, passwordFile, authorityHost string
+}
+
+// certificateCredentials caches the certificate credentials, so that their tokens are reused across notifications.
+var certificateCredentials = struct {
+	sync.Mutex
+	m map[certificateKey]*CertificateCredential
+}{m: map[certificateKey]*CertificateCredential{}}
+
+// CertificateCredential is a client certificate credential of a service principal, reloading the certificate when its
+// file or the file of its password changes on disk, as when a mounted Kubernetes secret is updated.
+type CertificateCredential struct {
+	logger  log.Logger
+	auth    config.AuthConfig
+	options azcore.ClientOptions
+
+	mtx     sync.Mutex
+	version string
+	cred    azcore.TokenCredential
+}
+
+// getCertificateCredential returns the cached certificate credential of the auth profile, loading the certificate
+// when it is first used.
+func getCertificateCredential(logger log.Logger, auth *config.AuthConfig, options azcore.ClientOptions) (*CertificateCredential, error) {
+	key := certificateKey{auth.TenantID, auth.ClientID, auth.CertificatePath, string(auth.CertificatePassword), auth.CertificatePasswordFile, options.Cloud.ActiveDirectoryAuthorityHost}
+	certificateCredentials.Lock()
+	defer certificateCredentials.Unlock()
+	if c, ok := certificateCredentials.m[key]; ok {
+		return c, nil
+	}
+	c, err := NewCertificateCredential(logger, auth, options)
+	if err != nil {
+		return nil, err
+	}
+	certificateCredentials.m[key] = c
+	return c, nil
+}
+
+// NewCertificateCredential returns a certificate credential of the auth profile, failing when the certificate cannot
+// be loaded.
+func NewCertificateCredential(logger log.Logger, auth *config.AuthConfig, options azcore.ClientOptions) (*CertificateCredential, error) {
+	c := &CertificateCredential{logger: logger, auth: *auth, options: options}
+	if _, err := c.credential(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// GetToken implements the azcore.TokenCredential interface.
+func (c *CertificateCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	cred, err := c.credential()
+	if err != nil {
+		return azcore.AccessToken{}, err
+	}
+	return cred.GetToken(ctx, options)
+}
+
+// credential returns the credential of the current certificate, reloading it when the files changed. When the changed
+// files cannot be loaded, e.g. while they are being written, the previous certificate is used until the next call.
+func (c *CertificateCredential) credential() (azcore.TokenCredential, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	version, err := c.fileVersion()
+	if err == nil && version == c.version {
+		return c.cred, nil
+	}
+	if err == nil {
+		var cred azcore.TokenCredential
+		if cred, err = c.load(); err == nil {
+			if c.cred != nil {
+				level.Info(c.logger).Log("msg", "reloaded client certificate", "path", c.auth.CertificatePath)
+			}
+			c.version, c.cred = version, cred
+			return cred, nil
+		}
+	}
+	if c.cred == nil {
+		return nil, err
+	}
+	level.Warn(c.logger).Log("msg", "failed to reload client certificate, using the previous one", "path", c.auth.CertificatePath, "err", err)
+	return c.cred, nil
+}
+
+// fileVersion identifies the contents of the certificate and password files by their modification times and sizes.
+// Stat follows symlinks, so the atomic updates of mounted secrets are seen as changes.
+func (c *CertificateCredential) fileVersion() (string, error) {
+	version := ""
+	for _, path := range []string{c.auth.CertificatePath, c.auth.CertificatePasswordFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read certificate: %w", err)
+		}
+		version += fmt.Sprintf("%s:%d:%d;", path, info.ModTime().UnixNano(), info.Size())
+	}
+	return version, nil
+}
+
+func (c *CertificateCredential) load() (azcore.TokenCredential, error) {
+	data, password, err := readCertificate(&c.auth)
+	if err != nil {
+		return nil, err
+	}
+	certs, key, err := azidentity.ParseCertificates(data, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate %s: %w", c.auth.CertificatePath, err)
+	}
+	return azidentity.NewClientCertificateCredential(c.auth.TenantID, c.auth.ClientID, certs, key, &azidentity.ClientCertificateCredentialOptions{ClientOptions: c.options})
+}
+
+// readCertificate reads the certificate file and its password, from the password file if set.
+func readCertificate(auth *config.AuthConfig) ([]byte, []byte, error) {
+	data, err := os.ReadFile(auth.CertificatePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read certificate: %w", err)
+	}
+	password := []byte(auth.CertificatePassword)
+	if auth.CertificatePasswordFile != "" {
+		if password, err = os.ReadFile(auth.CertificatePasswordFile); err != nil {
+			return nil, nil, fmt.Errorf("failed to read certificate password: %w", err)
+		}
+		password = bytes.TrimRight(password, "\r\n")
+	}
+	return data, password, nil
+}
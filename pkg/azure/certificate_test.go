@@ -0,0 +1,77 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package azure
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/go-kit/log"
+	"github.com/stakater/alert-az-do/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertificateCredential_Reload(t *testing.T) {
+	path := writeTestCertificate(t)
+	auth := &config.AuthConfig{Type: config.AuthServicePrincipalCertificate, TenantID: "tenant-123", ClientID: "client-123", CertificatePath: path}
+	c, err := NewCertificateCredential(log.NewNopLogger(), auth, azcore.ClientOptions{})
+	require.NoError(t, err)
+	first, err := c.credential()
+	require.NoError(t, err)
+
+	// Unchanged files are not loaded again.
+	cred, err := c.credential()
+	require.NoError(t, err)
+	require.Same(t, first, cred)
+
+	// A rotated certificate is loaded.
+	rotated, err := os.ReadFile(writeTestCertificate(t))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, rotated, 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+	second, err := c.credential()
+	require.NoError(t, err)
+	require.NotSame(t, first, second)
+
+	// A broken certificate keeps the previous one in use.
+	require.NoError(t, os.WriteFile(path, []byte("garbage"), 0o600))
+	cred, err = c.credential()
+	require.NoError(t, err)
+	require.Same(t, second, cred)
+}
+
+func TestGetCertificateCredential_Cached(t *testing.T) {
+	auth := &config.AuthConfig{Type: config.AuthServicePrincipalCertificate, TenantID: "tenant-123", ClientID: "client-123", CertificatePath: writeTestCertificate(t)}
+	first, err := getCertificateCredential(log.NewNopLogger(), auth, azcore.ClientOptions{})
+	require.NoError(t, err)
+	second, err := getCertificateCredential(log.NewNopLogger(), auth, azcore.ClientOptions{})
+	require.NoError(t, err)
+	require.Same(t, first, second)
+}
+
+func TestReadCertificate_PasswordFile(t *testing.T) {
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("s3cret\n"), 0o600))
+
+	_, password, err := readCertificate(&config.AuthConfig{CertificatePath: writeTestCertificate(t), CertificatePasswordFile: passwordFile})
+	require.NoError(t, err)
+	require.Equal(t, "s3cret", string(password))
+
+	_, _, err = readCertificate(&config.AuthConfig{CertificatePath: writeTestCertificate(t), CertificatePasswordFile: passwordFile + ".missing"})
+	require.ErrorContains(t, err, "failed to read certificate password")
+}
@@ -0,0 +1,236 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package azure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/stakater/alert-az-do/pkg/config"
+)
+
+// keyVaultAPIVersion is the version of the Key Vault REST API used for reading secrets.
+const keyVaultAPIVersion = "7.4"
+
+// keyVaultTTL is how long Key Vault secrets are cached before they are read again.
+var keyVaultTTL = 5 * time.Minute
+
+// keyVaultDomains maps the configured clouds to the domain of their key vaults.
+var keyVaultDomains = map[string]string{
+	"":                            "vault.azure.net",
+	config.CloudAzurePublic:       "vault.azure.net",
+	config.CloudAzureChina:        "vault.azure.cn",
+	config.CloudAzureUSGovernment: "vault.usgovcloudapi.net",
+}
+
+// keyVaultSecrets caches the Key Vault secrets by the identity reading them and their URL, so that a receiver does not
+// get secrets its own identity may not read.
+var keyVaultSecrets = struct {
+	sync.Mutex
+	m map[string]cachedSecret
+}{m: map[string]cachedSecret{}}
+
+type cachedSecret struct {
+	value   config.Secret
+	expires time.Time
+}
+
+// ResolveSecrets returns the configuration with the secrets set through files read, and the Key Vault references
+// replaced by their secrets. Files are read on every call, so that rotated secrets are picked up; Key Vault secrets are
+// cached for keyVaultTTL.
+func ResolveSecrets(ctx context.Context, logger log.Logger, conf *config.ReceiverConfig) (*config.ReceiverConfig, error) {
+	resolved := *conf
+	r := &secretResolver{ctx: ctx, logger: logger, conf: conf}
+	resolved.ClientSecret = r.resolve("client_secret", conf.ClientSecret, conf.ClientSecretFile)
+	resolved.PersonalAccessToken = r.resolve("personal_access_token", conf.PersonalAccessToken, conf.PersonalAccessTokenFile)
+	resolved.ClientSecretFile, resolved.PersonalAccessTokenFile = "", ""
+	if conf.Auth != nil {
+		auth := *conf.Auth
+		auth.ClientSecret = r.resolve("client_secret", conf.Auth.ClientSecret, conf.Auth.ClientSecretFile)
+		auth.PersonalAccessToken = r.resolve("personal_access_token", conf.Auth.PersonalAccessToken, conf.Auth.PersonalAccessTokenFile)
+		auth.CertificatePassword = r.resolve("certificate_password", conf.Auth.CertificatePassword, "")
+		auth.ClientSecretFile, auth.PersonalAccessTokenFile = "", ""
+		resolved.Auth = &auth
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &resolved, nil
+}
+
+// secretResolver resolves the secrets of a receiver configuration, keeping the first error.
+type secretResolver struct {
+	ctx    context.Context
+	logger log.Logger
+	conf   *config.ReceiverConfig
+	cred   azcore.TokenCredential
+	err    error
+}
+
+func (r *secretResolver) resolve(name string, secret config.Secret, file string) config.Secret {
+	if r.err != nil {
+		return ""
+	}
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			r.err = fmt.Errorf("failed to read %s: %w", name, err)
+			return ""
+		}
+		return config.Secret(bytes.TrimRight(data, "\r\n"))
+	}
+	ref, err := config.ParseKeyVaultReference(secret)
+	if err != nil {
+		r.err = err
+		return ""
+	}
+	if ref == nil {
+		return secret
+	}
+	value, err := r.keyVaultSecret(ref)
+	if err != nil {
+		r.err = fmt.Errorf("failed to read %s from Key Vault: %w", name, err)
+	}
+	return value
+}
+
+// keyVaultSecret returns the secret referenced, read with the credential returned by keyVaultCredential.
+func (r *secretResolver) keyVaultSecret(ref *config.KeyVaultReference) (config.Secret, error) {
+	cloud := ""
+	if r.conf.Azure != nil {
+		cloud = r.conf.Azure.Cloud
+	}
+	host := ref.Vault
+	if !strings.Contains(host, ".") {
+		host += "." + keyVaultDomains[cloud]
+	}
+	secretURL := fmt.Sprintf("https://%s/secrets/%s", host, url.PathEscape(ref.Name))
+	if ref.Version != "" {
+		secretURL += "/" + url.PathEscape(ref.Version)
+	}
+
+	key := fmt.Sprintf("%s|%s|%s", cloud, keyVaultIdentity(r.conf), secretURL)
+
+	keyVaultSecrets.Lock()
+	cached, ok := keyVaultSecrets.m[key]
+	keyVaultSecrets.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.value, nil
+	}
+
+	if r.cred == nil {
+		cred, err := keyVaultCredential(r.logger, r.conf)
+		if err != nil {
+			return "", err
+		}
+		r.cred = cred
+	}
+	scope := fmt.Sprintf("https://%s/.default", keyVaultDomains[cloud])
+	value, err := fetchKeyVaultSecret(r.ctx, http.DefaultClient, r.cred, scope, secretURL)
+	if err != nil {
+		return "", err
+	}
+	level.Debug(r.logger).Log("msg", "read secret from Key Vault", "url", secretURL)
+
+	keyVaultSecrets.Lock()
+	keyVaultSecrets.m[key] = cachedSecret{value: value, expires: time.Now().Add(keyVaultTTL)}
+	keyVaultSecrets.Unlock()
+	return value, nil
+}
+
+// keyVaultCredential returns the credential reading the Key Vault secrets of the receiver: its own credential when it
+// needs no secret, i.e. an auth profile of type workload_identity, managed_identity, azure_cli or default_chain, or a
+// workload or managed identity set by client_id without client secret and personal access token. Otherwise, the
+// secrets are read with the default credential chain of the Azure SDK in the receiver's Azure environment: the
+// workload's identity.
+func keyVaultCredential(logger log.Logger, conf *config.ReceiverConfig) (azcore.TokenCredential, error) {
+	if keyVaultIdentity(conf) != "" {
+		level.Debug(logger).Log("msg", "reading Key Vault secrets with the receiver's credential")
+		return GetAuthenticationCredential(logger, conf)
+	}
+	level.Debug(logger).Log("msg", "reading Key Vault secrets with the default credential chain")
+	return azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{ClientOptions: clientOptions(conf.Azure)})
+}
+
+// keyVaultIdentity returns the identity of the receiver's own credential reading its Key Vault secrets, see
+// keyVaultCredential: its auth type, tenant and client ID. It returns "" when the secrets are read with the default
+// credential chain.
+func keyVaultIdentity(conf *config.ReceiverConfig) string {
+	if conf.Auth != nil {
+		switch conf.Auth.Type {
+		case config.AuthWorkloadIdentity, config.AuthManagedIdentity, config.AuthAzureCLI, config.AuthDefaultChain:
+			return fmt.Sprintf("%s/%s/%s", conf.Auth.Type, conf.Auth.TenantID, conf.Auth.ClientID)
+		}
+		return ""
+	}
+	if conf.ClientID != "" && conf.ClientSecret == "" && conf.ClientSecretFile == "" &&
+		conf.PersonalAccessToken == "" && conf.PersonalAccessTokenFile == "" {
+		return fmt.Sprintf("client_id/%s/%s", conf.TenantID, conf.ClientID)
+	}
+	return ""
+}
+
+// fetchKeyVaultSecret reads a secret with the Key Vault REST API, authenticating with a token of cred for scope.
+func fetchKeyVaultSecret(ctx context.Context, client *http.Client, cred azcore.TokenCredential, scope, secretURL string) (config.Secret, error) {
+	token, err := cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{scope}})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, secretURL+"?api-version="+keyVaultAPIVersion, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.Token)
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		Value string `json:"value"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil && resp.StatusCode == http.StatusOK {
+		return "", fmt.Errorf("invalid response from %s: %w", secretURL, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if result.Error != nil {
+			return "", fmt.Errorf("%s: %s: %s", secretURL, resp.Status, result.Error.Message)
+		}
+		return "", fmt.Errorf("%s: %s", secretURL, resp.Status)
+	}
+	return config.Secret(result.Value), nil
+}
@@ -0,0 +1,160 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package azure
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/go-kit/log"
+	"github.com/stakater/alert-az-do/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveSecrets_Files(t *testing.T) {
+	dir := t.TempDir()
+	patFile := filepath.Join(dir, "pat")
+	secretFile := filepath.Join(dir, "secret")
+	require.NoError(t, os.WriteFile(patFile, []byte("pat-1\n"), 0o600))
+	require.NoError(t, os.WriteFile(secretFile, []byte("secret"), 0o600))
+
+	conf := &config.ReceiverConfig{
+		PersonalAccessTokenFile: patFile,
+		Auth:                    &config.AuthConfig{Type: config.AuthServicePrincipalSecret, TenantID: "tenant-123", ClientID: "client-123", ClientSecretFile: secretFile},
+	}
+	resolved, err := ResolveSecrets(context.Background(), log.NewNopLogger(), conf)
+	require.NoError(t, err)
+	require.Equal(t, config.Secret("pat-1"), resolved.PersonalAccessToken)
+	require.Empty(t, resolved.PersonalAccessTokenFile)
+	require.Equal(t, config.Secret("secret"), resolved.Auth.ClientSecret)
+	// The configuration is not modified.
+	require.Equal(t, patFile, conf.PersonalAccessTokenFile)
+	require.Empty(t, conf.Auth.ClientSecret)
+
+	// Rotated files are read again.
+	require.NoError(t, os.WriteFile(patFile, []byte("pat-2\n"), 0o600))
+	resolved, err = ResolveSecrets(context.Background(), log.NewNopLogger(), conf)
+	require.NoError(t, err)
+	require.Equal(t, config.Secret("pat-2"), resolved.PersonalAccessToken)
+
+	_, err = ResolveSecrets(context.Background(), log.NewNopLogger(), &config.ReceiverConfig{PersonalAccessTokenFile: patFile + ".missing"})
+	require.ErrorContains(t, err, "failed to read personal_access_token")
+}
+
+func TestResolveSecrets_KeyVaultCache(t *testing.T) {
+	key := "AzureUSGovernment||https://contoso.vault.usgovcloudapi.net/secrets/devops-pat/v1"
+	defer func() {
+		keyVaultSecrets.Lock()
+		delete(keyVaultSecrets.m, key)
+		keyVaultSecrets.Unlock()
+	}()
+	keyVaultSecrets.Lock()
+	keyVaultSecrets.m[key] = cachedSecret{value: "cached-pat", expires: time.Now().Add(time.Minute)}
+	keyVaultSecrets.Unlock()
+
+	conf := &config.ReceiverConfig{
+		PersonalAccessToken: "azurekeyvault://contoso/devops-pat/v1",
+		Azure:               &config.AzureConfig{Cloud: config.CloudAzureUSGovernment},
+	}
+	resolved, err := ResolveSecrets(context.Background(), log.NewNopLogger(), conf)
+	require.NoError(t, err)
+	require.Equal(t, config.Secret("cached-pat"), resolved.PersonalAccessToken)
+
+	// A receiver reading the secret with its own identity does not get the secret cached for another identity.
+	conf = &config.ReceiverConfig{
+		Auth: &config.AuthConfig{
+			Type:                config.AuthManagedIdentity,
+			ClientID:            "client-123",
+			PersonalAccessToken: "azurekeyvault://contoso/devops-pat/v1",
+		},
+		Azure: &config.AzureConfig{Cloud: config.CloudAzureUSGovernment},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = ResolveSecrets(ctx, log.NewNopLogger(), conf)
+	require.Error(t, err)
+}
+
+func TestKeyVaultIdentity(t *testing.T) {
+	require.Empty(t, keyVaultIdentity(&config.ReceiverConfig{PersonalAccessToken: "azurekeyvault://contoso/devops-pat"}))
+	require.Empty(t, keyVaultIdentity(&config.ReceiverConfig{Auth: &config.AuthConfig{Type: config.AuthServicePrincipalSecret, ClientID: "client-123"}}))
+	require.Equal(t, "client_id/tenant-123/client-123", keyVaultIdentity(&config.ReceiverConfig{TenantID: "tenant-123", ClientID: "client-123"}))
+	require.NotEqual(t,
+		keyVaultIdentity(&config.ReceiverConfig{Auth: &config.AuthConfig{Type: config.AuthManagedIdentity, ClientID: "client-123"}}),
+		keyVaultIdentity(&config.ReceiverConfig{Auth: &config.AuthConfig{Type: config.AuthManagedIdentity, ClientID: "client-456"}}))
+}
+
+func TestKeyVaultCredential(t *testing.T) {
+	for name, tc := range map[string]struct {
+		conf *config.ReceiverConfig
+		cred interface{}
+	}{
+		"workload identity profile": {
+			conf: &config.ReceiverConfig{
+				Auth:  &config.AuthConfig{Type: config.AuthWorkloadIdentity, TenantID: "tenant-123", ClientID: "client-123"},
+				Azure: &config.AzureConfig{FederatedTokenFile: "/var/run/secrets/azure/tokens/azure-identity-token"},
+			},
+			cred: &azidentity.WorkloadIdentityCredential{},
+		},
+		"managed identity": {
+			conf: &config.ReceiverConfig{ClientID: "client-123", SubscriptionID: "sub-123"},
+			cred: &azidentity.ManagedIdentityCredential{},
+		},
+		"personal access token": {
+			conf: &config.ReceiverConfig{PersonalAccessToken: "azurekeyvault://contoso/devops-pat"},
+			cred: &azidentity.DefaultAzureCredential{},
+		},
+		"service principal secret profile": {
+			conf: &config.ReceiverConfig{Auth: &config.AuthConfig{Type: config.AuthServicePrincipalSecret, TenantID: "tenant-123", ClientID: "client-123", ClientSecret: "azurekeyvault://contoso/sp"}},
+			cred: &azidentity.DefaultAzureCredential{},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cred, err := keyVaultCredential(log.NewNopLogger(), tc.conf)
+			require.NoError(t, err)
+			require.IsType(t, tc.cred, cred)
+		})
+	}
+}
+
+func TestFetchKeyVaultSecret(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "7.4", r.URL.Query().Get("api-version"))
+		require.Equal(t, "Bearer OnRva2Vu", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/secrets/devops-pat":
+			w.Write([]byte(`{"value": "pat-from-vault", "id": "https://contoso.vault.azure.net/secrets/devops-pat/v1"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"code": "SecretNotFound", "message": "A secret with (name/id) other was not found in this key vault."}}`))
+		}
+	}))
+	defer server.Close()
+	cred, err := NewBasicCredential("", "token")
+	require.NoError(t, err)
+
+	value, err := fetchKeyVaultSecret(context.Background(), server.Client(), cred, "https://vault.azure.net/.default", server.URL+"/secrets/devops-pat")
+	require.NoError(t, err)
+	require.Equal(t, config.Secret("pat-from-vault"), value)
+
+	_, err = fetchKeyVaultSecret(context.Background(), server.Client(), cred, "https://vault.azure.net/.default", server.URL+"/secrets/other")
+	require.ErrorContains(t, err, "404 Not Found: A secret with (name/id) other was not found in this key vault.")
+}
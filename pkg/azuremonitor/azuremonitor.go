@@ -0,0 +1,212 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package azuremonitor converts Azure Monitor alerts, sent by Action Groups in the Common Alert Schema, into
+// Alertmanager webhook notifications.
+//
+// See https://learn.microsoft.com/en-us/azure/azure-monitor/alerts/alerts-common-schema
+package azuremonitor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stakater/alert-az-do/pkg/alertmanager"
+)
+
+const (
+	// SchemaID identifies payloads in the Common Alert Schema.
+	SchemaID = "azureMonitorCommonAlertSchema"
+
+	// MonitorConditionFired is the monitor condition of a firing alert.
+	MonitorConditionFired = "Fired"
+
+	// MonitorConditionResolved is the monitor condition of a resolved alert.
+	MonitorConditionResolved = "Resolved"
+
+	// webhookVersion is the webhook protocol version of the converted notifications.
+	webhookVersion = "4"
+
+	// portalURL is the external URL of the converted notifications.
+	portalURL = "https://portal.azure.com"
+)
+
+// severities maps the Azure Monitor severities to the severity label values.
+var severities = map[string]string{
+	"Sev0": "critical",
+	"Sev1": "error",
+	"Sev2": "warning",
+	"Sev3": "informational",
+	"Sev4": "verbose",
+}
+
+// Payload is an Azure Monitor alert in the Common Alert Schema.
+type Payload struct {
+	SchemaID string `json:"schemaId"`
+	Data     struct {
+		Essentials       Essentials             `json:"essentials"`
+		AlertContext     map[string]interface{} `json:"alertContext"`
+		CustomProperties map[string]string      `json:"customProperties"`
+	} `json:"data"`
+}
+
+// Essentials are the fields common to all alert types.
+type Essentials struct {
+	AlertID             string     `json:"alertId"`
+	AlertRule           string     `json:"alertRule"`
+	Severity            string     `json:"severity"`
+	SignalType          string     `json:"signalType"`
+	MonitorCondition    string     `json:"monitorCondition"`
+	MonitoringService   string     `json:"monitoringService"`
+	AlertTargetIDs      []string   `json:"alertTargetIDs"`
+	ConfigurationItems  []string   `json:"configurationItems"`
+	OriginAlertID       string     `json:"originAlertId"`
+	FiredDateTime       time.Time  `json:"firedDateTime"`
+	ResolvedDateTime    *time.Time `json:"resolvedDateTime,omitempty"`
+	Description         string     `json:"description"`
+	InvestigationLink   string     `json:"investigationLink"`
+	EssentialsVersion   string     `json:"essentialsVersion"`
+	AlertContextVersion string     `json:"alertContextVersion"`
+}
+
+// Fingerprint identifies the alert across its fired and resolved notifications. It is derived from the alert ID,
+// which Azure Monitor keeps for the lifetime of a stateful alert.
+func Fingerprint(alertID string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(alertID)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// ToData converts the payload into a notification for the given receiver.
+//
+// The alert rule becomes the alertname label and the group label, the severity is mapped to the severity label
+// (Sev0 to Sev4 become critical, error, warning, informational and verbose, the original value is kept in the
+// azure_severity label), and the alert context and custom properties become annotations, nested fields being
+// flattened into dotted names.
+func (p *Payload) ToData(receiver string) (*alertmanager.Data, error) {
+	if p.SchemaID != SchemaID {
+		return nil, errors.Errorf("unsupported schema %q, expected %q", p.SchemaID, SchemaID)
+	}
+	essentials := p.Data.Essentials
+	if essentials.AlertID == "" {
+		return nil, errors.New("missing essentials.alertId")
+	}
+
+	status, err := alertStatus(essentials.MonitorCondition)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := alertmanager.KV{}
+	setLabel(labels, alertmanager.AlertNameLabel, essentials.AlertRule)
+	setLabel(labels, "severity", severities[essentials.Severity])
+	setLabel(labels, "azure_severity", essentials.Severity)
+	setLabel(labels, "signal_type", essentials.SignalType)
+	setLabel(labels, "monitoring_service", essentials.MonitoringService)
+	setLabel(labels, "target_resource", strings.Join(essentials.ConfigurationItems, ","))
+
+	annotations := alertmanager.KV{}
+	flatten(annotations, "", p.Data.AlertContext)
+	for k, v := range p.Data.CustomProperties {
+		annotations[k] = v
+	}
+	setLabel(annotations, "description", essentials.Description)
+	setLabel(annotations, "alert_id", essentials.AlertID)
+	setLabel(annotations, "target_resource_ids", strings.Join(essentials.AlertTargetIDs, ","))
+
+	alert := alertmanager.Alert{
+		Status:       status,
+		Labels:       labels,
+		Annotations:  annotations,
+		StartsAt:     essentials.FiredDateTime,
+		GeneratorURL: essentials.InvestigationLink,
+		Fingerprint:  Fingerprint(essentials.AlertID),
+	}
+	if status == alertmanager.AlertResolved && essentials.ResolvedDateTime != nil {
+		alert.EndsAt = *essentials.ResolvedDateTime
+	}
+
+	groupLabels := alertmanager.KV{}
+	setLabel(groupLabels, alertmanager.AlertNameLabel, essentials.AlertRule)
+	return &alertmanager.Data{
+		Version:           webhookVersion,
+		GroupKey:          fmt.Sprintf("{}/%s:{alertId=%q}", receiver, essentials.AlertID),
+		Receiver:          receiver,
+		Status:            status,
+		Alerts:            alertmanager.Alerts{alert},
+		GroupLabels:       groupLabels,
+		CommonLabels:      labels,
+		CommonAnnotations: annotations,
+		ExternalURL:       portalURL,
+	}, nil
+}
+
+func alertStatus(monitorCondition string) (string, error) {
+	switch {
+	case strings.EqualFold(monitorCondition, MonitorConditionFired):
+		return alertmanager.AlertFiring, nil
+	case strings.EqualFold(monitorCondition, MonitorConditionResolved):
+		return alertmanager.AlertResolved, nil
+	default:
+		return "", errors.Errorf("unsupported monitor condition %q", monitorCondition)
+	}
+}
+
+func setLabel(kv alertmanager.KV, name, value string) {
+	if value != "" {
+		kv[name] = value
+	}
+}
+
+// flatten adds the fields of the value to the set, nested objects and arrays under dotted names, e.g.
+// "condition.allOf.0.metricName". Other values are formatted as in JSON, strings unquoted.
+func flatten(kv alertmanager.KV, prefix string, value interface{}) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			flatten(kv, join(k), v[k])
+		}
+	case []interface{}:
+		for i, item := range v {
+			flatten(kv, join(fmt.Sprint(i)), item)
+		}
+	case nil:
+	case string:
+		setLabel(kv, prefix, v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			kv[prefix] = fmt.Sprint(v)
+			return
+		}
+		kv[prefix] = string(b)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package azuremonitor
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stakater/alert-az-do/pkg/alertmanager"
+	"github.com/stretchr/testify/require"
+)
+
+const metricAlert = `{
+  "schemaId": "azureMonitorCommonAlertSchema",
+  "data": {
+    "essentials": {
+      "alertId": "/subscriptions/11111111-1111-1111-1111-111111111111/providers/Microsoft.AlertsManagement/alerts/12345678-1234-1234-1234-1234567890ab",
+      "alertRule": "HighCpu",
+      "severity": "Sev1",
+      "signalType": "Metric",
+      "monitorCondition": "%s",
+      "monitoringService": "Platform",
+      "alertTargetIDs": ["/subscriptions/11111111-1111-1111-1111-111111111111/resourcegroups/rg/providers/microsoft.compute/virtualmachines/vm1"],
+      "configurationItems": ["vm1"],
+      "originAlertId": "3f2d4487-b0fc-4125-8bd5-7ad17384221e_PROD_123",
+      "firedDateTime": "2025-03-22T13:58:24.3713213Z",
+      "resolvedDateTime": "2025-03-22T14:03:16.2246313Z",
+      "description": "CPU above 90%",
+      "investigationLink": "https://portal.azure.com/#view/Microsoft_Azure_Monitoring_Alerts/Investigate/alertId/123",
+      "essentialsVersion": "1.0",
+      "alertContextVersion": "1.0"
+    },
+    "alertContext": {
+      "properties": null,
+      "conditionType": "SingleResourceMultipleMetricCriteria",
+      "condition": {
+        "windowSize": "PT5M",
+        "allOf": [{
+          "metricName": "Percentage CPU",
+          "operator": "GreaterThan",
+          "threshold": "90",
+          "metricValue": 97.5
+        }]
+      }
+    },
+    "customProperties": {
+      "team": "platform"
+    }
+  }
+}`
+
+func payload(t *testing.T, monitorCondition string) *Payload {
+	p := &Payload{}
+	require.NoError(t, json.Unmarshal([]byte(strings.Replace(metricAlert, "%s", monitorCondition, 1)), p))
+	return p
+}
+
+func TestPayload_ToData_Fired(t *testing.T) {
+	data, err := payload(t, "Fired").ToData("azure")
+	require.NoError(t, err)
+
+	require.Equal(t, "azure", data.Receiver)
+	require.Equal(t, alertmanager.AlertFiring, data.Status)
+	require.Equal(t, alertmanager.KV{"alertname": "HighCpu"}, data.GroupLabels)
+	require.Len(t, data.Alerts, 1)
+
+	alert := data.Alerts[0]
+	require.Equal(t, alertmanager.AlertFiring, alert.Status)
+	require.Equal(t, alertmanager.KV{
+		"alertname":          "HighCpu",
+		"severity":           "error",
+		"azure_severity":     "Sev1",
+		"signal_type":        "Metric",
+		"monitoring_service": "Platform",
+		"target_resource":    "vm1",
+	}, alert.Labels)
+	require.Equal(t, "PT5M", alert.Annotations["condition.windowSize"])
+	require.Equal(t, "Percentage CPU", alert.Annotations["condition.allOf.0.metricName"])
+	require.Equal(t, "97.5", alert.Annotations["condition.allOf.0.metricValue"])
+	require.NotContains(t, alert.Annotations, "properties")
+	require.Equal(t, "platform", alert.Annotations["team"])
+	require.Equal(t, "CPU above 90%", alert.Annotations["description"])
+	require.Contains(t, alert.Annotations["alert_id"], "/alerts/12345678")
+	require.Equal(t, time.Date(2025, 3, 22, 13, 58, 24, 371321300, time.UTC), alert.StartsAt)
+	require.True(t, alert.EndsAt.IsZero())
+	require.Equal(t, "https://portal.azure.com/#view/Microsoft_Azure_Monitoring_Alerts/Investigate/alertId/123", alert.GeneratorURL)
+	require.Equal(t, alert.Labels, data.CommonLabels)
+	require.Equal(t, alert.Annotations, data.CommonAnnotations)
+}
+
+func TestPayload_ToData_Resolved(t *testing.T) {
+	fired, err := payload(t, "Fired").ToData("azure")
+	require.NoError(t, err)
+	resolved, err := payload(t, "Resolved").ToData("azure")
+	require.NoError(t, err)
+
+	require.Equal(t, alertmanager.AlertResolved, resolved.Status)
+	require.Equal(t, alertmanager.AlertResolved, resolved.Alerts[0].Status)
+	require.Equal(t, time.Date(2025, 3, 22, 14, 3, 16, 224631300, time.UTC), resolved.Alerts[0].EndsAt)
+	// The fired and resolved notifications must update the same work item.
+	require.Equal(t, fired.Alerts[0].Fingerprint, resolved.Alerts[0].Fingerprint)
+	require.Equal(t, fired.GroupKey, resolved.GroupKey)
+}
+
+func TestPayload_ToData_Errors(t *testing.T) {
+	p := payload(t, "Fired")
+	p.SchemaID = "Microsoft.Insights/activityLogs"
+	_, err := p.ToData("azure")
+	require.ErrorContains(t, err, "unsupported schema")
+
+	p = payload(t, "Unknown")
+	_, err = p.ToData("azure")
+	require.ErrorContains(t, err, "unsupported monitor condition")
+
+	p = payload(t, "Fired")
+	p.Data.Essentials.AlertID = ""
+	_, err = p.ToData("azure")
+	require.ErrorContains(t, err, "missing essentials.alertId")
+}
+
+func TestPayload_ToData_Severities(t *testing.T) {
+	for severity, label := range map[string]string{"Sev0": "critical", "Sev2": "warning", "Sev3": "informational", "Sev4": "verbose"} {
+		p := payload(t, "Fired")
+		p.Data.Essentials.Severity = severity
+		data, err := p.ToData("azure")
+		require.NoError(t, err)
+		require.Equal(t, label, data.Alerts[0].Labels["severity"], severity)
+		require.Equal(t, severity, data.Alerts[0].Labels["azure_severity"])
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	fingerprint := Fingerprint("/subscriptions/1/providers/Microsoft.AlertsManagement/alerts/abc")
+	require.Len(t, fingerprint, 16)
+	require.Equal(t, fingerprint, Fingerprint("/subscriptions/1/providers/microsoft.alertsmanagement/alerts/ABC"))
+	require.NotEqual(t, fingerprint, Fingerprint("/subscriptions/1/providers/Microsoft.AlertsManagement/alerts/abd"))
+}
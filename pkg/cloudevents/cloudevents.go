@@ -0,0 +1,359 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cloudevents reads CloudEvents from HTTP requests, in binary and structured content mode, and converts them
+// into Alertmanager webhook notifications.
+//
+// See https://github.com/cloudevents/spec/blob/v1.0.2/cloudevents/bindings/http-protocol-binding.md
+package cloudevents
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stakater/alert-az-do/pkg/alertmanager"
+	"github.com/stakater/alert-az-do/pkg/config"
+)
+
+const (
+	// SpecVersion is the supported CloudEvents specification version.
+	SpecVersion = "1.0"
+
+	// ContentTypeStructured is the media type of events in structured content mode.
+	ContentTypeStructured = "application/cloudevents+json"
+
+	// ContentTypeBatch is the media type of event batches, which are not supported.
+	ContentTypeBatch = "application/cloudevents-batch+json"
+
+	// headerPrefix prefixes the event attributes in binary content mode.
+	headerPrefix = "Ce-"
+
+	// resolvedTypeSuffix is the suffix of the types of resolved events when resolved_types is not configured.
+	resolvedTypeSuffix = ".resolved"
+
+	// webhookVersion is the webhook protocol version of the converted notifications.
+	webhookVersion = "4"
+)
+
+var (
+	// ErrUnsupportedMediaType is the cause of errors reading events in an unsupported content mode or with non-JSON
+	// data, to be answered with "415 Unsupported Media Type".
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+	// defaultAnnotationFields are the data fields that become annotations when annotation_fields is not configured.
+	defaultAnnotationFields = []string{"summary", "description", "message", "runbook_url"}
+)
+
+// Event is a CloudEvent.
+type Event struct {
+	SpecVersion     string
+	Type            string
+	Source          string
+	ID              string
+	Subject         string
+	Time            time.Time
+	DataContentType string
+	Data            json.RawMessage
+	Extensions      map[string]string
+}
+
+// structured is an event in structured content mode.
+type structured struct {
+	SpecVersion     string          `json:"specversion"`
+	Type            string          `json:"type"`
+	Source          string          `json:"source"`
+	ID              string          `json:"id"`
+	Subject         string          `json:"subject"`
+	Time            string          `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	DataSchema      string          `json:"dataschema"`
+	Data            json.RawMessage `json:"data"`
+	DataBase64      string          `json:"data_base64"`
+}
+
+// ReadRequest reads the event from the request, in binary content mode when it has a ce-specversion header and in
+// structured content mode otherwise.
+func ReadRequest(req *http.Request) (*Event, error) {
+	mediaType := ""
+	if contentType := req.Header.Get("Content-Type"); contentType != "" {
+		var err error
+		if mediaType, _, err = mime.ParseMediaType(contentType); err != nil {
+			return nil, errors.Wrapf(ErrUnsupportedMediaType, "invalid content type %q", contentType)
+		}
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "read body")
+	}
+
+	var event *Event
+	switch {
+	case req.Header.Get(headerPrefix+"Specversion") != "":
+		event, err = readBinary(req.Header, mediaType, body)
+	case mediaType == ContentTypeStructured:
+		event, err = readStructured(body)
+	case mediaType == ContentTypeBatch:
+		return nil, errors.Wrap(ErrUnsupportedMediaType, "batched content mode is not supported")
+	default:
+		return nil, errors.Wrapf(ErrUnsupportedMediaType, "not a CloudEvent: no ce-specversion header and content type %q", mediaType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := event.validate(); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func readBinary(header http.Header, mediaType string, body []byte) (*Event, error) {
+	event := &Event{DataContentType: mediaType, Extensions: map[string]string{}}
+	for key, values := range header {
+		if !strings.HasPrefix(key, headerPrefix) || len(values) == 0 {
+			continue
+		}
+		// Header values are percent-encoded.
+		value, err := url.PathUnescape(values[0])
+		if err != nil {
+			return nil, errors.Wrapf(err, "header %s", key)
+		}
+		switch name := strings.ToLower(strings.TrimPrefix(key, headerPrefix)); name {
+		case "specversion":
+			event.SpecVersion = value
+		case "type":
+			event.Type = value
+		case "source":
+			event.Source = value
+		case "id":
+			event.ID = value
+		case "subject":
+			event.Subject = value
+		case "time":
+			if event.Time, err = time.Parse(time.RFC3339, value); err != nil {
+				return nil, errors.Wrap(err, "invalid time attribute")
+			}
+		case "dataschema":
+		default:
+			event.Extensions[name] = value
+		}
+	}
+	if len(body) > 0 {
+		event.Data = body
+	}
+	return event, nil
+}
+
+func readStructured(body []byte) (*Event, error) {
+	var s structured
+	if err := json.Unmarshal(body, &s); err != nil {
+		return nil, errors.Wrap(err, "decode structured event")
+	}
+	var attributes map[string]json.RawMessage
+	if err := json.Unmarshal(body, &attributes); err != nil {
+		return nil, errors.Wrap(err, "decode structured event")
+	}
+
+	event := &Event{
+		SpecVersion:     s.SpecVersion,
+		Type:            s.Type,
+		Source:          s.Source,
+		ID:              s.ID,
+		Subject:         s.Subject,
+		DataContentType: s.DataContentType,
+		Extensions:      map[string]string{},
+	}
+	if s.Time != "" {
+		var err error
+		if event.Time, err = time.Parse(time.RFC3339, s.Time); err != nil {
+			return nil, errors.Wrap(err, "invalid time attribute")
+		}
+	}
+	switch {
+	case len(s.Data) > 0 && string(s.Data) != "null":
+		event.Data = s.Data
+		if event.DataContentType == "" {
+			event.DataContentType = "application/json"
+		}
+	case s.DataBase64 != "":
+		data, err := base64.StdEncoding.DecodeString(s.DataBase64)
+		if err != nil {
+			return nil, errors.Wrap(err, "decode data_base64")
+		}
+		event.Data = data
+	}
+
+	for name, raw := range attributes {
+		switch name {
+		case "specversion", "type", "source", "id", "subject", "time", "datacontenttype", "dataschema", "data", "data_base64":
+			continue
+		}
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			// Extensions may be booleans or integers too.
+			value = string(raw)
+		}
+		event.Extensions[name] = value
+	}
+	return event, nil
+}
+
+func (e *Event) validate() error {
+	if e.SpecVersion != SpecVersion {
+		return errors.Errorf("unsupported specversion %q, expected %q", e.SpecVersion, SpecVersion)
+	}
+	switch {
+	case e.ID == "":
+		return errors.New("missing required attribute \"id\"")
+	case e.Source == "":
+		return errors.New("missing required attribute \"source\"")
+	case e.Type == "":
+		return errors.New("missing required attribute \"type\"")
+	}
+	if len(e.Data) > 0 && e.DataContentType != "" && !isJSON(e.DataContentType) {
+		return errors.Wrapf(ErrUnsupportedMediaType, "data content type %q is not JSON", e.DataContentType)
+	}
+	return nil
+}
+
+func isJSON(mediaType string) bool {
+	if mediaType, _, err := mime.ParseMediaType(mediaType); err == nil {
+		return mediaType == "application/json" || mediaType == "text/json" || strings.HasSuffix(mediaType, "+json")
+	}
+	return false
+}
+
+// Receiver returns the name of the receiver the event is routed to: the value of the configured extension attribute,
+// or the default receiver.
+func (e *Event) Receiver(conf *config.CloudEventsConfig) string {
+	if receiver := e.Extensions[conf.ReceiverExtension]; receiver != "" {
+		return receiver
+	}
+	return conf.DefaultReceiver
+}
+
+// ToData converts the event into a notification for the given receiver.
+//
+// The type tells whether the alert is firing or resolved, the source and subject identify the alert, and the fields
+// of the data (a JSON object) become labels, except for the annotation fields, which become annotations. The
+// alertname label defaults to the type.
+func (e *Event) ToData(conf *config.CloudEventsConfig, receiver string) (*alertmanager.Data, error) {
+	if e.Subject == "" {
+		return nil, errors.New("missing subject attribute identifying the alert")
+	}
+	status, err := e.status(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{}
+	if len(e.Data) > 0 {
+		if err := json.Unmarshal(e.Data, &fields); err != nil {
+			return nil, errors.Wrap(err, "data must be a JSON object")
+		}
+	}
+	annotationFields := conf.AnnotationFields
+	if len(annotationFields) == 0 {
+		annotationFields = defaultAnnotationFields
+	}
+	isAnnotation := map[string]bool{}
+	for _, name := range annotationFields {
+		isAnnotation[name] = true
+	}
+
+	labels, annotations := alertmanager.KV{}, alertmanager.KV{}
+	for name, value := range fields {
+		s := ""
+		switch v := value.(type) {
+		case nil:
+			continue
+		case string:
+			s = v
+		default:
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, errors.Wrapf(err, "data field %s", name)
+			}
+			s = string(b)
+		}
+		if isAnnotation[name] {
+			annotations[name] = s
+		} else {
+			labels[name] = s
+		}
+	}
+	if labels[alertmanager.AlertNameLabel] == "" {
+		labels[alertmanager.AlertNameLabel] = e.Type
+	}
+
+	sum := sha256.Sum256([]byte(e.Source + "\n" + e.Subject))
+	alert := alertmanager.Alert{
+		Status:      status,
+		Labels:      labels,
+		Annotations: annotations,
+		StartsAt:    e.Time,
+		Fingerprint: hex.EncodeToString(sum[:8]),
+	}
+	if status == alertmanager.AlertResolved {
+		alert.EndsAt = e.Time
+	}
+	if u, err := url.Parse(e.Source); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		alert.GeneratorURL = e.Source
+	}
+
+	groupLabels := alertmanager.KV{alertmanager.AlertNameLabel: labels[alertmanager.AlertNameLabel]}
+	return &alertmanager.Data{
+		Version:           webhookVersion,
+		GroupKey:          fmt.Sprintf("{}/%s:{source=%q, subject=%q}", receiver, e.Source, e.Subject),
+		Receiver:          receiver,
+		Status:            status,
+		Alerts:            alertmanager.Alerts{alert},
+		GroupLabels:       groupLabels,
+		CommonLabels:      labels,
+		CommonAnnotations: annotations,
+	}, nil
+}
+
+// status maps the event type to the alert status. Without configured types, types ending in ".resolved" are
+// resolved and all others firing; with firing types configured, types in neither list are rejected.
+func (e *Event) status(conf *config.CloudEventsConfig) (string, error) {
+	for _, t := range conf.ResolvedTypes {
+		if t == e.Type {
+			return alertmanager.AlertResolved, nil
+		}
+	}
+	for _, t := range conf.FiringTypes {
+		if t == e.Type {
+			return alertmanager.AlertFiring, nil
+		}
+	}
+	switch {
+	case len(conf.FiringTypes) > 0:
+		return "", errors.Errorf("unsupported event type %q", e.Type)
+	case len(conf.ResolvedTypes) == 0 && strings.HasSuffix(e.Type, resolvedTypeSuffix):
+		return alertmanager.AlertResolved, nil
+	default:
+		return alertmanager.AlertFiring, nil
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudevents
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stakater/alert-az-do/pkg/alertmanager"
+	"github.com/stakater/alert-az-do/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func eventsConfig() *config.CloudEventsConfig {
+	return &config.CloudEventsConfig{ReceiverExtension: "receiver"}
+}
+
+func TestReadRequest_Binary(t *testing.T) {
+	req := httptest.NewRequest("POST", "/cloudevents", strings.NewReader(`{"alertname": "DiskFull", "host": "db1", "summary": "Disk 95% full"}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("ce-specversion", "1.0")
+	req.Header.Set("ce-type", "com.example.disk.firing")
+	req.Header.Set("ce-source", "https://monitor.example.com/checks")
+	req.Header.Set("ce-id", "1234")
+	req.Header.Set("ce-subject", "db1%2Fdisk")
+	req.Header.Set("ce-time", "2025-04-01T10:00:00Z")
+	req.Header.Set("ce-receiver", "team-a")
+
+	event, err := ReadRequest(req)
+	require.NoError(t, err)
+	require.Equal(t, "1.0", event.SpecVersion)
+	require.Equal(t, "com.example.disk.firing", event.Type)
+	require.Equal(t, "https://monitor.example.com/checks", event.Source)
+	require.Equal(t, "1234", event.ID)
+	require.Equal(t, "db1/disk", event.Subject)
+	require.Equal(t, time.Date(2025, 4, 1, 10, 0, 0, 0, time.UTC), event.Time)
+	require.Equal(t, "application/json", event.DataContentType)
+	require.Equal(t, map[string]string{"receiver": "team-a"}, event.Extensions)
+	require.Equal(t, "team-a", event.Receiver(eventsConfig()))
+
+	data, err := event.ToData(eventsConfig(), "team-a")
+	require.NoError(t, err)
+	require.Equal(t, "team-a", data.Receiver)
+	require.Equal(t, alertmanager.AlertFiring, data.Status)
+	require.Len(t, data.Alerts, 1)
+	alert := data.Alerts[0]
+	require.Equal(t, alertmanager.KV{"alertname": "DiskFull", "host": "db1"}, alert.Labels)
+	require.Equal(t, alertmanager.KV{"summary": "Disk 95% full"}, alert.Annotations)
+	require.Equal(t, "https://monitor.example.com/checks", alert.GeneratorURL)
+	require.Equal(t, event.Time, alert.StartsAt)
+	require.Len(t, alert.Fingerprint, 16)
+	require.Equal(t, alertmanager.KV{"alertname": "DiskFull"}, data.GroupLabels)
+}
+
+func TestReadRequest_Structured(t *testing.T) {
+	req := httptest.NewRequest("POST", "/cloudevents", strings.NewReader(`{
+		"specversion": "1.0",
+		"type": "com.example.disk.resolved",
+		"source": "monitor",
+		"id": "5678",
+		"subject": "db1/disk",
+		"time": "2025-04-01T11:00:00Z",
+		"receiver": "team-a",
+		"priority": 2,
+		"data": {"host": "db1", "usage": 0.5}
+	}`))
+	req.Header.Set("Content-Type", "application/cloudevents+json; charset=utf-8")
+
+	event, err := ReadRequest(req)
+	require.NoError(t, err)
+	require.Equal(t, "com.example.disk.resolved", event.Type)
+	require.Equal(t, "application/json", event.DataContentType)
+	require.Equal(t, map[string]string{"receiver": "team-a", "priority": "2"}, event.Extensions)
+
+	data, err := event.ToData(eventsConfig(), "team-a")
+	require.NoError(t, err)
+	require.Equal(t, alertmanager.AlertResolved, data.Status)
+	alert := data.Alerts[0]
+	require.Equal(t, alertmanager.KV{"alertname": "com.example.disk.resolved", "host": "db1", "usage": "0.5"}, alert.Labels)
+	require.Equal(t, time.Date(2025, 4, 1, 11, 0, 0, 0, time.UTC), alert.EndsAt)
+	// Not an HTTP URL.
+	require.Empty(t, alert.GeneratorURL)
+}
+
+func TestReadRequest_StructuredBase64(t *testing.T) {
+	req := httptest.NewRequest("POST", "/cloudevents", strings.NewReader(`{
+		"specversion": "1.0", "type": "t", "source": "s", "id": "1", "subject": "x",
+		"datacontenttype": "application/json", "data_base64": "eyJob3N0IjogImRiMSJ9"
+	}`))
+	req.Header.Set("Content-Type", ContentTypeStructured)
+
+	event, err := ReadRequest(req)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"host": "db1"}`, string(event.Data))
+}
+
+func TestReadRequest_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		headers     map[string]string
+		body        string
+		err         string
+		unsupported bool
+	}{
+		{name: "no event", contentType: "application/json", body: `{}`, err: "not a CloudEvent", unsupported: true},
+		{name: "batch", contentType: ContentTypeBatch, body: `[]`, err: "batched content mode", unsupported: true},
+		{name: "malformed", contentType: ContentTypeStructured, body: `{`, err: "decode structured event"},
+		{name: "spec version", contentType: ContentTypeStructured, body: `{"specversion": "0.3", "type": "t", "source": "s", "id": "1"}`, err: `unsupported specversion "0.3"`},
+		{name: "missing id", contentType: ContentTypeStructured, body: `{"specversion": "1.0", "type": "t", "source": "s"}`, err: `missing required attribute "id"`},
+		{name: "invalid time", contentType: ContentTypeStructured, body: `{"specversion": "1.0", "type": "t", "source": "s", "id": "1", "time": "now"}`, err: "invalid time attribute"},
+		{
+			name:        "non-JSON data",
+			contentType: "text/plain",
+			headers:     map[string]string{"ce-specversion": "1.0", "ce-type": "t", "ce-source": "s", "ce-id": "1"},
+			body:        "disk full",
+			err:         `data content type "text/plain" is not JSON`,
+			unsupported: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/cloudevents", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			_, err := ReadRequest(req)
+			require.ErrorContains(t, err, tt.err)
+			require.Equal(t, tt.unsupported, errors.Cause(err) == ErrUnsupportedMediaType)
+		})
+	}
+}
+
+func TestEvent_Receiver(t *testing.T) {
+	conf := &config.CloudEventsConfig{ReceiverExtension: "team", DefaultReceiver: "fallback"}
+	require.Equal(t, "team-a", (&Event{Extensions: map[string]string{"team": "team-a"}}).Receiver(conf))
+	require.Equal(t, "fallback", (&Event{Extensions: map[string]string{"receiver": "team-a"}}).Receiver(conf))
+}
+
+func TestEvent_ToData_Types(t *testing.T) {
+	event := &Event{Type: "com.example.alert.opened", Source: "s", Subject: "x"}
+	conf := &config.CloudEventsConfig{
+		FiringTypes:   []string{"com.example.alert.opened"},
+		ResolvedTypes: []string{"com.example.alert.closed"},
+	}
+	data, err := event.ToData(conf, "r")
+	require.NoError(t, err)
+	require.Equal(t, alertmanager.AlertFiring, data.Status)
+
+	event.Type = "com.example.alert.closed"
+	data, err = event.ToData(conf, "r")
+	require.NoError(t, err)
+	require.Equal(t, alertmanager.AlertResolved, data.Status)
+
+	event.Type = "com.example.alert.resolved"
+	_, err = event.ToData(conf, "r")
+	require.ErrorContains(t, err, `unsupported event type "com.example.alert.resolved"`)
+
+	// Without configured types, the ".resolved" suffix tells resolved events.
+	data, err = event.ToData(&config.CloudEventsConfig{}, "r")
+	require.NoError(t, err)
+	require.Equal(t, alertmanager.AlertResolved, data.Status)
+}
+
+func TestEvent_ToData_Errors(t *testing.T) {
+	_, err := (&Event{Type: "t", Source: "s"}).ToData(eventsConfig(), "r")
+	require.ErrorContains(t, err, "missing subject attribute")
+
+	_, err = (&Event{Type: "t", Source: "s", Subject: "x", Data: []byte(`[1]`)}).ToData(eventsConfig(), "r")
+	require.ErrorContains(t, err, "data must be a JSON object")
+}
+
+func TestEvent_ToData_Identity(t *testing.T) {
+	fingerprint := func(source, subject string) string {
+		data, err := (&Event{Type: "t", Source: source, Subject: subject}).ToData(eventsConfig(), "r")
+		require.NoError(t, err)
+		return data.Alerts[0].Fingerprint
+	}
+	require.Equal(t, fingerprint("s", "x"), fingerprint("s", "x"))
+	require.NotEqual(t, fingerprint("s", "x"), fingerprint("s", "y"))
+	require.NotEqual(t, fingerprint("s", "x"), fingerprint("t", "x"))
+}
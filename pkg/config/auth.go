@@ -0,0 +1,206 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// AuthType is the authentication method of an auth profile.
+type AuthType string
+
+// The supported authentication methods.
+const (
+	AuthServicePrincipalSecret      AuthType = "service_principal_secret"
+	AuthServicePrincipalCertificate AuthType = "service_principal_certificate"
+	AuthWorkloadIdentity            AuthType = "workload_identity"
+	AuthManagedIdentity             AuthType = "managed_identity"
+	AuthAzureCLI                    AuthType = "azure_cli"
+	AuthPAT                         AuthType = "pat"
+	AuthDefaultChain                AuthType = "default_chain"
+)
+
+// authTypes lists the supported authentication methods, in the order of the documentation.
+var authTypes = []AuthType{AuthServicePrincipalSecret, AuthServicePrincipalCertificate, AuthWorkloadIdentity, AuthManagedIdentity, AuthAzureCLI, AuthPAT, AuthDefaultChain}
+
+// authFieldNames lists the credential fields of auth profiles.
+var authFieldNames = []string{"tenant_id", "client_id", "client_secret", "client_secret_file", "certificate_path", "certificate_password", "certificate_password_file", "personal_access_token", "personal_access_token_file"}
+
+// authFields lists the fields used by every authentication method, required ones first: the first count of each are
+// required. A required secret can be set through its file instead, named after it with a _file suffix.
+var authFields = map[AuthType]struct {
+	fields   []string
+	required int
+}{
+	AuthServicePrincipalSecret:      {[]string{"tenant_id", "client_id", "client_secret", "client_secret_file"}, 3},
+	AuthServicePrincipalCertificate: {[]string{"tenant_id", "client_id", "certificate_path", "certificate_password", "certificate_password_file"}, 3},
+	AuthWorkloadIdentity:            {[]string{"tenant_id", "client_id"}, 0},
+	AuthManagedIdentity:             {[]string{"client_id"}, 0},
+	AuthAzureCLI:                    {[]string{"tenant_id"}, 0},
+	AuthPAT:                         {[]string{"personal_access_token", "personal_access_token_file"}, 1},
+	AuthDefaultChain:                {[]string{"tenant_id"}, 0},
+}
+
+// AuthConfig is an explicit authentication profile, instead of the authentication method implied by the credential
+// fields that are set. Depending on Type:
+//
+//   - service_principal_secret requires TenantID, ClientID and ClientSecret or ClientSecretFile.
+//   - service_principal_certificate requires TenantID, ClientID and CertificatePath, a PEM or PKCS#12 file holding the
+//     certificate and its private key, encrypted with CertificatePassword or the password read from
+//     CertificatePasswordFile. Both files are read again when they change on disk.
+//   - workload_identity uses the TenantID and ClientID, which default to the AZURE_TENANT_ID and AZURE_CLIENT_ID
+//     environment variables.
+//   - managed_identity uses the user-assigned identity ClientID, or the system-assigned identity.
+//   - azure_cli uses the account logged in with the Azure CLI, optionally in TenantID.
+//   - pat requires PersonalAccessToken or PersonalAccessTokenFile.
+//   - default_chain uses the credential chain of the Azure SDK (environment, workload identity, managed identity, Azure
+//     CLI, ...), optionally in TenantID.
+//
+// Secrets set through files are read again on every connection. Inline secrets can reference Azure Key Vault secrets.
+type AuthConfig struct {
+	Type AuthType `yaml:"type" json:"type"`
+
+	TenantID            string `yaml:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	ClientID            string `yaml:"client_id,omitempty" json:"client_id,omitempty"`
+	ClientSecret        Secret `yaml:"client_secret,omitempty" json:"client_secret,omitempty"`
+	ClientSecretFile    string `yaml:"client_secret_file,omitempty" json:"client_secret_file,omitempty"`
+	CertificatePath     string `yaml:"certificate_path,omitempty" json:"certificate_path,omitempty"`
+	CertificatePassword Secret `yaml:"certificate_password,omitempty" json:"certificate_password,omitempty"`
+	// File holding the certificate password, e.g. from a mounted secret. Trailing newlines are ignored.
+	CertificatePasswordFile string `yaml:"certificate_password_file,omitempty" json:"certificate_password_file,omitempty"`
+	PersonalAccessToken     Secret `yaml:"personal_access_token,omitempty" json:"personal_access_token,omitempty"`
+	PersonalAccessTokenFile string `yaml:"personal_access_token_file,omitempty" json:"personal_access_token_file,omitempty"`
+
+	// Catches all undefined fields and must be empty after parsing.
+	XXX map[string]interface{} `yaml:",inline" json:"-"`
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (a *AuthConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain AuthConfig
+	if err := unmarshal((*plain)(a)); err != nil {
+		return err
+	}
+	if err := checkOverflow(a.XXX, "auth"); err != nil {
+		return err
+	}
+	return a.validate()
+}
+
+// values returns the values of the fields by name.
+func (a *AuthConfig) values() map[string]string {
+	return map[string]string{
+		"tenant_id":                  a.TenantID,
+		"client_id":                  a.ClientID,
+		"client_secret":              string(a.ClientSecret),
+		"client_secret_file":         a.ClientSecretFile,
+		"certificate_path":           a.CertificatePath,
+		"certificate_password":       string(a.CertificatePassword),
+		"certificate_password_file":  a.CertificatePasswordFile,
+		"personal_access_token":      string(a.PersonalAccessToken),
+		"personal_access_token_file": a.PersonalAccessTokenFile,
+	}
+}
+
+func (a *AuthConfig) validate() error {
+	spec, ok := authFields[a.Type]
+	if !ok {
+		var types []string
+		for _, t := range authTypes {
+			types = append(types, string(t))
+		}
+		return fmt.Errorf("unknown auth type %q, must be one of %s", a.Type, strings.Join(types, ", "))
+	}
+
+	values := a.values()
+	used := map[string]bool{}
+	for i, name := range spec.fields {
+		used[name] = true
+		if i < spec.required && values[name] == "" && values[name+"_file"] == "" {
+			if _, ok := values[name+"_file"]; ok {
+				return fmt.Errorf("auth type %q requires '%s' or '%s_file'", a.Type, name, name)
+			}
+			return fmt.Errorf("auth type %q requires '%s'", a.Type, name)
+		}
+	}
+	for _, name := range authFieldNames {
+		if values[name] != "" && !used[name] {
+			return fmt.Errorf("auth type %q does not use '%s'", a.Type, name)
+		}
+	}
+	for _, secret := range []struct {
+		name  string
+		value Secret
+		file  string
+	}{
+		{"client_secret", a.ClientSecret, a.ClientSecretFile},
+		{"certificate_password", a.CertificatePassword, a.CertificatePasswordFile},
+		{"personal_access_token", a.PersonalAccessToken, a.PersonalAccessTokenFile},
+	} {
+		if err := checkSecret(secret.name, secret.value, secret.file); err != nil {
+			return fmt.Errorf("auth type %q: %s", a.Type, err)
+		}
+	}
+	return nil
+}
+
+// The supported Azure clouds.
+const (
+	CloudAzurePublic       = "AzurePublic"
+	CloudAzureChina        = "AzureChina"
+	CloudAzureUSGovernment = "AzureUSGovernment"
+)
+
+// AzureConfig holds the Azure environment the credentials authenticate in. Fields not set fall back to the standard
+// environment variables of the Azure SDK: AZURE_AUTHORITY_HOST for the authority host and AZURE_FEDERATED_TOKEN_FILE
+// for the workload identity token.
+type AzureConfig struct {
+	// Sovereign cloud: AzurePublic, AzureChina or AzureUSGovernment. Optional (default: AzurePublic).
+	Cloud string `yaml:"cloud,omitempty" json:"cloud,omitempty"`
+	// Microsoft Entra ID authority host, overriding the one of the cloud.
+	AuthorityHost string `yaml:"authority_host,omitempty" json:"authority_host,omitempty"`
+	// Projected service account token of workload identity.
+	FederatedTokenFile string `yaml:"federated_token_file,omitempty" json:"federated_token_file,omitempty"`
+	// Scope of the Azure DevOps tokens. Optional (default: the Azure DevOps resource).
+	Scope string `yaml:"scope,omitempty" json:"scope,omitempty"`
+
+	// Catches all undefined fields and must be empty after parsing.
+	XXX map[string]interface{} `yaml:",inline" json:"-"`
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (a *AzureConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain AzureConfig
+	if err := unmarshal((*plain)(a)); err != nil {
+		return err
+	}
+	if err := checkOverflow(a.XXX, "azure"); err != nil {
+		return err
+	}
+	switch a.Cloud {
+	case "", CloudAzurePublic, CloudAzureChina, CloudAzureUSGovernment:
+	default:
+		return fmt.Errorf("unknown cloud %q, must be one of %s, %s, %s", a.Cloud, CloudAzurePublic, CloudAzureChina, CloudAzureUSGovernment)
+	}
+	if a.AuthorityHost != "" {
+		u, err := url.Parse(a.AuthorityHost)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("invalid authority_host %q, must be an https URL", a.AuthorityHost)
+		}
+	}
+	return nil
+}
@@ -20,6 +20,8 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
@@ -29,6 +31,15 @@ import (
 	yaml "gopkg.in/yaml.v3"
 )
 
+const (
+	// defaultReceiverExtension is the CloudEvents extension attribute routing events when receiver_extension is not
+	// set.
+	defaultReceiverExtension = "receiver"
+)
+
+// extensionNameRE matches valid CloudEvents attribute names.
+var extensionNameRE = regexp.MustCompile(`^[a-z0-9]{1,20}$`)
+
 // Secret is a string that must not be revealed on marshaling.
 type Secret string
 
@@ -56,46 +67,154 @@ func Load(s string) (*Config, error) {
 	return cfg, nil
 }
 
-// LoadFile parses the given YAML file into a Config.
-func LoadFile(filename string, logger log.Logger) (*Config, []byte, error) {
+// LoadFile parses the given YAML file into a Config. With strictEnv, environment variables referenced without default
+// value must be set. The receivers of the files matching receiver_files are added to the configuration.
+func LoadFile(filename string, strictEnv bool, logger log.Logger) (*Config, []byte, error) {
 	level.Info(logger).Log("msg", "loading configuration", "path", filename)
 	content, err := os.ReadFile(filename)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	content, err = substituteEnvVars(content, logger)
+	content, err = substituteEnvVars(content, strictEnv, logger)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	cfg, err := Load(string(content))
+	included, err := loadReceiverFiles(filepath.Dir(filename), content, strictEnv, logger)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	cfg := &Config{included: included}
+	if err := yaml.Unmarshal(content, cfg); err != nil {
+		return nil, nil, err
+	}
+
 	resolveFilepaths(filepath.Dir(filename), cfg, logger)
 	return cfg, content, nil
 }
 
-// expand env variables $(var) from the config file
-// taken from https://github.dev/thanos-io/thanos/blob/296c4ab4baf2c8dd6abdf2649b0660ac77505e63/pkg/reloader/reloader.go#L445-L462 by https://github.com/fabxc
-func substituteEnvVars(b []byte, logger log.Logger) (r []byte, err error) {
-	var envRe = regexp.MustCompile(`\$\(([a-zA-Z_0-9]+)\)`)
-	r = envRe.ReplaceAllFunc(b, func(n []byte) []byte {
+// receiverFile is the content of a receiver file.
+type receiverFile struct {
+	Receivers []*ReceiverConfig `yaml:"receivers"`
+
+	// Catches all undefined fields and must be empty after parsing.
+	XXX map[string]interface{} `yaml:",inline" json:"-"`
+}
+
+// loadReceiverFiles loads the receivers of the files matching the receiver_files globs of the configuration content,
+// relative to baseDir. Relative paths of the receivers are resolved against the directories of their files.
+func loadReceiverFiles(baseDir string, content []byte, strictEnv bool, logger log.Logger) ([]*ReceiverConfig, error) {
+	var main struct {
+		ReceiverFiles []string `yaml:"receiver_files"`
+	}
+	if err := yaml.Unmarshal(content, &main); err != nil {
+		return nil, err
+	}
+
+	var receivers []*ReceiverConfig
+	seen := map[string]bool{}
+	for _, pattern := range main.ReceiverFiles {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(baseDir, pattern)
+		}
+		files, err := filepath.Glob(pattern)
 		if err != nil {
-			return nil
+			return nil, fmt.Errorf("bad config in receiver_files: %s", err)
+		}
+		if len(files) == 0 {
+			level.Warn(logger).Log("msg", "no receiver files match", "pattern", pattern)
+		}
+		for _, file := range files {
+			if seen[file] {
+				continue
+			}
+			seen[file] = true
+
+			rcs, err := loadReceiverFile(file, strictEnv, logger)
+			if err != nil {
+				return nil, err
+			}
+			receivers = append(receivers, rcs...)
 		}
+	}
+	return receivers, nil
+}
+
+func loadReceiverFile(file string, strictEnv bool, logger log.Logger) ([]*ReceiverConfig, error) {
+	level.Debug(logger).Log("msg", "loading receiver file", "path", file)
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	content, err = substituteEnvVars(content, strictEnv, logger)
+	if err != nil {
+		return nil, fmt.Errorf("bad receiver file %s: %s", file, err)
+	}
+	var rf receiverFile
+	if err := yaml.Unmarshal(content, &rf); err != nil {
+		return nil, fmt.Errorf("bad receiver file %s: %s", file, err)
+	}
+	if err := checkOverflow(rf.XXX, "receiver file"); err != nil {
+		return nil, fmt.Errorf("bad receiver file %s: %s", file, err)
+	}
 
-		n = n[2 : len(n)-1]
+	// Resolve the paths before the receivers inherit relative paths of the main configuration file.
+	dir, err := filepath.Abs(filepath.Dir(file))
+	if err != nil {
+		return nil, err
+	}
+	resolveFilepaths(dir, &Config{Defaults: &ReceiverConfig{}, Receivers: rf.Receivers}, logger)
+	for _, rc := range rf.Receivers {
+		if rc != nil {
+			rc.file = file
+		}
+	}
+	return rf.Receivers, nil
+}
+
+// source describes where the receiver was defined.
+func (rc *ReceiverConfig) source() string {
+	if rc.file == "" {
+		return "the configuration file"
+	}
+	return rc.file
+}
+
+// envRe matches the environment variable references $(VAR), ${VAR} and ${VAR:-default}.
+var envRe = regexp.MustCompile(`\$\(([a-zA-Z_0-9]+)\)|\$\{([a-zA-Z_][a-zA-Z_0-9]*)(:-([^}]*))?\}`)
+
+// expand env variables $(var), ${var} and ${var:-default} from the config file. The default is used when the variable
+// is unset or empty. Missing variables without default are replaced with an empty value, or fail with strict.
+// taken from https://github.dev/thanos-io/thanos/blob/296c4ab4baf2c8dd6abdf2649b0660ac77505e63/pkg/reloader/reloader.go#L445-L462 by https://github.com/fabxc
+func substituteEnvVars(b []byte, strict bool, logger log.Logger) (r []byte, err error) {
+	var missing []string
+	r = envRe.ReplaceAllFunc(b, func(n []byte) []byte {
+		m := envRe.FindSubmatch(n)
+		name := string(m[1])
+		if name == "" {
+			name = string(m[2])
+		}
 
-		v, ok := os.LookupEnv(string(n))
+		v, ok := os.LookupEnv(name)
+		if m[3] != nil && v == "" {
+			return m[4]
+		}
 		if !ok {
-			level.Warn(logger).Log("msg", "missing environment variable, using empty value", "var", string(n))
+			if strict {
+				missing = append(missing, name)
+				return nil
+			}
+			level.Warn(logger).Log("msg", "missing environment variable, using empty value", "var", name)
 			return []byte("") // Continue with empty string instead of failing
 		}
 		return []byte(v)
 	})
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("missing environment variables: %s", strings.Join(slices.Compact(missing), ", "))
+	}
 	return r, err
 }
 
@@ -111,7 +230,103 @@ func resolveFilepaths(baseDir string, cfg *Config, logger log.Logger) {
 		return absFp
 	}
 
-	cfg.Template = join(cfg.Template)
+	for i, t := range cfg.Template {
+		cfg.Template[i] = join(t)
+	}
+	for _, auth := range cfg.authConfigs() {
+		auth.CertificatePath = join(auth.CertificatePath)
+		auth.CertificatePasswordFile = join(auth.CertificatePasswordFile)
+		auth.ClientSecretFile = join(auth.ClientSecretFile)
+		auth.PersonalAccessTokenFile = join(auth.PersonalAccessTokenFile)
+	}
+	// Receivers share the inherited targets.
+	targets := map[*Target]bool{}
+	for _, rc := range append([]*ReceiverConfig{cfg.Defaults}, cfg.Receivers...) {
+		if rc == nil {
+			continue
+		}
+		rc.ClientSecretFile = join(rc.ClientSecretFile)
+		rc.PersonalAccessTokenFile = join(rc.PersonalAccessTokenFile)
+		for i, t := range rc.Template {
+			rc.Template[i] = join(t)
+		}
+		for _, t := range rc.Targets {
+			if !targets[t] {
+				targets[t] = true
+				t.ClientSecretFile = join(t.ClientSecretFile)
+				t.PersonalAccessTokenFile = join(t.PersonalAccessTokenFile)
+			}
+		}
+	}
+	for _, creds := range cfg.Credentials {
+		creds.ClientSecretFile = join(creds.ClientSecretFile)
+		creds.PersonalAccessTokenFile = join(creds.PersonalAccessTokenFile)
+	}
+	// Receivers share the inherited Azure configuration.
+	seen := map[*AzureConfig]bool{}
+	for _, rc := range append([]*ReceiverConfig{cfg.Defaults}, cfg.Receivers...) {
+		if rc != nil && rc.Azure != nil && !seen[rc.Azure] {
+			seen[rc.Azure] = true
+			rc.Azure.FederatedTokenFile = join(rc.Azure.FederatedTokenFile)
+		}
+	}
+}
+
+// authConfigs returns all auth profiles of the configuration, once each as receivers share the inherited ones.
+func (c *Config) authConfigs() []*AuthConfig {
+	var auths []*AuthConfig
+	seen := map[*AuthConfig]bool{}
+	add := func(auth *AuthConfig) {
+		if auth != nil && !seen[auth] {
+			seen[auth] = true
+			auths = append(auths, auth)
+		}
+	}
+	add(c.Defaults.Auth)
+	for _, rc := range c.Receivers {
+		add(rc.Auth)
+		for _, t := range rc.Targets {
+			add(t.Auth)
+		}
+	}
+	for _, creds := range c.Credentials {
+		add(creds.Auth)
+	}
+	return auths
+}
+
+// Templates is a list of template files or glob patterns, written as a single one or as a list.
+type Templates []string
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (t *Templates) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var single string
+	if err := unmarshal(&single); err == nil {
+		*t = nil
+		if single != "" {
+			*t = Templates{single}
+		}
+		return nil
+	}
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return err
+	}
+	for _, path := range list {
+		if path == "" {
+			return fmt.Errorf("empty template path")
+		}
+	}
+	*t = list
+	return nil
+}
+
+// MarshalYAML implements the yaml.Marshaler interface, writing a single template as such.
+func (t Templates) MarshalYAML() (interface{}, error) {
+	if len(t) == 1 {
+		return t[0], nil
+	}
+	return []string(t), nil
 }
 
 // AutoResolve is the struct used for defining work item resolution state when alert is resolved.
@@ -119,6 +334,233 @@ type AutoResolve struct {
 	State string `yaml:"state" json:"state"`
 }
 
+// AttachPayload is the struct used for defining how the raw Alertmanager payload is attached to the work item.
+type AttachPayload struct {
+	FileName       string `yaml:"file_name" json:"file_name"`
+	MaxAttachments int    `yaml:"max_attachments" json:"max_attachments"`
+}
+
+// Parent is the struct used for grouping work items under a parent work item. Key, Summary and Description are
+// templated; every distinct rendered Key maps to one parent work item, which is created when it does not exist yet.
+type Parent struct {
+	Key         string `yaml:"key" json:"key"`
+	IssueType   string `yaml:"issue_type" json:"issue_type"`
+	Summary     string `yaml:"summary" json:"summary"`
+	Description string `yaml:"description" json:"description"`
+
+	// Close the parent work item with the given state when all of its children are closed, checked when the receiver
+	// auto-resolves one of them. Requires auto_resolve.
+	AutoClose *AutoResolve `yaml:"auto_close" json:"auto_close"`
+	// States in which a child counts as closed. Optional (default: the receiver's auto_resolve state).
+	ClosedStates []string `yaml:"closed_states" json:"closed_states"`
+}
+
+// Correlation is the struct used for cross-linking work items of correlated notifications. Notifications are
+// correlated when they share the values of all Labels (taken from the common labels) and their work items were
+// created within Window of each other. Comment is a template posted on every correlated work item.
+type Correlation struct {
+	Labels  []string       `yaml:"labels" json:"labels"`
+	Window  *time.Duration `yaml:"window" json:"window"`
+	Comment string         `yaml:"comment" json:"comment"`
+}
+
+// Escalation is the struct used for escalating work items. Priorities maps values of the alert label Label to work
+// item priorities (1 being the highest); a notification raises the priority of its work item when one of its firing
+// alerts calls for a higher one. Priorities are never lowered automatically.
+type Escalation struct {
+	Label      string         `yaml:"label" json:"label"`
+	Priorities map[string]int `yaml:"priorities" json:"priorities"`
+	SLA        *SLA           `yaml:"sla" json:"sla"`
+}
+
+// SLA is the struct used for escalating work items that stayed in their initial State for longer than After. Such
+// work items are raised to Priority and/or assigned to AssignTo. They are looked up every Interval.
+type SLA struct {
+	State    string         `yaml:"state" json:"state"`
+	After    *time.Duration `yaml:"after" json:"after"`
+	Priority int            `yaml:"priority" json:"priority"`
+	AssignTo string         `yaml:"assign_to" json:"assign_to"`
+	Interval *time.Duration `yaml:"interval" json:"interval"`
+}
+
+// Silence is the struct used for creating Alertmanager silences from Azure DevOps service hooks. A silence lasting
+// Duration is created when a work item moves to AcknowledgedState, or one lasting <duration> when the work item gets
+// a "silence:<duration>" tag. Silences are expired when the work item moves to one of ClosedStates.
+type Silence struct {
+	AcknowledgedState string         `yaml:"acknowledged_state" json:"acknowledged_state"`
+	Duration          *time.Duration `yaml:"duration" json:"duration"`
+	ClosedStates      []string       `yaml:"closed_states" json:"closed_states"`
+	CreatedBy         string         `yaml:"created_by" json:"created_by"`
+}
+
+// Reconcile is the struct used for reconciling open work items with the active alerts in Alertmanager every Interval.
+// Work items whose alerts are not active anymore are auto-resolved, e.g. when their resolved notification was lost.
+type Reconcile struct {
+	Interval *time.Duration `yaml:"interval" json:"interval"`
+}
+
+// AlertmanagerConfig is the configuration for accessing the Alertmanager v2 API.
+type AlertmanagerConfig struct {
+	URL string `yaml:"url" json:"url"`
+
+	// Catches all undefined fields and must be empty after parsing.
+	XXX map[string]interface{} `yaml:",inline" json:"-"`
+}
+
+// PollingConfig is the configuration of the polling mode, in which alert-az-do pulls the alert groups of Receivers
+// (all receivers when empty) from the Alertmanager API every Interval, instead of receiving webhooks.
+type PollingConfig struct {
+	Interval  *time.Duration `yaml:"interval" json:"interval"`
+	Receivers []string       `yaml:"receivers" json:"receivers"`
+
+	// Catches all undefined fields and must be empty after parsing.
+	XXX map[string]interface{} `yaml:",inline" json:"-"`
+}
+
+// InputConfig is the configuration of a generic JSON webhook input, receiving arbitrary JSON documents at
+// /input/<Name> and notifying Receiver of the alerts mapped from them. Every mapped value is either a JSONPath
+// expression (starting with "$") or a template, both applied to the document or, with Alerts set, to each element of
+// the array it selects.
+type InputConfig struct {
+	Name     string `yaml:"name" json:"name"`
+	Receiver string `yaml:"receiver" json:"receiver"`
+
+	Alerts       string            `yaml:"alerts,omitempty" json:"alerts,omitempty"`
+	Status       string            `yaml:"status,omitempty" json:"status,omitempty"`
+	Fingerprint  string            `yaml:"fingerprint,omitempty" json:"fingerprint,omitempty"`
+	Labels       map[string]string `yaml:"labels" json:"labels"`
+	Annotations  map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
+	StartsAt     string            `yaml:"starts_at,omitempty" json:"starts_at,omitempty"`
+	EndsAt       string            `yaml:"ends_at,omitempty" json:"ends_at,omitempty"`
+	GeneratorURL string            `yaml:"generator_url,omitempty" json:"generator_url,omitempty"`
+	GroupBy      []string          `yaml:"group_by,omitempty" json:"group_by,omitempty"`
+
+	// Catches all undefined fields and must be empty after parsing.
+	XXX map[string]interface{} `yaml:",inline" json:"-"`
+}
+
+// CloudEventsConfig is the configuration of the CloudEvents endpoint. Events are routed to the receiver named by the
+// ReceiverExtension attribute (or to DefaultReceiver), their type tells whether the alert is firing or resolved, and
+// the fields of their data become labels, except for AnnotationFields, which become annotations.
+type CloudEventsConfig struct {
+	ReceiverExtension string   `yaml:"receiver_extension,omitempty" json:"receiver_extension,omitempty"`
+	DefaultReceiver   string   `yaml:"default_receiver,omitempty" json:"default_receiver,omitempty"`
+	FiringTypes       []string `yaml:"firing_types,omitempty" json:"firing_types,omitempty"`
+	ResolvedTypes     []string `yaml:"resolved_types,omitempty" json:"resolved_types,omitempty"`
+	AnnotationFields  []string `yaml:"annotation_fields,omitempty" json:"annotation_fields,omitempty"`
+
+	// Catches all undefined fields and must be empty after parsing.
+	XXX map[string]interface{} `yaml:",inline" json:"-"`
+}
+
+// Route is a node of the routing tree, routing notifications to receivers by label matchers. A notification matching
+// the Matchers of a route is routed by its child Routes, or to its Receiver (inherited from the parent route when
+// empty) when none of them matches. Unless Continue is set, the first matching route stops the matching of its
+// following siblings.
+type Route struct {
+	Receiver string   `yaml:"receiver,omitempty" json:"receiver,omitempty"`
+	Matchers Matchers `yaml:"matchers,omitempty" json:"matchers,omitempty"`
+	Continue bool     `yaml:"continue,omitempty" json:"continue,omitempty"`
+	Routes   []*Route `yaml:"routes,omitempty" json:"routes,omitempty"`
+
+	// Catches all undefined fields and must be empty after parsing.
+	XXX map[string]interface{} `yaml:",inline" json:"-"`
+}
+
+// ServiceHookConfig is the configuration of the endpoint receiving Azure DevOps service hooks. When Username is set,
+// requests must authenticate with basic authentication, which receivers with silences require.
+type ServiceHookConfig struct {
+	Username string `yaml:"username" json:"username"`
+	Password Secret `yaml:"password" json:"password"`
+
+	// Catches all undefined fields and must be empty after parsing.
+	XXX map[string]interface{} `yaml:",inline" json:"-"`
+}
+
+// Link is the struct used for defining a hyperlink relation added to the work item. Both URL and Comment are
+// templated; with PerAlert set they are rendered once for every alert of the notification instead of once for the
+// whole notification.
+type Link struct {
+	URL      string `yaml:"url" json:"url"`
+	Comment  string `yaml:"comment" json:"comment"`
+	PerAlert bool   `yaml:"per_alert" json:"per_alert"`
+}
+
+// Credentials is a named set of Azure DevOps credentials, referenced by receivers and targets so that they don't have to
+// repeat secrets.
+type Credentials struct {
+	TenantID            string `yaml:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	ClientID            string `yaml:"client_id,omitempty" json:"client_id,omitempty"`
+	SubscriptionID      string `yaml:"subscription_id,omitempty" json:"subscription_id,omitempty"`
+	ClientSecret        Secret `yaml:"client_secret,omitempty" json:"client_secret,omitempty"`
+	PersonalAccessToken Secret `yaml:"personal_access_token,omitempty" json:"personal_access_token,omitempty"`
+
+	// Files holding the secrets, instead of the fields above.
+	ClientSecretFile        string `yaml:"client_secret_file,omitempty" json:"client_secret_file,omitempty"`
+	PersonalAccessTokenFile string `yaml:"personal_access_token_file,omitempty" json:"personal_access_token_file,omitempty"`
+
+	// Explicit authentication profile, instead of the fields above.
+	Auth *AuthConfig `yaml:"auth,omitempty" json:"auth,omitempty"`
+
+	// Catches all undefined fields and must be empty after parsing.
+	XXX map[string]interface{} `yaml:",inline" json:"-"`
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (c *Credentials) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain Credentials
+	if err := unmarshal((*plain)(c)); err != nil {
+		return err
+	}
+	return checkOverflow(c.XXX, "credentials")
+}
+
+// Target is an Azure DevOps organization and project a receiver creates work items in, each with work items of its own.
+// Fields that are not set are taken from the receiver; credentials are only taken from the receiver as a whole, when
+// the target sets none. Fields are merged with the receiver's, the target's taking precedence.
+type Target struct {
+	Name string `yaml:"name" json:"name"`
+
+	// API access fields
+	Organization            string      `yaml:"organization,omitempty" json:"organization,omitempty"`
+	TenantID                string      `yaml:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	ClientID                string      `yaml:"client_id,omitempty" json:"client_id,omitempty"`
+	SubscriptionID          string      `yaml:"subscription_id,omitempty" json:"subscription_id,omitempty"`
+	ClientSecret            Secret      `yaml:"client_secret,omitempty" json:"client_secret,omitempty"`
+	PersonalAccessToken     Secret      `yaml:"personal_access_token,omitempty" json:"personal_access_token,omitempty"`
+	ClientSecretFile        string      `yaml:"client_secret_file,omitempty" json:"client_secret_file,omitempty"`
+	PersonalAccessTokenFile string      `yaml:"personal_access_token_file,omitempty" json:"personal_access_token_file,omitempty"`
+	Credentials             string      `yaml:"credentials,omitempty" json:"credentials,omitempty"`
+	Auth                    *AuthConfig `yaml:"auth,omitempty" json:"auth,omitempty"`
+
+	// Issue fields
+	Project   string                 `yaml:"project,omitempty" json:"project,omitempty"`
+	IssueType string                 `yaml:"issue_type,omitempty" json:"issue_type,omitempty"`
+	Fields    map[string]interface{} `yaml:"fields,omitempty" json:"fields,omitempty"`
+
+	// Catches all undefined fields and must be empty after parsing.
+	XXX map[string]interface{} `yaml:",inline" json:"-"`
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (t *Target) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain Target
+	if err := unmarshal((*plain)(t)); err != nil {
+		return err
+	}
+	return checkOverflow(t.XXX, "target")
+}
+
+// hasCredentials reports whether the target sets any credential, replacing those of the receiver.
+func (t *Target) hasCredentials() bool {
+	return t.hasInlineCredentials() || t.Credentials != "" || t.Auth != nil
+}
+
+// hasInlineCredentials reports whether any credential field is set on the target itself.
+func (t *Target) hasInlineCredentials() bool {
+	return t.TenantID != "" || t.ClientID != "" || t.SubscriptionID != "" || secretSet(t.ClientSecret, t.ClientSecretFile) || secretSet(t.PersonalAccessToken, t.PersonalAccessTokenFile)
+}
+
 // ReceiverConfig is the configuration for one receiver. It has a unique name and includes API access fields (url and
 // auth) and issue fields (required -- e.g. project, issue type -- and optional -- e.g. priority).
 type ReceiverConfig struct {
@@ -132,6 +574,20 @@ type ReceiverConfig struct {
 	ClientSecret        Secret `yaml:"client_secret" json:"client_secret"`
 	PersonalAccessToken Secret `yaml:"personal_access_token" json:"personal_access_token"`
 
+	// Files holding the secrets, instead of the fields above. They are read again on every connection, so that rotated
+	// secrets are picked up.
+	ClientSecretFile        string `yaml:"client_secret_file,omitempty" json:"client_secret_file,omitempty"`
+	PersonalAccessTokenFile string `yaml:"personal_access_token_file,omitempty" json:"personal_access_token_file,omitempty"`
+
+	// Name of the credentials to use from the credentials section, instead of the fields above. Templated.
+	Credentials string `yaml:"credentials,omitempty" json:"credentials,omitempty"`
+
+	// Explicit authentication profile, instead of the fields above.
+	Auth *AuthConfig `yaml:"auth,omitempty" json:"auth,omitempty"`
+
+	// Azure cloud, authority host, workload identity token file and token scope.
+	Azure *AzureConfig `yaml:"azure,omitempty" json:"azure,omitempty"`
+
 	// Required issue fields
 	Project        string         `yaml:"project" json:"project"`
 	OtherProjects  []string       `yaml:"other_projects" json:"other_projects"`
@@ -161,6 +617,51 @@ type ReceiverConfig struct {
 	// Flag to auto-resolve opened issue when the alert is resolved.
 	AutoResolve *AutoResolve `yaml:"auto_resolve" json:"auto_resolve"`
 
+	// Hyperlink relations added to the work item, e.g. to Prometheus, Alertmanager or runbooks.
+	Links []*Link `yaml:"links" json:"links"`
+
+	// Attach the raw Alertmanager payload to the work item on create and on each update.
+	AttachPayload *AttachPayload `yaml:"attach_payload" json:"attach_payload"`
+
+	// Group work items under a parent work item, e.g. per alertname or per service.
+	Parent *Parent `yaml:"parent" json:"parent"`
+
+	// Rules for cross-linking work items created for correlated notifications.
+	Correlations []*Correlation `yaml:"correlations" json:"correlations"`
+
+	// Raise the priority of work items on more severe alerts and when they are not picked up in time.
+	Escalation *Escalation `yaml:"escalation" json:"escalation"`
+
+	// Create Alertmanager silences when work items are acknowledged in Azure DevOps.
+	Silence *Silence `yaml:"silence" json:"silence"`
+
+	// Periodically auto-resolve work items whose alerts are not active in Alertmanager anymore. Only for receivers
+	// notified by the Alertmanager receiver of the same name.
+	Reconcile *Reconcile `yaml:"reconcile" json:"reconcile"`
+
+	// Only handle the alerts matching all Include matchers and none of the Exclude matchers.
+	Include Matchers `yaml:"include,omitempty" json:"include,omitempty"`
+	Exclude Matchers `yaml:"exclude,omitempty" json:"exclude,omitempty"`
+
+	// Rewrite or drop the labels of the alerts, before templating and fingerprinting. Not with silences, reconciliation
+	// or polling, which look up the Alertmanager fingerprints.
+	RelabelConfigs []*RelabelConfig `yaml:"relabel_configs,omitempty" json:"relabel_configs,omitempty"`
+
+	// Create work items in each of these organizations and projects, instead of the receiver's own.
+	Targets []*Target `yaml:"targets,omitempty" json:"targets,omitempty"`
+
+	// Template files of the receiver, layered over the global template files so that they can redefine templates.
+	Template Templates `yaml:"template,omitempty" json:"template,omitempty"`
+
+	// Name of the target the configuration was derived for by TargetConfigs.
+	Target string `yaml:"-" json:"-"`
+
+	// The credentials section, to look up Credentials in.
+	credentials map[string]*Credentials
+
+	// The receiver file the receiver was loaded from, empty for the main configuration file.
+	file string
+
 	// Catches all undefined fields and must be empty after parsing.
 	XXX map[string]interface{} `yaml:",inline" json:"-"`
 }
@@ -178,7 +679,34 @@ func (rc *ReceiverConfig) UnmarshalYAML(unmarshal func(interface{}) error) error
 type Config struct {
 	Defaults  *ReceiverConfig   `yaml:"defaults,omitempty" json:"defaults,omitempty"`
 	Receivers []*ReceiverConfig `yaml:"receivers,omitempty" json:"receivers,omitempty"`
-	Template  string            `yaml:"template" json:"template"`
+	Template  Templates         `yaml:"template" json:"template"`
+
+	// Glob patterns of files holding further receivers, relative to the configuration file.
+	ReceiverFiles []string `yaml:"receiver_files,omitempty" json:"receiver_files,omitempty"`
+
+	// Named credentials, referenced by receivers and targets.
+	Credentials map[string]*Credentials `yaml:"credentials,omitempty" json:"credentials,omitempty"`
+
+	// Routing of notifications to receivers by label matchers.
+	Route *Route `yaml:"route,omitempty" json:"route,omitempty"`
+
+	// Alertmanager API access, e.g. for creating silences.
+	Alertmanager *AlertmanagerConfig `yaml:"alertmanager,omitempty" json:"alertmanager,omitempty"`
+
+	// Pull alerts from Alertmanager instead of receiving webhooks.
+	Polling *PollingConfig `yaml:"polling,omitempty" json:"polling,omitempty"`
+
+	// Endpoint receiving Azure DevOps service hooks.
+	ServiceHook *ServiceHookConfig `yaml:"service_hook,omitempty" json:"service_hook,omitempty"`
+
+	// Generic JSON webhook inputs.
+	Inputs []*InputConfig `yaml:"inputs,omitempty" json:"inputs,omitempty"`
+
+	// Endpoint receiving CloudEvents.
+	CloudEvents *CloudEventsConfig `yaml:"cloudevents,omitempty" json:"cloudevents,omitempty"`
+
+	// Receivers loaded from the receiver files, added to Receivers on unmarshaling.
+	included []*ReceiverConfig
 
 	// Catches all undefined fields and must be empty after parsing.
 	XXX map[string]interface{} `yaml:",inline" json:"-"`
@@ -215,6 +743,7 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	if err := unmarshal((*plain)(c)); err != nil {
 		return err
 	}
+	c.Receivers = append(c.Receivers, c.included...)
 
 	// Initialize defaults if it's nil to prevent panics
 	if c.Defaults == nil {
@@ -223,9 +752,12 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	}
 
 	// Check for mutually exclusive authentication methods in defaults
-	hasServicePrincipal := c.Defaults.TenantID != "" && c.Defaults.ClientID != "" && c.Defaults.ClientSecret != ""
+	if err := checkSecrets(c.Defaults.ClientSecret, c.Defaults.ClientSecretFile, c.Defaults.PersonalAccessToken, c.Defaults.PersonalAccessTokenFile); err != nil {
+		return fmt.Errorf("bad auth config in defaults section: %s", err)
+	}
+	hasServicePrincipal := c.Defaults.TenantID != "" && c.Defaults.ClientID != "" && secretSet(c.Defaults.ClientSecret, c.Defaults.ClientSecretFile)
 	hasManagedIdentity := c.Defaults.ClientID != "" && c.Defaults.SubscriptionID != ""
-	hasPAT := c.Defaults.PersonalAccessToken != ""
+	hasPAT := secretSet(c.Defaults.PersonalAccessToken, c.Defaults.PersonalAccessTokenFile)
 
 	authMethodCount := 0
 	if hasServicePrincipal {
@@ -241,6 +773,32 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	if authMethodCount > 1 {
 		return fmt.Errorf("bad auth config in defaults section: Service Principal (TenantID+ClientID+ClientSecret), Managed Identity (ClientID+SubscriptionID), and PAT authentication are mutually exclusive")
 	}
+	if c.Defaults.Credentials != "" && c.Defaults.hasInlineCredentials() {
+		return fmt.Errorf("bad auth config in defaults section: 'credentials', 'auth' and inline credentials are mutually exclusive")
+	}
+	if c.Defaults.Auth != nil && (hasServicePrincipal || hasManagedIdentity || hasPAT) {
+		return fmt.Errorf("bad auth config in defaults section: 'credentials', 'auth' and inline credentials are mutually exclusive")
+	}
+	for name, creds := range c.Credentials {
+		clientSecret := secretSet(creds.ClientSecret, creds.ClientSecretFile)
+		pat := secretSet(creds.PersonalAccessToken, creds.PersonalAccessTokenFile)
+		if creds.Auth != nil {
+			if creds.TenantID != "" || creds.ClientID != "" || creds.SubscriptionID != "" || clientSecret || pat {
+				return fmt.Errorf("bad auth config in credentials %q: 'auth' and inline credentials are mutually exclusive", name)
+			}
+			continue
+		}
+		if err := checkSecrets(creds.ClientSecret, creds.ClientSecretFile, creds.PersonalAccessToken, creds.PersonalAccessTokenFile); err != nil {
+			return fmt.Errorf("bad auth config in credentials %q: %s", name, err)
+		}
+		if err := checkAuth(creds.TenantID, creds.ClientID, creds.SubscriptionID, clientSecret, pat); err != nil {
+			return fmt.Errorf("bad auth config in credentials %q: %s", name, err)
+		}
+	}
+
+	if len(c.Defaults.Template) > 0 {
+		return fmt.Errorf("bad config in defaults section: 'template' must be set at the top level")
+	}
 
 	if c.Defaults.AutoResolve != nil {
 		if c.Defaults.AutoResolve.State == "" {
@@ -248,10 +806,29 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		}
 	}
 
+	if c.Defaults.AttachPayload != nil && c.Defaults.AttachPayload.MaxAttachments < 0 {
+		return fmt.Errorf("bad config in defaults section: 'attach_payload' max_attachments cannot be negative")
+	}
+
+	for _, l := range c.Defaults.Links {
+		if l.URL == "" {
+			return fmt.Errorf("bad config in defaults section: 'links' entry defined with empty 'url' field")
+		}
+	}
+
+	indirectReceivers := c.indirectReceivers()
+	receivers := map[string]*ReceiverConfig{}
 	for _, rc := range c.Receivers {
 		if rc.Name == "" {
 			return fmt.Errorf("missing name for receiver %+v", rc)
 		}
+		if other, ok := receivers[rc.Name]; ok {
+			if other.file == "" && rc.file == "" {
+				return fmt.Errorf("duplicate receiver name %q", rc.Name)
+			}
+			return fmt.Errorf("duplicate receiver name %q in %s and %s", rc.Name, other.source(), rc.source())
+		}
+		receivers[rc.Name] = rc
 
 		// Check API access fields.
 		if rc.Organization == "" {
@@ -260,14 +837,20 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 			}
 			rc.Organization = c.Defaults.Organization
 		}
-		if _, err := url.Parse(rc.Organization); err != nil {
-			return fmt.Errorf("invalid organization %q in receiver %q: %s", rc.Organization, rc.Name, err)
+		if !isTemplated(rc.Organization) {
+			if _, err := url.Parse(rc.Organization); err != nil {
+				return fmt.Errorf("invalid organization %q in receiver %q: %s", rc.Organization, rc.Name, err)
+			}
 		}
+		rc.credentials = c.Credentials
 
 		// Check for mutually exclusive authentication methods in receiver
-		rcServicePrincipal := rc.TenantID != "" && rc.ClientID != "" && rc.ClientSecret != ""
+		if err := checkSecrets(rc.ClientSecret, rc.ClientSecretFile, rc.PersonalAccessToken, rc.PersonalAccessTokenFile); err != nil {
+			return fmt.Errorf("bad auth config in receiver %q: %s", rc.Name, err)
+		}
+		rcServicePrincipal := rc.TenantID != "" && rc.ClientID != "" && secretSet(rc.ClientSecret, rc.ClientSecretFile)
 		rcManagedIdentity := rc.ClientID != "" && rc.SubscriptionID != ""
-		rcPAT := rc.PersonalAccessToken != ""
+		rcPAT := secretSet(rc.PersonalAccessToken, rc.PersonalAccessTokenFile)
 
 		rcAuthMethodCount := 0
 		if rcServicePrincipal {
@@ -283,6 +866,12 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		if rcAuthMethodCount > 1 {
 			return fmt.Errorf("bad auth config in receiver %q: Service Principal (TenantID+ClientID+ClientSecret), Managed Identity (ClientID+SubscriptionID), and PAT authentication are mutually exclusive", rc.Name)
 		}
+		if rc.Credentials != "" && rc.hasInlineCredentials() {
+			return fmt.Errorf("bad auth config in receiver %q: 'credentials', 'auth' and inline credentials are mutually exclusive", rc.Name)
+		}
+		if rc.Auth != nil && (rc.TenantID != "" || rc.ClientID != "" || rc.SubscriptionID != "" || secretSet(rc.ClientSecret, rc.ClientSecretFile) || rcPAT) {
+			return fmt.Errorf("bad auth config in receiver %q: 'credentials', 'auth' and inline credentials are mutually exclusive", rc.Name)
+		}
 
 		// Determine authentication method and validate completeness
 		if rcPAT {
@@ -291,10 +880,19 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 			// Service Principal authentication is complete - no defaults needed
 		} else if rcManagedIdentity {
 			// Managed Identity authentication is complete - no defaults needed
+		} else if rc.Credentials != "" {
+			// Named credentials, looked up on notification
+		} else if rc.Auth != nil {
+			// Explicit authentication profile
 		} else {
 			// No complete authentication method in receiver, try to inherit from defaults
-			if c.Defaults.PersonalAccessToken != "" {
+			if c.Defaults.Auth != nil {
+				rc.Auth = c.Defaults.Auth
+			} else if c.Defaults.Credentials != "" {
+				rc.Credentials = c.Defaults.Credentials
+			} else if hasPAT {
 				rc.PersonalAccessToken = c.Defaults.PersonalAccessToken
+				rc.PersonalAccessTokenFile = c.Defaults.PersonalAccessTokenFile
 			} else if hasServicePrincipal {
 				// Inherit Service Principal from defaults
 				if rc.TenantID == "" {
@@ -303,8 +901,9 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 				if rc.ClientID == "" {
 					rc.ClientID = c.Defaults.ClientID
 				}
-				if rc.ClientSecret == "" {
+				if !secretSet(rc.ClientSecret, rc.ClientSecretFile) {
 					rc.ClientSecret = c.Defaults.ClientSecret
+					rc.ClientSecretFile = c.Defaults.ClientSecretFile
 				}
 			} else if hasManagedIdentity {
 				// Inherit Managed Identity from defaults
@@ -352,6 +951,9 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		}
 
 		// Populate optional issue fields, where necessary.
+		if rc.Azure == nil {
+			rc.Azure = c.Defaults.Azure
+		}
 		if rc.Priority == "" && c.Defaults.Priority != "" {
 			rc.Priority = c.Defaults.Priority
 		}
@@ -369,6 +971,117 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		if rc.AutoResolve == nil && c.Defaults.AutoResolve != nil {
 			rc.AutoResolve = c.Defaults.AutoResolve
 		}
+		for _, l := range rc.Links {
+			if l.URL == "" {
+				return fmt.Errorf("bad config in receiver %q, 'links' entry defined with empty 'url' field", rc.Name)
+			}
+		}
+		if rc.Links == nil {
+			rc.Links = c.Defaults.Links
+		}
+		if rc.AttachPayload != nil && rc.AttachPayload.MaxAttachments < 0 {
+			return fmt.Errorf("bad config in receiver %q, 'attach_payload' max_attachments cannot be negative", rc.Name)
+		}
+		if rc.AttachPayload == nil {
+			rc.AttachPayload = c.Defaults.AttachPayload
+		}
+		if rc.Parent == nil {
+			rc.Parent = c.Defaults.Parent
+		}
+		if rc.Parent != nil {
+			if err := rc.Parent.validate(rc); err != nil {
+				return fmt.Errorf("bad config in receiver %q, %s", rc.Name, err)
+			}
+		}
+		if rc.Correlations == nil {
+			rc.Correlations = c.Defaults.Correlations
+		}
+		for _, cr := range rc.Correlations {
+			if len(cr.Labels) == 0 {
+				return fmt.Errorf("bad config in receiver %q, 'correlations' entry defined without 'labels'", rc.Name)
+			}
+			if cr.Window == nil || *cr.Window <= 0 {
+				return fmt.Errorf("bad config in receiver %q, 'correlations' entry defined without a positive 'window'", rc.Name)
+			}
+		}
+		if rc.Escalation == nil {
+			rc.Escalation = c.Defaults.Escalation
+		}
+		if rc.Escalation != nil {
+			if err := rc.Escalation.validate(); err != nil {
+				return fmt.Errorf("bad config in receiver %q, %s", rc.Name, err)
+			}
+		}
+		// Silences match the alert groups of the Alertmanager receiver of the same name, which receivers notified
+		// through routes, inputs or CloudEvents do not have.
+		if rc.Silence != nil && indirectReceivers[rc.Name] {
+			return fmt.Errorf("bad config in receiver %q, 'silence' cannot be used with receivers notified through 'route', 'inputs' or 'cloudevents'", rc.Name)
+		}
+		if rc.Silence == nil && !indirectReceivers[rc.Name] {
+			rc.Silence = c.Defaults.Silence
+		}
+		if rc.Silence != nil {
+			if rc.Silence.Duration != nil && *rc.Silence.Duration <= 0 {
+				return fmt.Errorf("bad config in receiver %q, 'silence' duration must be positive", rc.Name)
+			}
+			if rc.Silence.AcknowledgedState != "" && rc.Silence.Duration == nil {
+				return fmt.Errorf("bad config in receiver %q, 'silence.acknowledged_state' requires 'silence.duration'", rc.Name)
+			}
+			if c.Alertmanager == nil || c.Alertmanager.URL == "" {
+				return fmt.Errorf("bad config in receiver %q, 'silence' requires 'alertmanager.url'", rc.Name)
+			}
+			// Without credentials, anyone reaching the service hook endpoint could create and expire silences.
+			if c.ServiceHook == nil || c.ServiceHook.Username == "" {
+				return fmt.Errorf("bad config in receiver %q, 'silence' requires 'service_hook' username and password", rc.Name)
+			}
+		}
+		// Reconciling compares work items with the alerts of the Alertmanager receiver of the same name, too.
+		if rc.Reconcile != nil && indirectReceivers[rc.Name] {
+			return fmt.Errorf("bad config in receiver %q, 'reconcile' cannot be used with receivers notified through 'route', 'inputs' or 'cloudevents'", rc.Name)
+		}
+		if rc.Reconcile == nil && !indirectReceivers[rc.Name] {
+			rc.Reconcile = c.Defaults.Reconcile
+		}
+		if rc.Reconcile != nil {
+			if rc.Reconcile.Interval != nil && *rc.Reconcile.Interval <= 0 {
+				return fmt.Errorf("bad config in receiver %q, 'reconcile' interval must be positive", rc.Name)
+			}
+			if rc.AutoResolve == nil {
+				return fmt.Errorf("bad config in receiver %q, 'reconcile' requires 'auto_resolve'", rc.Name)
+			}
+			if c.Alertmanager == nil || c.Alertmanager.URL == "" {
+				return fmt.Errorf("bad config in receiver %q, 'reconcile' requires 'alertmanager.url'", rc.Name)
+			}
+		}
+		if rc.Include == nil {
+			rc.Include = c.Defaults.Include
+		}
+		if rc.Exclude == nil {
+			rc.Exclude = c.Defaults.Exclude
+		}
+		if rc.RelabelConfigs == nil {
+			rc.RelabelConfigs = c.Defaults.RelabelConfigs
+		}
+		// Relabeled alerts get fingerprints unknown to Alertmanager, which silences and reconciliation look up.
+		if len(rc.RelabelConfigs) > 0 && (rc.Silence != nil || rc.Reconcile != nil) {
+			return fmt.Errorf("bad config in receiver %q, 'relabel_configs' cannot be used with 'silence' or 'reconcile'", rc.Name)
+		}
+		if rc.Targets == nil {
+			rc.Targets = c.Defaults.Targets
+		}
+		if err := rc.validateTargets(); err != nil {
+			return fmt.Errorf("bad config in receiver %q, %s", rc.Name, err)
+		}
+		for _, conf := range rc.TargetConfigs() {
+			if conf.Credentials != "" && !isTemplated(conf.Credentials) && c.Credentials[conf.Credentials] == nil {
+				return fmt.Errorf("bad config in receiver %q, unknown credentials %q", rc.Name, conf.Credentials)
+			}
+			// Background tasks and service hooks access Azure DevOps without a notification to render templates with.
+			if conf.TemplatedAccess() &&
+				(rc.Silence != nil || rc.Reconcile != nil || (rc.Escalation != nil && rc.Escalation.SLA != nil)) {
+				return fmt.Errorf("bad config in receiver %q, templated 'organization' and 'credentials' cannot be used with 'silence', 'reconcile' or 'escalation.sla'", rc.Name)
+			}
+		}
 		if len(c.Defaults.Fields) > 0 {
 			if rc.Fields == nil {
 				rc.Fields = make(map[string]interface{})
@@ -397,13 +1110,335 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		return fmt.Errorf("no receivers defined")
 	}
 
-	if c.Template == "" {
+	if len(c.Template) == 0 {
 		return fmt.Errorf("missing template file")
 	}
 
+	if c.Route != nil {
+		if len(c.Route.Matchers) > 0 {
+			return fmt.Errorf("bad config in route section: the root route must not have matchers")
+		}
+		if err := c.Route.validate(c); err != nil {
+			return fmt.Errorf("bad config in route section: %s", err)
+		}
+	}
+	if c.Alertmanager != nil {
+		if c.Alertmanager.URL == "" {
+			return fmt.Errorf("bad config in alertmanager section: missing url")
+		}
+		if _, err := url.Parse(c.Alertmanager.URL); err != nil {
+			return fmt.Errorf("bad config in alertmanager section: invalid url %q: %s", c.Alertmanager.URL, err)
+		}
+		if err := checkOverflow(c.Alertmanager.XXX, "alertmanager"); err != nil {
+			return err
+		}
+	}
+	if c.Polling != nil {
+		if c.Alertmanager == nil {
+			return fmt.Errorf("bad config in polling section: requires 'alertmanager.url'")
+		}
+		if c.Polling.Interval != nil && *c.Polling.Interval <= 0 {
+			return fmt.Errorf("bad config in polling section: interval must be positive")
+		}
+		for _, name := range c.Polling.Receivers {
+			if c.ReceiverByName(name) == nil {
+				return fmt.Errorf("bad config in polling section: unknown receiver %q", name)
+			}
+		}
+		for _, rc := range c.Receivers {
+			if len(rc.RelabelConfigs) > 0 && (len(c.Polling.Receivers) == 0 || slices.Contains(c.Polling.Receivers, rc.Name)) {
+				return fmt.Errorf("bad config in polling section: receiver %q with 'relabel_configs' cannot be polled", rc.Name)
+			}
+		}
+		if err := checkOverflow(c.Polling.XXX, "polling"); err != nil {
+			return err
+		}
+	}
+	if c.ServiceHook != nil {
+		if (c.ServiceHook.Username == "") != (c.ServiceHook.Password == "") {
+			return fmt.Errorf("bad config in service_hook section: username and password must be set together")
+		}
+		if err := checkOverflow(c.ServiceHook.XXX, "service_hook"); err != nil {
+			return err
+		}
+	}
+	if c.CloudEvents != nil {
+		if c.CloudEvents.ReceiverExtension == "" {
+			c.CloudEvents.ReceiverExtension = defaultReceiverExtension
+		}
+		if !extensionNameRE.MatchString(c.CloudEvents.ReceiverExtension) {
+			return fmt.Errorf("bad config in cloudevents section: invalid receiver_extension %q, must be 1 to 20 lowercase letters or digits", c.CloudEvents.ReceiverExtension)
+		}
+		if c.CloudEvents.DefaultReceiver != "" && c.ReceiverByName(c.CloudEvents.DefaultReceiver) == nil {
+			return fmt.Errorf("bad config in cloudevents section: unknown default_receiver %q", c.CloudEvents.DefaultReceiver)
+		}
+		resolvedTypes := map[string]bool{}
+		for _, t := range c.CloudEvents.ResolvedTypes {
+			resolvedTypes[t] = true
+		}
+		for _, t := range c.CloudEvents.FiringTypes {
+			if resolvedTypes[t] {
+				return fmt.Errorf("bad config in cloudevents section: type %q is both firing and resolved", t)
+			}
+		}
+		if err := checkOverflow(c.CloudEvents.XXX, "cloudevents"); err != nil {
+			return err
+		}
+	}
+	inputNames := map[string]struct{}{}
+	for _, in := range c.Inputs {
+		if in.Name == "" {
+			return fmt.Errorf("missing name for input %+v", in)
+		}
+		if _, ok := inputNames[in.Name]; ok {
+			return fmt.Errorf("duplicate input name %q", in.Name)
+		}
+		inputNames[in.Name] = struct{}{}
+		if err := in.validate(c); err != nil {
+			return fmt.Errorf("bad config in input %q, %s", in.Name, err)
+		}
+	}
+
 	return checkOverflow(c.XXX, "config")
 }
 
+func (in *InputConfig) validate(c *Config) error {
+	if strings.ContainsAny(in.Name, "/?#") {
+		return fmt.Errorf("name must not contain '/', '?' or '#'")
+	}
+	if in.Receiver == "" {
+		return fmt.Errorf("missing 'receiver' field")
+	}
+	if c.ReceiverByName(in.Receiver) == nil {
+		return fmt.Errorf("unknown receiver %q", in.Receiver)
+	}
+	if in.Alerts != "" && !strings.HasPrefix(in.Alerts, "$") {
+		return fmt.Errorf("'alerts' must be a JSONPath expression")
+	}
+	if len(in.Labels) == 0 {
+		return fmt.Errorf("missing 'labels' field")
+	}
+	return checkOverflow(in.XXX, "input")
+}
+
+func (p *Parent) validate(rc *ReceiverConfig) error {
+	if p.Key == "" {
+		return fmt.Errorf("'parent' was defined with empty 'key' field")
+	}
+	if p.IssueType == "" {
+		return fmt.Errorf("'parent' was defined with empty 'issue_type' field")
+	}
+	if p.AutoClose != nil {
+		if p.AutoClose.State == "" {
+			return fmt.Errorf("'parent.auto_close' was defined with empty 'state' field")
+		}
+		// Parents are only closed when the receiver auto-resolves one of their children.
+		if rc.AutoResolve == nil {
+			return fmt.Errorf("'parent.auto_close' requires 'auto_resolve'")
+		}
+	}
+	return nil
+}
+
+func (e *Escalation) validate() error {
+	if len(e.Priorities) > 0 && e.Label == "" {
+		return fmt.Errorf("'escalation' was defined with 'priorities' but empty 'label' field")
+	}
+	for value, priority := range e.Priorities {
+		if priority < 1 {
+			return fmt.Errorf("'escalation.priorities' maps %q to %d, priorities start at 1", value, priority)
+		}
+	}
+	if e.SLA != nil {
+		if e.SLA.After == nil || *e.SLA.After <= 0 {
+			return fmt.Errorf("'escalation.sla' was defined without a positive 'after' field")
+		}
+		if e.SLA.Priority < 0 {
+			return fmt.Errorf("'escalation.sla' priority cannot be negative")
+		}
+		if e.SLA.Priority == 0 && e.SLA.AssignTo == "" {
+			return fmt.Errorf("'escalation.sla' requires 'priority' or 'assign_to'")
+		}
+		if e.SLA.Interval != nil && *e.SLA.Interval <= 0 {
+			return fmt.Errorf("'escalation.sla' interval must be positive")
+		}
+	}
+	return nil
+}
+
+func (rc *ReceiverConfig) validateTargets() error {
+	names := map[string]struct{}{}
+	for _, t := range rc.Targets {
+		if t.Name == "" {
+			return fmt.Errorf("'targets' entry defined with empty 'name' field")
+		}
+		if _, ok := names[t.Name]; ok {
+			return fmt.Errorf("duplicate target name %q", t.Name)
+		}
+		names[t.Name] = struct{}{}
+		if t.Organization != "" && !isTemplated(t.Organization) {
+			if _, err := url.Parse(t.Organization); err != nil {
+				return fmt.Errorf("invalid organization %q in target %q: %s", t.Organization, t.Name, err)
+			}
+		}
+		inline := t.hasInlineCredentials()
+		if t.Credentials != "" {
+			if inline || t.Auth != nil {
+				return fmt.Errorf("bad auth config in target %q: 'credentials', 'auth' and inline credentials are mutually exclusive", t.Name)
+			}
+			continue
+		}
+		if t.Auth != nil {
+			if inline {
+				return fmt.Errorf("bad auth config in target %q: 'credentials', 'auth' and inline credentials are mutually exclusive", t.Name)
+			}
+			continue
+		}
+		if !t.hasCredentials() {
+			continue
+		}
+		if err := checkSecrets(t.ClientSecret, t.ClientSecretFile, t.PersonalAccessToken, t.PersonalAccessTokenFile); err != nil {
+			return fmt.Errorf("bad auth config in target %q: %s", t.Name, err)
+		}
+		if err := checkAuth(t.TenantID, t.ClientID, t.SubscriptionID, secretSet(t.ClientSecret, t.ClientSecretFile), secretSet(t.PersonalAccessToken, t.PersonalAccessTokenFile)); err != nil {
+			return fmt.Errorf("bad auth config in target %q: %s", t.Name, err)
+		}
+	}
+
+	// Targets sharing a project would find each other's work items.
+	projects := map[string]string{}
+	for _, conf := range rc.TargetConfigs() {
+		key := strings.ToLower(conf.Organization + "/" + conf.Project)
+		if other, ok := projects[key]; ok {
+			return fmt.Errorf("targets %q and %q share organization %q and project %q", other, conf.Target, conf.Organization, conf.Project)
+		}
+		projects[key] = conf.Target
+	}
+	return nil
+}
+
+// hasInlineCredentials reports whether any credential field or auth profile is set on the receiver itself.
+func (rc *ReceiverConfig) hasInlineCredentials() bool {
+	return rc.TenantID != "" || rc.ClientID != "" || rc.SubscriptionID != "" || secretSet(rc.ClientSecret, rc.ClientSecretFile) || secretSet(rc.PersonalAccessToken, rc.PersonalAccessTokenFile) || rc.Auth != nil
+}
+
+// Access returns the configuration of the receiver for accessing Azure DevOps on a notification: a copy with the
+// organization rendered and the named credentials applied, or the configuration itself when there is neither a
+// templated organization nor named credentials. render renders templates with the notification.
+func (rc *ReceiverConfig) Access(render func(text string) (string, error)) (*ReceiverConfig, error) {
+	if !isTemplated(rc.Organization) && rc.Credentials == "" {
+		return rc, nil
+	}
+	conf := *rc
+	if isTemplated(rc.Organization) {
+		organization, err := render(rc.Organization)
+		if err != nil {
+			return nil, fmt.Errorf("render organization: %w", err)
+		}
+		if organization == "" || strings.ContainsAny(organization, "/?#") {
+			return nil, fmt.Errorf("invalid organization %q rendered", organization)
+		}
+		conf.Organization = organization
+	}
+	if rc.Credentials != "" {
+		name, err := render(rc.Credentials)
+		if err != nil {
+			return nil, fmt.Errorf("render credentials: %w", err)
+		}
+		creds, ok := rc.credentials[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown credentials %q", name)
+		}
+		conf.Credentials = ""
+		conf.TenantID = creds.TenantID
+		conf.ClientID = creds.ClientID
+		conf.SubscriptionID = creds.SubscriptionID
+		conf.ClientSecret = creds.ClientSecret
+		conf.PersonalAccessToken = creds.PersonalAccessToken
+		conf.ClientSecretFile = creds.ClientSecretFile
+		conf.PersonalAccessTokenFile = creds.PersonalAccessTokenFile
+		conf.Auth = creds.Auth
+	}
+	return &conf, nil
+}
+
+// TemplatedAccess reports whether the organization or the credentials name of the receiver are templated, so that it
+// can only access Azure DevOps on a notification.
+func (rc *ReceiverConfig) TemplatedAccess() bool {
+	return isTemplated(rc.Organization) || isTemplated(rc.Credentials)
+}
+
+// TemplatedProject reports whether the project of the receiver is templated, so that it is only known on a
+// notification.
+func (rc *ReceiverConfig) TemplatedProject() bool {
+	return isTemplated(rc.Project)
+}
+
+// TemplatedIssueType reports whether the work item type of the receiver is templated, so that it is only known on a
+// notification.
+func (rc *ReceiverConfig) TemplatedIssueType() bool {
+	return isTemplated(rc.IssueType)
+}
+
+// TargetConfigs returns the configurations of the targets of the receiver: copies of the receiver with the fields of
+// each target applied. A receiver without targets is its own single target.
+func (rc *ReceiverConfig) TargetConfigs() []*ReceiverConfig {
+	if len(rc.Targets) == 0 {
+		return []*ReceiverConfig{rc}
+	}
+	confs := make([]*ReceiverConfig, 0, len(rc.Targets))
+	for _, t := range rc.Targets {
+		conf := *rc
+		conf.Targets = nil
+		conf.Target = t.Name
+		if t.Organization != "" {
+			conf.Organization = t.Organization
+		}
+		if t.hasCredentials() {
+			conf.TenantID = t.TenantID
+			conf.ClientID = t.ClientID
+			conf.SubscriptionID = t.SubscriptionID
+			conf.ClientSecret = t.ClientSecret
+			conf.PersonalAccessToken = t.PersonalAccessToken
+			conf.ClientSecretFile = t.ClientSecretFile
+			conf.PersonalAccessTokenFile = t.PersonalAccessTokenFile
+			conf.Credentials = t.Credentials
+			conf.Auth = t.Auth
+		}
+		if t.Project != "" {
+			conf.Project = t.Project
+		}
+		if t.IssueType != "" {
+			conf.IssueType = t.IssueType
+		}
+		if len(t.Fields) > 0 {
+			conf.Fields = make(map[string]interface{}, len(rc.Fields)+len(t.Fields))
+			for key, value := range rc.Fields {
+				conf.Fields[key] = value
+			}
+			for key, value := range t.Fields {
+				conf.Fields[key] = value
+			}
+		}
+		confs = append(confs, &conf)
+	}
+	return confs
+}
+
+// TargetByProject returns the configuration of the target creating work items in the project, or nil when there is
+// none. A receiver without targets is returned as is.
+func (rc *ReceiverConfig) TargetByProject(project string) *ReceiverConfig {
+	if len(rc.Targets) == 0 {
+		return rc
+	}
+	for _, conf := range rc.TargetConfigs() {
+		if strings.EqualFold(conf.Project, project) {
+			return conf
+		}
+	}
+	return nil
+}
+
 // ReceiverByName loops the receiver list and returns the first instance with that name
 func (c *Config) ReceiverByName(name string) *ReceiverConfig {
 	for _, rc := range c.Receivers {
@@ -414,6 +1449,26 @@ func (c *Config) ReceiverByName(name string) *ReceiverConfig {
 	return nil
 }
 
+// isTemplated reports whether the value is a template rather than a literal, as told by template.Execute.
+func isTemplated(s string) bool {
+	return strings.Contains(s, "{{")
+}
+
+// checkAuth checks that the credential fields make up exactly one authentication method, given whether the client
+// secret and the personal access token are set.
+func checkAuth(tenantID, clientID, subscriptionID string, clientSecret, personalAccessToken bool) error {
+	servicePrincipal := tenantID != "" && clientID != "" && clientSecret
+	managedIdentity := clientID != "" && subscriptionID != "" && !servicePrincipal
+	pat := personalAccessToken
+	switch {
+	case (servicePrincipal || managedIdentity) && pat:
+		return fmt.Errorf("Service Principal (TenantID+ClientID+ClientSecret), Managed Identity (ClientID+SubscriptionID), and PAT authentication are mutually exclusive")
+	case !servicePrincipal && !managedIdentity && !pat:
+		return fmt.Errorf("incomplete authentication")
+	}
+	return nil
+}
+
 func checkOverflow(m map[string]interface{}, ctx string) error {
 	if len(m) > 0 {
 		var keys []string
@@ -15,9 +15,11 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -92,7 +94,7 @@ func TestLoadFile(t *testing.T) {
 
 	require.NoError(t, os.WriteFile(path.Join(dir, "config.yaml"), []byte(testConf), os.ModePerm))
 
-	_, content, err := LoadFile(path.Join(dir, "config.yaml"), log.NewNopLogger())
+	_, content, err := LoadFile(path.Join(dir, "config.yaml"), false, log.NewNopLogger())
 
 	require.NoError(t, err)
 	require.Equal(t, testConf, string(content))
@@ -104,18 +106,38 @@ func TestEnvSubstitution(t *testing.T) {
 	require.NoError(t, err)
 	config := "user: $(JA_USER)"
 
-	content, err := substituteEnvVars([]byte(config), log.NewNopLogger())
+	content, err := substituteEnvVars([]byte(config), false, log.NewNopLogger())
 	expected := "user: user"
 	require.NoError(t, err)
 	require.Equal(t, string(content), expected)
 
 	config = "user: $(JA_MISSING)"
-	content, err = substituteEnvVars([]byte(config), log.NewNopLogger())
+	content, err = substituteEnvVars([]byte(config), false, log.NewNopLogger())
 	expected = "user: " // Missing env var results in empty string, not error
 	require.NoError(t, err)
 	require.Equal(t, string(content), expected)
 }
 
+func TestEnvSubstitution_Defaults(t *testing.T) {
+	t.Setenv("JA_USER", "user")
+	t.Setenv("JA_EMPTY", "")
+
+	content, err := substituteEnvVars([]byte("a: ${JA_USER}, b: ${JA_USER:-other}, c: ${JA_EMPTY:-fallback}, d: ${JA_MISSING:-}, e: ${JA_MISSING:-x y}"), true, log.NewNopLogger())
+	require.NoError(t, err)
+	require.Equal(t, "a: user, b: user, c: fallback, d: , e: x y", string(content))
+}
+
+func TestEnvSubstitution_Strict(t *testing.T) {
+	t.Setenv("JA_EMPTY", "")
+
+	content, err := substituteEnvVars([]byte("a: $(JA_MISSING), b: ${JA_OTHER_MISSING}, c: $(JA_MISSING), d: ${JA_EMPTY}"), false, log.NewNopLogger())
+	require.NoError(t, err)
+	require.Equal(t, "a: , b: , c: , d: ", string(content))
+
+	_, err = substituteEnvVars([]byte("a: $(JA_MISSING), b: ${JA_OTHER_MISSING}, c: $(JA_MISSING), d: ${JA_EMPTY}"), true, log.NewNopLogger())
+	require.EqualError(t, err, "missing environment variables: JA_MISSING, JA_OTHER_MISSING")
+}
+
 // A test version of the ReceiverConfig struct to create test yaml fixtures.
 type receiverTestConfig struct {
 	Name                string `yaml:"name,omitempty"`
@@ -783,7 +805,7 @@ template: alert-az-do.tmpl
 `
 
 	// Use substituteEnvVars first, then Load
-	substitutedContent, err := substituteEnvVars([]byte(configYAML), log.NewNopLogger())
+	substitutedContent, err := substituteEnvVars([]byte(configYAML), false, log.NewNopLogger())
 	require.NoError(t, err)
 
 	cfg, err := Load(string(substitutedContent))
@@ -934,7 +956,7 @@ func TestConfig_String(t *testing.T) {
 				// Inherits PAT from defaults
 			},
 		},
-		Template: "test.tmpl",
+		Template: Templates{"test.tmpl"},
 	}
 
 	result := cfg.String()
@@ -1229,3 +1251,1108 @@ template: test.tmpl
 	require.Equal(t, "test-org", receiver.Organization)
 	require.Equal(t, Secret("test-token"), receiver.PersonalAccessToken)
 }
+
+func TestConfig_UnmarshalYAML_Links(t *testing.T) {
+	tests := []struct {
+		name       string
+		configYAML string
+		expectErr  bool
+		errMsg     string
+		checkFunc  func(t *testing.T, cfg *Config)
+	}{
+		{
+			name: "links inherited from defaults",
+			configYAML: `
+defaults:
+  organization: test-org
+  personal_access_token: test-token
+  project: test-project
+  issue_type: Bug
+  summary: Test Summary
+  reopen_state: Active
+  reopen_duration: 5m
+  links:
+    - url: '{{ .ExternalURL }}'
+      comment: Alertmanager
+
+receivers:
+  - name: receiver-inherit
+  - name: receiver-own
+    links:
+      - url: '{{ .Annotations.runbook_url }}'
+        per_alert: true
+
+template: test.tmpl
+`,
+			checkFunc: func(t *testing.T, cfg *Config) {
+				inherit := cfg.ReceiverByName("receiver-inherit")
+				require.Len(t, inherit.Links, 1)
+				require.Equal(t, "{{ .ExternalURL }}", inherit.Links[0].URL)
+				require.Equal(t, "Alertmanager", inherit.Links[0].Comment)
+				require.False(t, inherit.Links[0].PerAlert)
+
+				own := cfg.ReceiverByName("receiver-own")
+				require.Len(t, own.Links, 1)
+				require.True(t, own.Links[0].PerAlert)
+			},
+		},
+		{
+			name: "empty url in defaults",
+			configYAML: `
+defaults:
+  organization: test-org
+  personal_access_token: test-token
+  project: test-project
+  issue_type: Bug
+  summary: Test Summary
+  reopen_state: Active
+  reopen_duration: 5m
+  links:
+    - comment: Alertmanager
+
+receivers:
+  - name: test-receiver
+
+template: test.tmpl
+`,
+			expectErr: true,
+			errMsg:    "bad config in defaults section: 'links' entry defined with empty 'url' field",
+		},
+		{
+			name: "empty url in receiver",
+			configYAML: `
+defaults:
+  organization: test-org
+  personal_access_token: test-token
+  project: test-project
+  issue_type: Bug
+  summary: Test Summary
+  reopen_state: Active
+  reopen_duration: 5m
+
+receivers:
+  - name: test-receiver
+    links:
+      - comment: Runbook
+
+template: test.tmpl
+`,
+			expectErr: true,
+			errMsg:    `bad config in receiver "test-receiver", 'links' entry defined with empty 'url' field`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Config
+			err := yaml.Unmarshal([]byte(tt.configYAML), &cfg)
+			if tt.expectErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			tt.checkFunc(t, &cfg)
+		})
+	}
+}
+
+func TestConfig_UnmarshalYAML_AttachPayload(t *testing.T) {
+	configYAML := `
+defaults:
+  organization: test-org
+  personal_access_token: test-token
+  project: test-project
+  issue_type: Bug
+  summary: Test Summary
+  reopen_state: Active
+  reopen_duration: 5m
+  attach_payload:
+    max_attachments: 5
+
+receivers:
+  - name: receiver-inherit
+  - name: receiver-own
+    attach_payload:
+      file_name: payload.json
+%s
+template: test.tmpl
+`
+
+	var cfg Config
+	require.NoError(t, yaml.Unmarshal([]byte(fmt.Sprintf(configYAML, "")), &cfg))
+	require.Equal(t, 5, cfg.ReceiverByName("receiver-inherit").AttachPayload.MaxAttachments)
+	require.Equal(t, "payload.json", cfg.ReceiverByName("receiver-own").AttachPayload.FileName)
+	require.Equal(t, 0, cfg.ReceiverByName("receiver-own").AttachPayload.MaxAttachments)
+
+	err := yaml.Unmarshal([]byte(fmt.Sprintf(configYAML, "      max_attachments: -1\n")), &cfg)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `bad config in receiver "receiver-own", 'attach_payload' max_attachments cannot be negative`)
+}
+
+func TestConfig_UnmarshalYAML_Parent(t *testing.T) {
+	configYAML := `
+defaults:
+  organization: test-org
+  personal_access_token: test-token
+  project: test-project
+  issue_type: Bug
+  summary: Test Summary
+  reopen_state: Active
+  reopen_duration: 5m
+
+receivers:
+  - name: test-receiver
+    parent:
+%s
+template: test.tmpl
+`
+	for _, test := range []struct {
+		parent string
+		errMsg string
+	}{
+		{"      key: '{{ .GroupLabels.alertname }}'\n      issue_type: Feature\n", ""},
+		{"      issue_type: Feature\n", "'parent' was defined with empty 'key' field"},
+		{"      key: '{{ .GroupLabels.alertname }}'\n", "'parent' was defined with empty 'issue_type' field"},
+		{"      key: k\n      issue_type: Feature\n      auto_close: {state: ''}\n", "'parent.auto_close' was defined with empty 'state' field"},
+		{"      key: k\n      issue_type: Feature\n      auto_close: {state: Done}\n", "'parent.auto_close' requires 'auto_resolve'"},
+		{"      key: k\n      issue_type: Feature\n      auto_close: {state: Done}\n      closed_states: [Closed]\n", "'parent.auto_close' requires 'auto_resolve'"},
+		{"      key: k\n      issue_type: Feature\n      auto_close: {state: Done}\n      closed_states: [Closed]\n    auto_resolve: {state: Closed}\n", ""},
+	} {
+		var cfg Config
+		err := yaml.Unmarshal([]byte(fmt.Sprintf(configYAML, test.parent)), &cfg)
+		if test.errMsg == "" {
+			require.NoError(t, err)
+			require.NotNil(t, cfg.ReceiverByName("test-receiver").Parent)
+			continue
+		}
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `bad config in receiver "test-receiver", `+test.errMsg)
+	}
+}
+
+func TestConfig_UnmarshalYAML_Correlations(t *testing.T) {
+	configYAML := `
+defaults:
+  organization: test-org
+  personal_access_token: test-token
+  project: test-project
+  issue_type: Bug
+  summary: Test Summary
+  reopen_state: Active
+  reopen_duration: 5m
+  correlations:
+    - labels: [cluster]
+      window: 5m
+
+receivers:
+  - name: inherited
+  - name: test-receiver
+    correlations:
+%s
+template: test.tmpl
+`
+	for _, test := range []struct {
+		correlations string
+		errMsg       string
+	}{
+		{"      - labels: [cluster, namespace]\n        window: 10m\n        comment: 'see {{ .RelatedIDs }}'\n", ""},
+		{"      - window: 10m\n", "'correlations' entry defined without 'labels'"},
+		{"      - labels: [cluster]\n", "'correlations' entry defined without a positive 'window'"},
+		{"      - labels: [cluster]\n        window: 0s\n", "'correlations' entry defined without a positive 'window'"},
+	} {
+		var cfg Config
+		err := yaml.Unmarshal([]byte(fmt.Sprintf(configYAML, test.correlations)), &cfg)
+		if test.errMsg == "" {
+			require.NoError(t, err)
+			require.Len(t, cfg.ReceiverByName("test-receiver").Correlations, 1)
+			require.Equal(t, 10*time.Minute, *cfg.ReceiverByName("test-receiver").Correlations[0].Window)
+			require.Equal(t, []string{"cluster"}, cfg.ReceiverByName("inherited").Correlations[0].Labels)
+			continue
+		}
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `bad config in receiver "test-receiver", `+test.errMsg)
+	}
+}
+
+func TestConfig_UnmarshalYAML_Escalation(t *testing.T) {
+	configYAML := `
+defaults:
+  organization: test-org
+  personal_access_token: test-token
+  project: test-project
+  issue_type: Bug
+  summary: Test Summary
+  reopen_state: Active
+  reopen_duration: 5m
+
+receivers:
+  - name: test-receiver
+    escalation:
+%s
+template: test.tmpl
+`
+	for _, test := range []struct {
+		escalation string
+		errMsg     string
+	}{
+		{"      label: severity\n      priorities: {critical: 1, warning: 2}\n", ""},
+		{"      label: severity\n      priorities: {critical: 1}\n      sla: {after: 1h, priority: 1, assign_to: oncall@contoso.com, interval: 1m}\n", ""},
+		{"      sla: {after: 30m, assign_to: oncall@contoso.com}\n", ""},
+		{"      priorities: {critical: 1}\n", "'escalation' was defined with 'priorities' but empty 'label' field"},
+		{"      label: severity\n      priorities: {critical: 0}\n", `'escalation.priorities' maps "critical" to 0, priorities start at 1`},
+		{"      sla: {priority: 1}\n", "'escalation.sla' was defined without a positive 'after' field"},
+		{"      sla: {after: 1h}\n", "'escalation.sla' requires 'priority' or 'assign_to'"},
+		{"      sla: {after: 1h, priority: -1}\n", "'escalation.sla' priority cannot be negative"},
+		{"      sla: {after: 1h, priority: 1, interval: 0s}\n", "'escalation.sla' interval must be positive"},
+	} {
+		var cfg Config
+		err := yaml.Unmarshal([]byte(fmt.Sprintf(configYAML, test.escalation)), &cfg)
+		if test.errMsg == "" {
+			require.NoError(t, err)
+			require.NotNil(t, cfg.ReceiverByName("test-receiver").Escalation)
+			continue
+		}
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `bad config in receiver "test-receiver", `+test.errMsg)
+	}
+}
+
+func TestConfig_UnmarshalYAML_Silence(t *testing.T) {
+	configYAML := `
+defaults:
+  organization: test-org
+  personal_access_token: test-token
+  project: test-project
+  issue_type: Bug
+  summary: Test Summary
+  reopen_state: Active
+  reopen_duration: 5m
+
+receivers:
+  - name: test-receiver
+    silence:
+%s
+template: test.tmpl
+%s
+`
+	const alertmanagerSection = "alertmanager:\n  url: http://alertmanager:9093\nservice_hook:\n  username: ado\n  password: secret\n"
+	for _, test := range []struct {
+		silence  string
+		toplevel string
+		errMsg   string
+	}{
+		{"      acknowledged_state: Active\n      duration: 4h\n", alertmanagerSection, ""},
+		{"      closed_states: [Closed]\n", alertmanagerSection, ""},
+		{"      acknowledged_state: Active\n", alertmanagerSection, `bad config in receiver "test-receiver", 'silence.acknowledged_state' requires 'silence.duration'`},
+		{"      duration: 0s\n", alertmanagerSection, `bad config in receiver "test-receiver", 'silence' duration must be positive`},
+		{"      duration: 1h\n", "", `bad config in receiver "test-receiver", 'silence' requires 'alertmanager.url'`},
+		{"      duration: 1h\n", "alertmanager:\n  url: ''\n", `bad config in receiver "test-receiver", 'silence' requires 'alertmanager.url'`},
+		{"      duration: 1h\n", "alertmanager:\n  url: http://alertmanager:9093\n", `bad config in receiver "test-receiver", 'silence' requires 'service_hook' username and password`},
+		{"      duration: 1h\n", "alertmanager:\n  url: http://alertmanager:9093\nservice_hook:\n  username: ado\n", "bad config in service_hook section: username and password must be set together"},
+		{"      duration: 1h\n", "alertmanager:\n  url: http://alertmanager:9093\n  timeout: 1s\nservice_hook:\n  username: ado\n  password: secret\n", "unknown fields in alertmanager: timeout"},
+		{"      duration: 1h\n", alertmanagerSection + "route:\n  receiver: test-receiver\n", `bad config in receiver "test-receiver", 'silence' cannot be used with receivers notified through 'route', 'inputs' or 'cloudevents'`},
+		{"      duration: 1h\n", alertmanagerSection + "inputs:\n  - {name: in, receiver: test-receiver, labels: {alertname: x}}\n", "'silence' cannot be used"},
+		{"      duration: 1h\n", alertmanagerSection + "cloudevents:\n  default_receiver: test-receiver\n", "'silence' cannot be used"},
+	} {
+		var cfg Config
+		err := yaml.Unmarshal([]byte(fmt.Sprintf(configYAML, test.silence, test.toplevel)), &cfg)
+		if test.errMsg == "" {
+			require.NoError(t, err)
+			require.NotNil(t, cfg.ReceiverByName("test-receiver").Silence)
+			require.Equal(t, "http://alertmanager:9093", cfg.Alertmanager.URL)
+			continue
+		}
+		require.Error(t, err)
+		require.Contains(t, err.Error(), test.errMsg)
+	}
+
+	// Receivers notified through routes do not inherit the default silence.
+	var cfg Config
+	require.NoError(t, yaml.Unmarshal([]byte(strings.Replace(fmt.Sprintf(configYAML, "", alertmanagerSection+"route:\n  receiver: test-receiver\n"),
+		"  reopen_duration: 5m\n", "  reopen_duration: 5m\n  silence: {duration: 1h}\n", 1)), &cfg))
+	require.Nil(t, cfg.ReceiverByName("test-receiver").Silence)
+}
+
+func TestConfig_UnmarshalYAML_Reconcile(t *testing.T) {
+	configYAML := `
+defaults:
+  organization: test-org
+  personal_access_token: test-token
+  project: test-project
+  issue_type: Bug
+  summary: Test Summary
+  reopen_state: Active
+  reopen_duration: 5m
+  reconcile:
+    interval: 15m
+
+receivers:
+  - name: test-receiver
+%s
+template: test.tmpl
+%s
+`
+	const alertmanagerSection = "alertmanager:\n  url: http://alertmanager:9093\n"
+	for _, test := range []struct {
+		receiver string
+		toplevel string
+		errMsg   string
+	}{
+		{"    auto_resolve: {state: Closed}\n", alertmanagerSection, ""},
+		{"    auto_resolve: {state: Closed}\n    reconcile: {interval: 0s}\n", alertmanagerSection, "'reconcile' interval must be positive"},
+		{"    project: other\n", alertmanagerSection, "'reconcile' requires 'auto_resolve'"},
+		{"    auto_resolve: {state: Closed}\n", "", "'reconcile' requires 'alertmanager.url'"},
+		{"    auto_resolve: {state: Closed}\n    reconcile: {}\n", alertmanagerSection + "route:\n  receiver: test-receiver\n", "'reconcile' cannot be used with receivers notified through 'route', 'inputs' or 'cloudevents'"},
+		{"    auto_resolve: {state: Closed}\n    reconcile: {}\n", alertmanagerSection + "inputs:\n  - {name: in, receiver: test-receiver, labels: {alertname: x}}\n", "'reconcile' cannot be used"},
+		{"    auto_resolve: {state: Closed}\n    reconcile: {}\n", alertmanagerSection + "cloudevents:\n  default_receiver: test-receiver\n", "'reconcile' cannot be used"},
+	} {
+		var cfg Config
+		err := yaml.Unmarshal([]byte(fmt.Sprintf(configYAML, test.receiver, test.toplevel)), &cfg)
+		if test.errMsg == "" {
+			require.NoError(t, err)
+			require.Equal(t, 15*time.Minute, *cfg.ReceiverByName("test-receiver").Reconcile.Interval)
+			continue
+		}
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `bad config in receiver "test-receiver", `+test.errMsg)
+	}
+
+	// Receivers notified through routes do not inherit the default reconcile.
+	var cfg Config
+	require.NoError(t, yaml.Unmarshal([]byte(fmt.Sprintf(configYAML, "    auto_resolve: {state: Closed}\n", alertmanagerSection+"route:\n  receiver: test-receiver\n")), &cfg))
+	require.Nil(t, cfg.ReceiverByName("test-receiver").Reconcile)
+}
+
+func TestConfig_UnmarshalYAML_Polling(t *testing.T) {
+	configYAML := `
+defaults:
+  organization: test-org
+  personal_access_token: test-token
+  project: test-project
+  issue_type: Bug
+  summary: Test Summary
+  reopen_state: Active
+  reopen_duration: 5m
+
+receivers:
+  - name: test-receiver
+template: test.tmpl
+%s
+`
+	const alertmanagerSection = "alertmanager:\n  url: http://alertmanager:9093\n"
+	for _, test := range []struct {
+		toplevel string
+		errMsg   string
+	}{
+		{alertmanagerSection + "polling:\n  interval: 30s\n  receivers: [test-receiver]\n", ""},
+		{"polling:\n  interval: 30s\n", "bad config in polling section: requires 'alertmanager.url'"},
+		{alertmanagerSection + "polling:\n  interval: 0s\n", "bad config in polling section: interval must be positive"},
+		{alertmanagerSection + "polling:\n  receivers: [unknown]\n", `bad config in polling section: unknown receiver "unknown"`},
+		{alertmanagerSection + "polling:\n  filter: x\n", "unknown fields in polling: filter"},
+	} {
+		var cfg Config
+		err := yaml.Unmarshal([]byte(fmt.Sprintf(configYAML, test.toplevel)), &cfg)
+		if test.errMsg == "" {
+			require.NoError(t, err)
+			require.Equal(t, 30*time.Second, *cfg.Polling.Interval)
+			continue
+		}
+		require.Error(t, err)
+		require.Contains(t, err.Error(), test.errMsg)
+	}
+}
+
+func TestConfig_UnmarshalYAML_Inputs(t *testing.T) {
+	configYAML := `
+defaults:
+  organization: test-org
+  personal_access_token: test-token
+  project: test-project
+  issue_type: Bug
+  summary: Test Summary
+  reopen_state: Active
+  reopen_duration: 5m
+
+receivers:
+  - name: test-receiver
+template: test.tmpl
+inputs:
+%s
+`
+	for _, test := range []struct {
+		inputs string
+		errMsg string
+	}{
+		{"  - name: ci\n    receiver: test-receiver\n    alerts: $.jobs\n    labels:\n      alertname: $.name\n", ""},
+		{"  - receiver: test-receiver\n    labels:\n      alertname: $.name\n", "missing name for input"},
+		{"  - name: ci\n    receiver: test-receiver\n    labels:\n      alertname: $.name\n  - name: ci\n    receiver: test-receiver\n    labels:\n      alertname: $.name\n", `duplicate input name "ci"`},
+		{"  - name: ci/jobs\n    receiver: test-receiver\n    labels:\n      alertname: $.name\n", `bad config in input "ci/jobs", name must not contain`},
+		{"  - name: ci\n    labels:\n      alertname: $.name\n", `bad config in input "ci", missing 'receiver' field`},
+		{"  - name: ci\n    receiver: unknown\n    labels:\n      alertname: $.name\n", `bad config in input "ci", unknown receiver "unknown"`},
+		{"  - name: ci\n    receiver: test-receiver\n    alerts: '{{ .jobs }}'\n    labels:\n      alertname: $.name\n", `bad config in input "ci", 'alerts' must be a JSONPath expression`},
+		{"  - name: ci\n    receiver: test-receiver\n", `bad config in input "ci", missing 'labels' field`},
+		{"  - name: ci\n    receiver: test-receiver\n    labels:\n      alertname: $.name\n    route: x\n", "unknown fields in input: route"},
+	} {
+		var cfg Config
+		err := yaml.Unmarshal([]byte(fmt.Sprintf(configYAML, test.inputs)), &cfg)
+		if test.errMsg == "" {
+			require.NoError(t, err)
+			require.Len(t, cfg.Inputs, 1)
+			require.Equal(t, "$.jobs", cfg.Inputs[0].Alerts)
+			require.Equal(t, map[string]string{"alertname": "$.name"}, cfg.Inputs[0].Labels)
+			continue
+		}
+		require.Error(t, err)
+		require.Contains(t, err.Error(), test.errMsg)
+	}
+}
+
+func TestConfig_UnmarshalYAML_CloudEvents(t *testing.T) {
+	configYAML := `
+defaults:
+  organization: test-org
+  personal_access_token: test-token
+  project: test-project
+  issue_type: Bug
+  summary: Test Summary
+  reopen_state: Active
+  reopen_duration: 5m
+
+receivers:
+  - name: test-receiver
+template: test.tmpl
+cloudevents:
+%s
+`
+	for _, test := range []struct {
+		section string
+		errMsg  string
+	}{
+		{"  default_receiver: test-receiver\n  resolved_types: [a.closed]\n", ""},
+		{"  receiver_extension: Team\n", `bad config in cloudevents section: invalid receiver_extension "Team"`},
+		{"  default_receiver: unknown\n", `bad config in cloudevents section: unknown default_receiver "unknown"`},
+		{"  firing_types: [a]\n  resolved_types: [a]\n", `bad config in cloudevents section: type "a" is both firing and resolved`},
+		{"  route: x\n", "unknown fields in cloudevents: route"},
+	} {
+		var cfg Config
+		err := yaml.Unmarshal([]byte(fmt.Sprintf(configYAML, test.section)), &cfg)
+		if test.errMsg == "" {
+			require.NoError(t, err)
+			require.Equal(t, "receiver", cfg.CloudEvents.ReceiverExtension)
+			require.Equal(t, "test-receiver", cfg.CloudEvents.DefaultReceiver)
+			continue
+		}
+		require.Error(t, err)
+		require.Contains(t, err.Error(), test.errMsg)
+	}
+}
+
+func TestConfig_UnmarshalYAML_Filters(t *testing.T) {
+	configYAML := `
+defaults:
+  organization: test-org
+  personal_access_token: test-token
+  project: test-project
+  issue_type: Bug
+  summary: Test Summary
+  reopen_state: Active
+  reopen_duration: 5m
+  exclude: ['severity="info"']
+  relabel_configs:
+    - action: labeldrop
+      regex: pod
+
+receivers:
+  - name: inherited
+  - name: overridden
+    include: ['team=~"db|storage"']
+    exclude: []
+    relabel_configs: []
+template: test.tmpl
+`
+	var cfg Config
+	require.NoError(t, yaml.Unmarshal([]byte(configYAML), &cfg))
+
+	inherited := cfg.ReceiverByName("inherited")
+	require.Empty(t, inherited.Include)
+	require.Len(t, inherited.Exclude, 1)
+	require.Equal(t, `severity="info"`, inherited.Exclude[0].String())
+	require.Len(t, inherited.RelabelConfigs, 1)
+	require.Equal(t, RelabelLabelDrop, inherited.RelabelConfigs[0].Action)
+
+	overridden := cfg.ReceiverByName("overridden")
+	require.Len(t, overridden.Include, 1)
+	require.True(t, overridden.Include.Matches(map[string]string{"team": "storage"}))
+	require.Empty(t, overridden.Exclude)
+	require.Empty(t, overridden.RelabelConfigs)
+
+	// Relabeled fingerprints are unknown to Alertmanager.
+	const alertmanagerSection = "alertmanager:\n  url: http://alertmanager:9093\n"
+	for _, test := range []struct {
+		receiver string
+		toplevel string
+		errMsg   string
+	}{
+		{"    auto_resolve: {state: Closed}\n    reconcile: {}\n", alertmanagerSection, `bad config in receiver "inherited", 'relabel_configs' cannot be used with 'silence' or 'reconcile'`},
+		{"", alertmanagerSection + "polling: {}\n", `bad config in polling section: receiver "inherited" with 'relabel_configs' cannot be polled`},
+		{"", alertmanagerSection + "polling:\n  receivers: [overridden]\n", ""},
+	} {
+		var cfg Config
+		err := yaml.Unmarshal([]byte(strings.Replace(configYAML, "  - name: overridden", test.receiver+"  - name: overridden", 1)+test.toplevel), &cfg)
+		if test.errMsg == "" {
+			require.NoError(t, err)
+			continue
+		}
+		require.EqualError(t, err, test.errMsg)
+	}
+}
+
+func TestConfig_UnmarshalYAML_Targets(t *testing.T) {
+	configYAML := `
+defaults:
+  organization: test-org
+  personal_access_token: test-token
+  project: test-project
+  issue_type: Bug
+  summary: Test Summary
+  reopen_state: Active
+  reopen_duration: 5m
+  fields:
+    System.AreaPath: test-project\Alerts
+
+receivers:
+  - name: security
+    targets:
+      - name: team
+      - name: secops
+        organization: secops-org
+        tenant_id: tenant
+        client_id: client
+        client_secret: secret
+        project: SecOps
+        issue_type: Incident
+        fields:
+          System.AreaPath: SecOps
+          Custom.Severity: '{{ .CommonLabels.severity }}'
+template: test.tmpl
+`
+	var cfg Config
+	require.NoError(t, yaml.Unmarshal([]byte(configYAML), &cfg))
+
+	rc := cfg.ReceiverByName("security")
+	targets := rc.TargetConfigs()
+	require.Len(t, targets, 2)
+
+	team := targets[0]
+	require.Equal(t, "team", team.Target)
+	require.Equal(t, "security", team.Name)
+	require.Equal(t, "test-org", team.Organization)
+	require.Equal(t, Secret("test-token"), team.PersonalAccessToken)
+	require.Equal(t, "test-project", team.Project)
+	require.Equal(t, "Bug", team.IssueType)
+	require.Empty(t, team.Targets)
+
+	secops := targets[1]
+	require.Equal(t, "secops", secops.Target)
+	require.Equal(t, "secops-org", secops.Organization)
+	require.Equal(t, "client", secops.ClientID)
+	require.Equal(t, Secret("secret"), secops.ClientSecret)
+	// Credentials are replaced as a whole.
+	require.Empty(t, secops.PersonalAccessToken)
+	require.Equal(t, "SecOps", secops.Project)
+	require.Equal(t, "Incident", secops.IssueType)
+	require.Equal(t, map[string]interface{}{"System.AreaPath": "SecOps", "Custom.Severity": "{{ .CommonLabels.severity }}"}, secops.Fields)
+	// The receiver's fields are left untouched.
+	require.Equal(t, map[string]interface{}{"System.AreaPath": `test-project\Alerts`}, rc.Fields)
+
+	require.Equal(t, "secops", rc.TargetByProject("secops").Target)
+	require.Nil(t, rc.TargetByProject("other"))
+
+	untargeted := &ReceiverConfig{Name: "plain"}
+	require.Equal(t, []*ReceiverConfig{untargeted}, untargeted.TargetConfigs())
+	require.Same(t, untargeted, untargeted.TargetByProject("other"))
+}
+
+func TestConfig_UnmarshalYAML_TargetsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		targets string
+		err     string
+	}{
+		{name: "missing name", targets: `[{project: other}]`, err: "'targets' entry defined with empty 'name' field"},
+		{name: "duplicate name", targets: `[{name: a, project: one}, {name: a, project: two}]`, err: `duplicate target name "a"`},
+		{name: "shared project", targets: `[{name: a}, {name: b, project: Test-Project}]`, err: `targets "a" and "b" share organization "test-org" and project "Test-Project"`},
+		{name: "incomplete auth", targets: `[{name: a, project: other, client_id: client}]`, err: `bad auth config in target "a": incomplete authentication`},
+		{name: "exclusive auth", targets: `[{name: a, project: other, client_id: c, subscription_id: s, personal_access_token: t}]`, err: `bad auth config in target "a"`},
+		{name: "unknown field", targets: `[{name: a, projects: other}]`, err: "unknown fields in target: projects"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configYAML := `
+defaults:
+  organization: test-org
+  personal_access_token: test-token
+  project: test-project
+  issue_type: Bug
+  summary: Test Summary
+  reopen_state: Active
+  reopen_duration: 5m
+receivers:
+  - name: security
+    targets: ` + tt.targets + `
+template: test.tmpl
+`
+			var cfg Config
+			require.ErrorContains(t, yaml.Unmarshal([]byte(configYAML), &cfg), tt.err)
+		})
+	}
+}
+
+func TestConfig_UnmarshalYAML_Credentials(t *testing.T) {
+	configYAML := `
+defaults:
+  organization: '{{ .CommonLabels.tenant }}'
+  credentials: '{{ .CommonLabels.tenant }}'
+  project: test-project
+  issue_type: Bug
+  summary: Test Summary
+  reopen_state: Active
+  reopen_duration: 5m
+
+credentials:
+  fabrikam:
+    personal_access_token: fabrikam-token
+  contoso:
+    tenant_id: tenant
+    client_id: client
+    client_secret: secret
+
+receivers:
+  - name: tenants
+  - name: contoso
+    organization: contoso
+    credentials: contoso
+template: test.tmpl
+`
+	var cfg Config
+	require.NoError(t, yaml.Unmarshal([]byte(configYAML), &cfg))
+
+	render := func(tenant string) func(string) (string, error) {
+		return func(text string) (string, error) {
+			return strings.ReplaceAll(text, "{{ .CommonLabels.tenant }}", tenant), nil
+		}
+	}
+
+	tenants := cfg.ReceiverByName("tenants")
+	require.Equal(t, "{{ .CommonLabels.tenant }}", tenants.Credentials)
+	conf, err := tenants.Access(render("fabrikam"))
+	require.NoError(t, err)
+	require.Equal(t, "fabrikam", conf.Organization)
+	require.Equal(t, Secret("fabrikam-token"), conf.PersonalAccessToken)
+	require.Empty(t, conf.Credentials)
+	// The receiver is left untouched.
+	require.Equal(t, "{{ .CommonLabels.tenant }}", tenants.Organization)
+
+	_, err = tenants.Access(render("unknown"))
+	require.EqualError(t, err, `unknown credentials "unknown"`)
+	_, err = tenants.Access(render(""))
+	require.EqualError(t, err, `invalid organization "" rendered`)
+
+	conf, err = cfg.ReceiverByName("contoso").Access(render("fabrikam"))
+	require.NoError(t, err)
+	require.Equal(t, "contoso", conf.Organization)
+	require.Equal(t, "client", conf.ClientID)
+	require.Equal(t, Secret("secret"), conf.ClientSecret)
+
+	plain := &ReceiverConfig{Organization: "contoso", PersonalAccessToken: "token"}
+	conf, err = plain.Access(render("fabrikam"))
+	require.NoError(t, err)
+	require.Same(t, plain, conf)
+}
+
+func TestConfig_UnmarshalYAML_CredentialsErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		receiver string
+		err      string
+	}{
+		{name: "unknown credentials", receiver: `{name: r, credentials: other}`, err: `bad config in receiver "r", unknown credentials "other"`},
+		{name: "inline credentials", receiver: `{name: r, credentials: contoso, personal_access_token: token}`, err: `bad auth config in receiver "r": 'credentials', 'auth' and inline credentials are mutually exclusive`},
+		{name: "unknown target credentials", receiver: `{name: r, credentials: contoso, targets: [{name: a}, {name: b, project: other, credentials: other}]}`, err: `bad config in receiver "r", unknown credentials "other"`},
+		{
+			name:     "templated organization with reconcile",
+			receiver: `{name: r, organization: '{{ .CommonLabels.tenant }}', credentials: contoso, auto_resolve: {state: Done}, reconcile: {}}`,
+			err:      `bad config in receiver "r", templated 'organization' and 'credentials' cannot be used with 'silence', 'reconcile' or 'escalation.sla'`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configYAML := `
+defaults:
+  organization: test-org
+  project: test-project
+  issue_type: Bug
+  summary: Test Summary
+  reopen_state: Active
+  reopen_duration: 5m
+credentials:
+  contoso:
+    personal_access_token: token
+alertmanager:
+  url: http://alertmanager:9093
+receivers:
+  - ` + tt.receiver + `
+template: test.tmpl
+`
+			var cfg Config
+			require.ErrorContains(t, yaml.Unmarshal([]byte(configYAML), &cfg), tt.err)
+		})
+	}
+
+	var cfg Config
+	require.ErrorContains(t, yaml.Unmarshal([]byte(`
+credentials:
+  contoso:
+    client_id: client
+receivers:
+  - {name: r, organization: o, project: p, issue_type: Bug, summary: s, reopen_state: Active, reopen_duration: 5m, credentials: contoso}
+template: test.tmpl
+`), &cfg), `bad auth config in credentials "contoso": incomplete authentication`)
+}
+
+func TestConfig_UnmarshalYAML_Auth(t *testing.T) {
+	configYAML := `
+defaults:
+  organization: test-org
+  project: test-project
+  issue_type: Bug
+  summary: Test Summary
+  reopen_state: Active
+  reopen_duration: 5m
+  auth:
+    type: workload_identity
+credentials:
+  cli:
+    auth:
+      type: azure_cli
+      tenant_id: tenant
+receivers:
+  - name: inherited
+  - name: certificate
+    auth:
+      type: service_principal_certificate
+      tenant_id: tenant
+      client_id: client
+      certificate_path: certs/client.pem
+      certificate_password_file: /run/secrets/password
+  - name: named
+    credentials: cli
+template: test.tmpl
+`
+	cfg, err := Load(configYAML)
+	require.NoError(t, err)
+
+	require.Equal(t, AuthWorkloadIdentity, cfg.Receivers[0].Auth.Type)
+	require.Equal(t, AuthServicePrincipalCertificate, cfg.Receivers[1].Auth.Type)
+
+	named, err := cfg.Receivers[2].Access(func(s string) (string, error) { return s, nil })
+	require.NoError(t, err)
+	require.Equal(t, &AuthConfig{Type: AuthAzureCLI, TenantID: "tenant"}, named.Auth)
+
+	resolveFilepaths("/etc/alert-az-do", cfg, log.NewNopLogger())
+	require.Equal(t, "/etc/alert-az-do/certs/client.pem", cfg.Receivers[1].Auth.CertificatePath)
+	require.Equal(t, "/run/secrets/password", cfg.Receivers[1].Auth.CertificatePasswordFile)
+}
+
+func TestConfig_UnmarshalYAML_AuthErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		auth string
+		err  string
+	}{
+		{name: "unknown type", auth: `{type: password}`, err: `unknown auth type "password", must be one of service_principal_secret, service_principal_certificate, workload_identity, managed_identity, azure_cli, pat, default_chain`},
+		{name: "missing field", auth: `{type: service_principal_secret, tenant_id: t, client_id: c}`, err: `auth type "service_principal_secret" requires 'client_secret'`},
+		{name: "unused field", auth: `{type: managed_identity, client_secret: s}`, err: `auth type "managed_identity" does not use 'client_secret'`},
+		{name: "unknown field", auth: `{type: pat, token: t}`, err: `unknown fields in auth: token`},
+		{
+			name: "certificate password and file",
+			auth: `{type: service_principal_certificate, tenant_id: t, client_id: c, certificate_path: p, certificate_password: s, certificate_password_file: f}`,
+			err:  `auth type "service_principal_certificate": 'certificate_password' and 'certificate_password_file' are mutually exclusive`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Config
+			require.ErrorContains(t, yaml.Unmarshal([]byte(`
+receivers:
+  - {name: r, organization: o, project: p, issue_type: Bug, summary: s, reopen_state: Active, reopen_duration: 5m, auth: `+tt.auth+`}
+template: test.tmpl
+`), &cfg), tt.err)
+		})
+	}
+
+	var cfg Config
+	require.ErrorContains(t, yaml.Unmarshal([]byte(`
+receivers:
+  - {name: r, organization: o, project: p, issue_type: Bug, summary: s, reopen_state: Active, reopen_duration: 5m, auth: {type: azure_cli}, personal_access_token: token}
+template: test.tmpl
+`), &cfg), `bad auth config in receiver "r": 'credentials', 'auth' and inline credentials are mutually exclusive`)
+}
+
+func TestConfig_UnmarshalYAML_Azure(t *testing.T) {
+	configYAML := `
+defaults:
+  organization: test-org
+  project: test-project
+  issue_type: Bug
+  summary: Test Summary
+  reopen_state: Active
+  reopen_duration: 5m
+  personal_access_token: token
+  azure:
+    cloud: AzureUSGovernment
+    federated_token_file: tokens/azure-identity-token
+receivers:
+  - name: inherited
+  - name: own
+    azure:
+      authority_host: https://login.example.com/
+      scope: api://devops/.default
+template: test.tmpl
+`
+	cfg, err := Load(configYAML)
+	require.NoError(t, err)
+	require.Same(t, cfg.Defaults.Azure, cfg.Receivers[0].Azure)
+	require.Equal(t, &AzureConfig{AuthorityHost: "https://login.example.com/", Scope: "api://devops/.default"}, cfg.Receivers[1].Azure)
+
+	resolveFilepaths("/etc/alert-az-do", cfg, log.NewNopLogger())
+	require.Equal(t, "/etc/alert-az-do/tokens/azure-identity-token", cfg.Receivers[0].Azure.FederatedTokenFile)
+
+	tests := []struct {
+		name  string
+		azure string
+		err   string
+	}{
+		{name: "unknown cloud", azure: `{cloud: AzureGermany}`, err: `unknown cloud "AzureGermany", must be one of AzurePublic, AzureChina, AzureUSGovernment`},
+		{name: "http authority host", azure: `{authority_host: 'http://login.example.com/'}`, err: `invalid authority_host "http://login.example.com/", must be an https URL`},
+		{name: "unknown field", azure: `{tenant: t}`, err: `unknown fields in azure: tenant`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Config
+			require.ErrorContains(t, yaml.Unmarshal([]byte(`
+receivers:
+  - {name: r, organization: o, project: p, issue_type: Bug, summary: s, reopen_state: Active, reopen_duration: 5m, personal_access_token: t, azure: `+tt.azure+`}
+template: test.tmpl
+`), &cfg), tt.err)
+		})
+	}
+}
+
+func TestConfig_UnmarshalYAML_SecretFiles(t *testing.T) {
+	configYAML := `
+defaults:
+  organization: test-org
+  project: test-project
+  issue_type: Bug
+  summary: Test Summary
+  reopen_state: Active
+  reopen_duration: 5m
+  personal_access_token_file: secrets/pat
+credentials:
+  contoso:
+    tenant_id: tenant
+    client_id: client
+    client_secret_file: secrets/client-secret
+receivers:
+  - name: inherited
+  - name: service-principal
+    tenant_id: tenant
+    client_id: client
+    client_secret_file: /run/secrets/client-secret
+  - name: vault
+    personal_access_token: azurekeyvault://contoso/devops-pat
+  - name: named
+    credentials: contoso
+template: test.tmpl
+`
+	cfg, err := Load(configYAML)
+	require.NoError(t, err)
+	require.Equal(t, "secrets/pat", cfg.Receivers[0].PersonalAccessTokenFile)
+	require.Empty(t, cfg.Receivers[0].PersonalAccessToken)
+	require.Empty(t, cfg.Receivers[1].PersonalAccessTokenFile)
+	require.Empty(t, cfg.Receivers[2].PersonalAccessTokenFile)
+
+	resolveFilepaths("/etc/alert-az-do", cfg, log.NewNopLogger())
+	require.Equal(t, "/etc/alert-az-do/secrets/pat", cfg.Receivers[0].PersonalAccessTokenFile)
+	require.Equal(t, "/run/secrets/client-secret", cfg.Receivers[1].ClientSecretFile)
+
+	named, err := cfg.Receivers[3].Access(func(s string) (string, error) { return s, nil })
+	require.NoError(t, err)
+	require.Equal(t, "/etc/alert-az-do/secrets/client-secret", named.ClientSecretFile)
+}
+
+func TestConfig_UnmarshalYAML_SecretErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		receiver string
+		err      string
+	}{
+		{name: "secret and file", receiver: `{name: r, personal_access_token: t, personal_access_token_file: f}`, err: `bad auth config in receiver "r": 'personal_access_token' and 'personal_access_token_file' are mutually exclusive`},
+		{name: "invalid reference", receiver: `{name: r, personal_access_token: 'azurekeyvault://contoso'}`, err: `bad auth config in receiver "r": 'personal_access_token': invalid Key Vault reference, must be azurekeyvault://<vault>/<secret>[/<version>]`},
+		{name: "target secret and file", receiver: `{name: r, personal_access_token: t, targets: [{name: a}, {name: b, project: other, personal_access_token: t, personal_access_token_file: f}]}`, err: `bad auth config in target "b": 'personal_access_token' and 'personal_access_token_file' are mutually exclusive`},
+		{name: "auth without secret", receiver: `{name: r, auth: {type: pat}}`, err: `auth type "pat" requires 'personal_access_token' or 'personal_access_token_file'`},
+		{name: "auth secret and file", receiver: `{name: r, auth: {type: pat, personal_access_token: t, personal_access_token_file: f}}`, err: `auth type "pat": 'personal_access_token' and 'personal_access_token_file' are mutually exclusive`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Config
+			require.ErrorContains(t, yaml.Unmarshal([]byte(`
+defaults:
+  organization: test-org
+  project: test-project
+  issue_type: Bug
+  summary: Test Summary
+  reopen_state: Active
+  reopen_duration: 5m
+receivers:
+  - `+tt.receiver+`
+template: test.tmpl
+`), &cfg), tt.err)
+		})
+	}
+}
+
+func TestParseKeyVaultReference(t *testing.T) {
+	ref, err := ParseKeyVaultReference("literal")
+	require.NoError(t, err)
+	require.Nil(t, ref)
+
+	ref, err = ParseKeyVaultReference("azurekeyvault://contoso/devops-pat")
+	require.NoError(t, err)
+	require.Equal(t, &KeyVaultReference{Vault: "contoso", Name: "devops-pat"}, ref)
+
+	ref, err = ParseKeyVaultReference("azurekeyvault://contoso.vault.azure.net/devops-pat/v1")
+	require.NoError(t, err)
+	require.Equal(t, &KeyVaultReference{Vault: "contoso.vault.azure.net", Name: "devops-pat", Version: "v1"}, ref)
+
+	for _, s := range []Secret{"azurekeyvault://contoso", "azurekeyvault:///pat", "azurekeyvault://contoso/pat/", "azurekeyvault://contoso/pat/v1/x"} {
+		_, err = ParseKeyVaultReference(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestLoadFile_ReceiverFiles(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(path.Join(dir, "receivers.d", "secrets"), 0o755))
+	require.NoError(t, os.WriteFile(path.Join(dir, "config.yaml"), []byte(`
+defaults:
+  organization: test-org
+  issue_type: Bug
+  summary: Test Summary
+  reopen_state: Active
+  reopen_duration: 5m
+  personal_access_token_file: secrets/pat
+receivers:
+  - name: main
+    project: Main
+receiver_files: ['receivers.d/*.yml']
+template: test.tmpl
+`), 0o600))
+	require.NoError(t, os.WriteFile(path.Join(dir, "receivers.d", "team-a.yml"), []byte(`
+receivers:
+  - name: team-a
+    project: A
+`), 0o600))
+	require.NoError(t, os.WriteFile(path.Join(dir, "receivers.d", "team-b.yml"), []byte(`
+receivers:
+  - name: team-b
+    project: B
+    personal_access_token_file: secrets/team-b-pat
+`), 0o600))
+
+	cfg, _, err := LoadFile(path.Join(dir, "config.yaml"), false, log.NewNopLogger())
+	require.NoError(t, err)
+	require.Len(t, cfg.Receivers, 3)
+	require.Equal(t, "main", cfg.Receivers[0].Name)
+	require.Equal(t, "team-a", cfg.Receivers[1].Name)
+	require.Equal(t, "team-b", cfg.Receivers[2].Name)
+
+	// Inherited paths are relative to the configuration file, the receivers' own paths to their receiver file.
+	require.Equal(t, path.Join(dir, "secrets", "pat"), cfg.Receivers[1].PersonalAccessTokenFile)
+	require.Equal(t, path.Join(dir, "receivers.d", "secrets", "team-b-pat"), cfg.Receivers[2].PersonalAccessTokenFile)
+
+	// Added and removed files are picked up when loading again.
+	require.NoError(t, os.Remove(path.Join(dir, "receivers.d", "team-a.yml")))
+	require.NoError(t, os.WriteFile(path.Join(dir, "receivers.d", "team-c.yml"), []byte(`receivers: [{name: team-c, project: C}]`), 0o600))
+	cfg, _, err = LoadFile(path.Join(dir, "config.yaml"), false, log.NewNopLogger())
+	require.NoError(t, err)
+	require.Len(t, cfg.Receivers, 3)
+	require.Equal(t, "team-b", cfg.Receivers[1].Name)
+	require.Equal(t, "team-c", cfg.Receivers[2].Name)
+
+	// Duplicates are reported with their files.
+	require.NoError(t, os.WriteFile(path.Join(dir, "receivers.d", "team-d.yml"), []byte(`receivers: [{name: team-b, project: D}]`), 0o600))
+	_, _, err = LoadFile(path.Join(dir, "config.yaml"), false, log.NewNopLogger())
+	require.EqualError(t, err, fmt.Sprintf(`duplicate receiver name "team-b" in %s and %s`, path.Join(dir, "receivers.d", "team-b.yml"), path.Join(dir, "receivers.d", "team-d.yml")))
+
+	require.NoError(t, os.WriteFile(path.Join(dir, "receivers.d", "team-d.yml"), []byte(`{receivers: [{name: team-d, project: D}], template: other.tmpl}`), 0o600))
+	_, _, err = LoadFile(path.Join(dir, "config.yaml"), false, log.NewNopLogger())
+	require.EqualError(t, err, fmt.Sprintf(`bad receiver file %s: unknown fields in receiver file: template`, path.Join(dir, "receivers.d", "team-d.yml")))
+}
+
+func TestConfig_UnmarshalYAML_DuplicateReceivers(t *testing.T) {
+	var cfg Config
+	require.EqualError(t, yaml.Unmarshal([]byte(`
+defaults: {organization: o, project: p, issue_type: Bug, summary: s, reopen_state: Active, reopen_duration: 5m, personal_access_token: t}
+receivers: [{name: r}, {name: r}]
+template: test.tmpl
+`), &cfg), `duplicate receiver name "r"`)
+}
+
+func TestConfig_UnmarshalYAML_Templates(t *testing.T) {
+	configYAML := `
+defaults: {organization: o, project: p, issue_type: Bug, summary: s, reopen_state: Active, reopen_duration: 5m, personal_access_token: t}
+receivers:
+  - name: shared
+  - name: team-a
+    template: team-a/*.tmpl
+  - name: team-b
+    template: [team-b/common.tmpl, /opt/templates/team-b.tmpl]
+template: [alert-az-do.tmpl, templates/*.tmpl]
+`
+	cfg, err := Load(configYAML)
+	require.NoError(t, err)
+	require.Equal(t, Templates{"alert-az-do.tmpl", "templates/*.tmpl"}, cfg.Template)
+	require.Empty(t, cfg.Receivers[0].Template)
+	require.Equal(t, Templates{"team-a/*.tmpl"}, cfg.Receivers[1].Template)
+
+	resolveFilepaths("/etc/alert-az-do", cfg, log.NewNopLogger())
+	require.Equal(t, Templates{"/etc/alert-az-do/alert-az-do.tmpl", "/etc/alert-az-do/templates/*.tmpl"}, cfg.Template)
+	require.Equal(t, Templates{"/etc/alert-az-do/team-a/*.tmpl"}, cfg.Receivers[1].Template)
+	require.Equal(t, Templates{"/etc/alert-az-do/team-b/common.tmpl", "/opt/templates/team-b.tmpl"}, cfg.Receivers[2].Template)
+
+	out, err := yaml.Marshal(&Config{Template: Templates{"test.tmpl"}})
+	require.NoError(t, err)
+	require.Contains(t, string(out), "template: test.tmpl\n")
+
+	tests := []struct {
+		name string
+		yaml string
+		err  string
+	}{
+		{name: "empty entry", yaml: "template: [test.tmpl, '']", err: "empty template path"},
+		{name: "defaults", yaml: "defaults: {template: t.tmpl}\ntemplate: test.tmpl", err: "bad config in defaults section: 'template' must be set at the top level"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Config
+			require.ErrorContains(t, yaml.Unmarshal([]byte(`
+receivers:
+  - {name: r, organization: o, project: p, issue_type: Bug, summary: s, reopen_state: Active, reopen_duration: 5m, personal_access_token: t}
+`+tt.yaml+`
+`), &cfg), tt.err)
+		})
+	}
+}
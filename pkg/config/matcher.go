@@ -0,0 +1,151 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MatchType is the operator of a label matcher.
+type MatchType string
+
+// The match types of the Alertmanager matcher syntax.
+const (
+	MatchEqual     MatchType = "="
+	MatchNotEqual  MatchType = "!="
+	MatchRegexp    MatchType = "=~"
+	MatchNotRegexp MatchType = "!~"
+)
+
+// matcherRE splits a matcher into label name, operator and value.
+var matcherRE = regexp.MustCompile(`^\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*(=~|!~|!=|=)\s*(.*?)\s*$`)
+
+// Matcher is a label matcher in the Alertmanager syntax, e.g. `severity="critical"` or `team=~"db|storage"`. Regular
+// expressions are anchored at both ends. Missing labels match as empty values.
+type Matcher struct {
+	Name  string
+	Type  MatchType
+	Value string
+
+	re *regexp.Regexp
+}
+
+// ParseMatcher parses a matcher. The value may be double-quoted.
+func ParseMatcher(s string) (*Matcher, error) {
+	parts := matcherRE.FindStringSubmatch(s)
+	if parts == nil {
+		return nil, fmt.Errorf("invalid matcher %q", s)
+	}
+	m := &Matcher{Name: parts[1], Type: MatchType(parts[2]), Value: parts[3]}
+	if strings.HasPrefix(m.Value, `"`) {
+		value, err := strconv.Unquote(m.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid matcher %q: bad quoting of value", s)
+		}
+		m.Value = value
+	}
+	if m.Type == MatchRegexp || m.Type == MatchNotRegexp {
+		re, err := regexp.Compile("^(?:" + m.Value + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid matcher %q: %s", s, err)
+		}
+		m.re = re
+	}
+	return m, nil
+}
+
+// ParseMatchers parses a list of matchers.
+func ParseMatchers(ss []string) (Matchers, error) {
+	matchers := make(Matchers, 0, len(ss))
+	for _, s := range ss {
+		m, err := ParseMatcher(s)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers, nil
+}
+
+// Matches reports whether the labels satisfy the matcher.
+func (m *Matcher) Matches(labels map[string]string) bool {
+	value := labels[m.Name]
+	switch m.Type {
+	case MatchEqual:
+		return value == m.Value
+	case MatchNotEqual:
+		return value != m.Value
+	case MatchRegexp:
+		return m.re.MatchString(value)
+	case MatchNotRegexp:
+		return !m.re.MatchString(value)
+	}
+	return false
+}
+
+func (m *Matcher) String() string {
+	return fmt.Sprintf("%s%s%q", m.Name, m.Type, m.Value)
+}
+
+// MarshalYAML implements the yaml.Marshaler interface.
+func (m *Matcher) MarshalYAML() (interface{}, error) {
+	return m.String(), nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (m *Matcher) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.String())
+}
+
+// Matchers is a list of matchers, given in the configuration file as a list of strings in the Alertmanager syntax.
+type Matchers []*Matcher
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (ms *Matchers) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var ss []string
+	if err := unmarshal(&ss); err != nil {
+		return err
+	}
+	matchers, err := ParseMatchers(ss)
+	if err != nil {
+		return err
+	}
+	*ms = matchers
+	return nil
+}
+
+// Matches reports whether the labels satisfy all matchers.
+func (ms Matchers) Matches(labels map[string]string) bool {
+	for _, m := range ms {
+		if !m.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchesAny reports whether the labels satisfy at least one of the matchers.
+func (ms Matchers) MatchesAny(labels map[string]string) bool {
+	for _, m := range ms {
+		if m.Matches(labels) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v3"
+)
+
+func TestParseMatcher(t *testing.T) {
+	labels := map[string]string{"severity": "critical", "team": "db"}
+	tests := []struct {
+		matcher string
+		name    string
+		typ     MatchType
+		value   string
+		matches bool
+	}{
+		{`severity="critical"`, "severity", MatchEqual, "critical", true},
+		{`severity = critical`, "severity", MatchEqual, "critical", true},
+		{`severity!="critical"`, "severity", MatchNotEqual, "critical", false},
+		{`team=~"db|storage"`, "team", MatchRegexp, "db|storage", true},
+		{`team=~"d"`, "team", MatchRegexp, "d", false},
+		{`team!~"web.*"`, "team", MatchNotRegexp, "web.*", true},
+		{`env=""`, "env", MatchEqual, "", true},
+		{`env!=""`, "env", MatchNotEqual, "", false},
+		{`summary="a \"quoted\" value"`, "summary", MatchEqual, `a "quoted" value`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.matcher, func(t *testing.T) {
+			m, err := ParseMatcher(tt.matcher)
+			require.NoError(t, err)
+			require.Equal(t, tt.name, m.Name)
+			require.Equal(t, tt.typ, m.Type)
+			require.Equal(t, tt.value, m.Value)
+			require.Equal(t, tt.matches, m.Matches(labels))
+		})
+	}
+}
+
+func TestParseMatcher_Errors(t *testing.T) {
+	for _, matcher := range []string{`severity`, `1abc="x"`, `severity="x"x`, `severity="unterminated`, `team=~"("`} {
+		t.Run(matcher, func(t *testing.T) {
+			_, err := ParseMatcher(matcher)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestParseMatchers(t *testing.T) {
+	matchers, err := ParseMatchers([]string{`severity="critical"`, `team=~"db|storage"`})
+	require.NoError(t, err)
+	require.Len(t, matchers, 2)
+	require.True(t, matchers.Matches(map[string]string{"severity": "critical", "team": "storage"}))
+	require.False(t, matchers.Matches(map[string]string{"severity": "warning", "team": "storage"}))
+	require.True(t, matchers.MatchesAny(map[string]string{"severity": "warning", "team": "storage"}))
+	require.False(t, matchers.MatchesAny(map[string]string{"severity": "warning", "team": "web"}))
+	require.Equal(t, `team=~"db|storage"`, matchers[1].String())
+
+	_, err = ParseMatchers([]string{`severity="critical"`, `bad`})
+	require.ErrorContains(t, err, `invalid matcher "bad"`)
+}
+
+func TestMatchers_YAML(t *testing.T) {
+	var matchers Matchers
+	require.NoError(t, yaml.Unmarshal([]byte(`['severity="critical"', 'team=~"db|storage"']`), &matchers))
+	require.Len(t, matchers, 2)
+
+	out, err := yaml.Marshal(matchers)
+	require.NoError(t, err)
+	require.Equal(t, "- severity=\"critical\"\n- team=~\"db|storage\"\n", string(out))
+
+	require.ErrorContains(t, yaml.Unmarshal([]byte(`['severity']`), &matchers), `invalid matcher "severity"`)
+}
@@ -0,0 +1,188 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RelabelAction is the action of a relabel config.
+type RelabelAction string
+
+// The supported relabel actions, with the semantics of Prometheus.
+const (
+	RelabelReplace   RelabelAction = "replace"
+	RelabelKeep      RelabelAction = "keep"
+	RelabelDrop      RelabelAction = "drop"
+	RelabelLabelDrop RelabelAction = "labeldrop"
+	RelabelLabelMap  RelabelAction = "labelmap"
+)
+
+// Regexp is a regular expression anchored at both ends, given unanchored in the configuration file.
+type Regexp struct {
+	*regexp.Regexp
+	original string
+}
+
+// NewRegexp compiles the regular expression, anchored at both ends.
+func NewRegexp(s string) (Regexp, error) {
+	re, err := regexp.Compile("^(?:" + s + ")$")
+	return Regexp{Regexp: re, original: s}, err
+}
+
+// MustNewRegexp is like NewRegexp but panics if the regular expression does not compile.
+func MustNewRegexp(s string) Regexp {
+	re, err := NewRegexp(s)
+	if err != nil {
+		panic(err)
+	}
+	return re
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (re *Regexp) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	r, err := NewRegexp(s)
+	if err != nil {
+		return err
+	}
+	*re = r
+	return nil
+}
+
+// MarshalYAML implements the yaml.Marshaler interface.
+func (re Regexp) MarshalYAML() (interface{}, error) {
+	return re.original, nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface, used for JSON.
+func (re Regexp) MarshalText() ([]byte, error) {
+	return []byte(re.original), nil
+}
+
+// RelabelConfig is a Prometheus-style relabel rule, rewriting the labels of alerts or dropping them.
+type RelabelConfig struct {
+	SourceLabels []string      `yaml:"source_labels,flow,omitempty" json:"source_labels,omitempty"`
+	Separator    string        `yaml:"separator,omitempty" json:"separator,omitempty"`
+	Regex        Regexp        `yaml:"regex,omitempty" json:"regex,omitempty"`
+	TargetLabel  string        `yaml:"target_label,omitempty" json:"target_label,omitempty"`
+	Replacement  string        `yaml:"replacement,omitempty" json:"replacement,omitempty"`
+	Action       RelabelAction `yaml:"action,omitempty" json:"action,omitempty"`
+
+	// Catches all undefined fields and must be empty after parsing.
+	XXX map[string]interface{} `yaml:",inline" json:"-"`
+}
+
+// defaultRelabelConfig holds the defaults of the Prometheus relabel configs.
+var defaultRelabelConfig = RelabelConfig{
+	Separator:   ";",
+	Regex:       MustNewRegexp("(.*)"),
+	Replacement: "$1",
+	Action:      RelabelReplace,
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (rc *RelabelConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	*rc = defaultRelabelConfig
+	type plain RelabelConfig
+	if err := unmarshal((*plain)(rc)); err != nil {
+		return err
+	}
+
+	switch rc.Action {
+	case RelabelReplace:
+		if rc.TargetLabel == "" {
+			return fmt.Errorf("relabel action %q requires 'target_label'", rc.Action)
+		}
+	case RelabelKeep, RelabelDrop:
+		if len(rc.SourceLabels) == 0 {
+			return fmt.Errorf("relabel action %q requires 'source_labels'", rc.Action)
+		}
+	case RelabelLabelDrop, RelabelLabelMap:
+		if len(rc.SourceLabels) > 0 || rc.TargetLabel != "" {
+			return fmt.Errorf("relabel action %q must not have 'source_labels' or 'target_label'", rc.Action)
+		}
+	default:
+		return fmt.Errorf("unknown relabel action %q", rc.Action)
+	}
+	return checkOverflow(rc.XXX, "relabel_configs")
+}
+
+// Relabel applies the relabel configs in order to a copy of the labels. It returns nil when the labels are dropped.
+func Relabel(labels map[string]string, cfgs []*RelabelConfig) map[string]string {
+	res := make(map[string]string, len(labels))
+	for k, v := range labels {
+		res[k] = v
+	}
+	for _, cfg := range cfgs {
+		if !cfg.apply(res) {
+			return nil
+		}
+	}
+	return res
+}
+
+// apply applies the relabel config to the labels in place, returning false when they are dropped.
+func (rc *RelabelConfig) apply(labels map[string]string) bool {
+	values := make([]string, 0, len(rc.SourceLabels))
+	for _, name := range rc.SourceLabels {
+		values = append(values, labels[name])
+	}
+	value := strings.Join(values, rc.Separator)
+
+	switch rc.Action {
+	case RelabelKeep:
+		return rc.Regex.MatchString(value)
+	case RelabelDrop:
+		return !rc.Regex.MatchString(value)
+	case RelabelReplace:
+		indexes := rc.Regex.FindStringSubmatchIndex(value)
+		if indexes == nil {
+			break
+		}
+		target := string(rc.Regex.ExpandString(nil, rc.TargetLabel, value, indexes))
+		replacement := string(rc.Regex.ExpandString(nil, rc.Replacement, value, indexes))
+		if target == "" {
+			break
+		}
+		if replacement == "" {
+			delete(labels, target)
+			break
+		}
+		labels[target] = replacement
+	case RelabelLabelDrop:
+		for name := range labels {
+			if rc.Regex.MatchString(name) {
+				delete(labels, name)
+			}
+		}
+	case RelabelLabelMap:
+		mapped := map[string]string{}
+		for name, v := range labels {
+			if rc.Regex.MatchString(name) {
+				mapped[rc.Regex.ReplaceAllString(name, rc.Replacement)] = v
+			}
+		}
+		for name, v := range mapped {
+			labels[name] = v
+		}
+	}
+	return true
+}
@@ -0,0 +1,124 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v3"
+)
+
+func parseRelabelConfigs(t *testing.T, s string) []*RelabelConfig {
+	var cfgs []*RelabelConfig
+	require.NoError(t, yaml.Unmarshal([]byte(s), &cfgs))
+	return cfgs
+}
+
+func TestRelabel(t *testing.T) {
+	labels := map[string]string{"alertname": "HighCPU", "pod": "web-7d9f", "instance_id": "i-123", "namespace": "shop", "severity": "warning"}
+	tests := []struct {
+		name     string
+		configs  string
+		expected map[string]string
+	}{
+		{
+			name:     "labeldrop",
+			configs:  `[{action: labeldrop, regex: 'pod|instance_.*'}]`,
+			expected: map[string]string{"alertname": "HighCPU", "namespace": "shop", "severity": "warning"},
+		},
+		{
+			name:     "replace",
+			configs:  `[{source_labels: [namespace, severity], regex: '(.*);(.*)', target_label: route, replacement: '$1-$2'}]`,
+			expected: map[string]string{"alertname": "HighCPU", "pod": "web-7d9f", "instance_id": "i-123", "namespace": "shop", "severity": "warning", "route": "shop-warning"},
+		},
+		{
+			name:     "replace with defaults",
+			configs:  `[{source_labels: [pod], target_label: workload}]`,
+			expected: map[string]string{"alertname": "HighCPU", "pod": "web-7d9f", "instance_id": "i-123", "namespace": "shop", "severity": "warning", "workload": "web-7d9f"},
+		},
+		{
+			name:     "replace without match",
+			configs:  `[{source_labels: [pod], regex: 'db-.*', target_label: workload}]`,
+			expected: labels,
+		},
+		{
+			name:     "replace with empty value deletes",
+			configs:  `[{source_labels: [missing], target_label: pod}]`,
+			expected: map[string]string{"alertname": "HighCPU", "instance_id": "i-123", "namespace": "shop", "severity": "warning"},
+		},
+		{
+			name:     "labelmap",
+			configs:  `[{action: labelmap, regex: 'instance_(.*)', replacement: 'aws_$1'}, {action: labeldrop, regex: 'instance_.*|pod'}]`,
+			expected: map[string]string{"alertname": "HighCPU", "aws_id": "i-123", "namespace": "shop", "severity": "warning"},
+		},
+		{
+			name:     "keep",
+			configs:  `[{action: keep, source_labels: [namespace], regex: 'shop|cart'}]`,
+			expected: labels,
+		},
+		{
+			name:     "keep drops",
+			configs:  `[{action: keep, source_labels: [namespace], regex: 'cart'}]`,
+			expected: nil,
+		},
+		{
+			name:     "drop",
+			configs:  `[{action: drop, source_labels: [severity], regex: info|warning}]`,
+			expected: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Relabel(labels, parseRelabelConfigs(t, tt.configs))
+			if tt.expected == nil {
+				require.Nil(t, result)
+				return
+			}
+			require.Equal(t, tt.expected, result)
+		})
+	}
+	// The labels are not modified in place.
+	require.Len(t, labels, 5)
+}
+
+func TestRelabelConfig_UnmarshalYAML(t *testing.T) {
+	cfgs := parseRelabelConfigs(t, `[{target_label: x}]`)
+	require.Equal(t, RelabelReplace, cfgs[0].Action)
+	require.Equal(t, ";", cfgs[0].Separator)
+	require.Equal(t, "$1", cfgs[0].Replacement)
+	require.True(t, cfgs[0].Regex.MatchString("anything"))
+
+	out, err := yaml.Marshal(cfgs[0])
+	require.NoError(t, err)
+	require.Contains(t, string(out), "regex: (.*)")
+
+	for _, test := range []struct {
+		config string
+		errMsg string
+	}{
+		{`[{action: replace}]`, `relabel action "replace" requires 'target_label'`},
+		{`[{action: keep}]`, `relabel action "keep" requires 'source_labels'`},
+		{`[{action: labeldrop, source_labels: [a]}]`, `relabel action "labeldrop" must not have 'source_labels' or 'target_label'`},
+		{`[{action: hashmod}]`, `unknown relabel action "hashmod"`},
+		{`[{target_label: x, regex: '('}]`, "missing closing )"},
+		{`[{target_label: x, modulus: 2}]`, "unknown fields in relabel_configs: modulus"},
+	} {
+		var cfgs []*RelabelConfig
+		err := yaml.Unmarshal([]byte(test.config), &cfgs)
+		require.Error(t, err, test.config)
+		require.Contains(t, err.Error(), test.errMsg)
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import "fmt"
+
+// ReceiversFor returns the receivers of a notification sent to the named receiver with the given labels: the
+// receivers of the matching routes of the routing tree, or the receiver of that name when no route matches.
+func (c *Config) ReceiversFor(name string, labels map[string]string) []*ReceiverConfig {
+	var names []string
+	if c.Route != nil {
+		names = c.Route.match(labels, "")
+	}
+	if len(names) == 0 {
+		names = []string{name}
+	}
+
+	var receivers []*ReceiverConfig
+	seen := map[string]bool{}
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		if rc := c.ReceiverByName(name); rc != nil {
+			receivers = append(receivers, rc)
+		}
+	}
+	return receivers
+}
+
+// match returns the receivers of the notification matching the route, which is assumed.
+func (r *Route) match(labels map[string]string, receiver string) []string {
+	if r.Receiver != "" {
+		receiver = r.Receiver
+	}
+	var names []string
+	for _, child := range r.Routes {
+		if !child.Matchers.Matches(labels) {
+			continue
+		}
+		names = append(names, child.match(labels, receiver)...)
+		if !child.Continue {
+			break
+		}
+	}
+	if len(names) == 0 && receiver != "" {
+		names = []string{receiver}
+	}
+	return names
+}
+
+// receivers adds the receivers of the route and its child routes to names.
+func (r *Route) receivers(names map[string]bool) {
+	if r.Receiver != "" {
+		names[r.Receiver] = true
+	}
+	for _, child := range r.Routes {
+		child.receivers(names)
+	}
+}
+
+// indirectReceivers returns the names of the receivers notified through the routing tree, inputs or CloudEvents,
+// whose alerts are not those of the Alertmanager receiver of their name.
+func (c *Config) indirectReceivers() map[string]bool {
+	names := map[string]bool{}
+	if c.Route != nil {
+		c.Route.receivers(names)
+	}
+	for _, in := range c.Inputs {
+		if in.Receiver != "" {
+			names[in.Receiver] = true
+		}
+	}
+	if c.CloudEvents != nil && c.CloudEvents.DefaultReceiver != "" {
+		names[c.CloudEvents.DefaultReceiver] = true
+	}
+	return names
+}
+
+func (r *Route) validate(c *Config) error {
+	if r.Receiver != "" && c.ReceiverByName(r.Receiver) == nil {
+		return fmt.Errorf("unknown receiver %q", r.Receiver)
+	}
+	for _, child := range r.Routes {
+		if err := child.validate(c); err != nil {
+			return err
+		}
+	}
+	return checkOverflow(r.XXX, "route")
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v3"
+)
+
+const routeConfigYAML = `
+defaults:
+  organization: test-org
+  personal_access_token: test-token
+  project: test-project
+  issue_type: Bug
+  summary: Test Summary
+  reopen_state: Active
+  reopen_duration: 5m
+
+receivers:
+  - name: default
+  - name: db
+  - name: db-critical
+  - name: secops
+  - name: web
+template: test.tmpl
+%s
+`
+
+func receiverNames(receivers []*ReceiverConfig) []string {
+	var names []string
+	for _, rc := range receivers {
+		names = append(names, rc.Name)
+	}
+	return names
+}
+
+func TestConfig_ReceiversFor(t *testing.T) {
+	var cfg Config
+	require.NoError(t, yaml.Unmarshal([]byte(fmt.Sprintf(routeConfigYAML, `
+route:
+  routes:
+    - matchers: ['category="security"']
+      receiver: secops
+      continue: true
+    - matchers: ['team=~"db|storage"']
+      receiver: db
+      routes:
+        - matchers: ['severity="critical"']
+          receiver: db-critical
+        - matchers: ['severity="warning"']
+    - matchers: ['team="web"', 'severity!="info"']
+      receiver: web
+`)), &cfg))
+
+	tests := []struct {
+		name     string
+		receiver string
+		labels   map[string]string
+		expected []string
+	}{
+		{"nested route", "am", map[string]string{"team": "db", "severity": "critical"}, []string{"db-critical"}},
+		{"receiver inherited from parent", "am", map[string]string{"team": "storage", "severity": "warning"}, []string{"db"}},
+		{"parent receiver without matching child", "am", map[string]string{"team": "db", "severity": "info"}, []string{"db"}},
+		{"continue", "am", map[string]string{"category": "security", "team": "web", "severity": "critical"}, []string{"secops", "web"}},
+		{"first match stops", "am", map[string]string{"team": "db", "severity": "critical", "category": "x"}, []string{"db-critical"}},
+		{"negative matcher", "default", map[string]string{"team": "web", "severity": "info"}, []string{"default"}},
+		{"fallback to name", "default", map[string]string{"team": "other"}, []string{"default"}},
+		{"no receiver", "unknown", map[string]string{"team": "other"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, receiverNames(cfg.ReceiversFor(tt.receiver, tt.labels)))
+		})
+	}
+}
+
+func TestConfig_ReceiversFor_RootReceiver(t *testing.T) {
+	var cfg Config
+	require.NoError(t, yaml.Unmarshal([]byte(fmt.Sprintf(routeConfigYAML, `
+route:
+  receiver: default
+  routes:
+    - matchers: ['team="db"']
+      receiver: db
+      continue: true
+    - matchers: ['team="db"']
+      receiver: db
+`)), &cfg))
+
+	// Receivers are notified once, and the root receiver takes precedence over the name.
+	require.Equal(t, []string{"db"}, receiverNames(cfg.ReceiversFor("web", map[string]string{"team": "db"})))
+	require.Equal(t, []string{"default"}, receiverNames(cfg.ReceiversFor("web", map[string]string{"team": "web"})))
+}
+
+func TestConfig_ReceiversFor_NoRoute(t *testing.T) {
+	var cfg Config
+	require.NoError(t, yaml.Unmarshal([]byte(fmt.Sprintf(routeConfigYAML, "")), &cfg))
+	require.Equal(t, []string{"db"}, receiverNames(cfg.ReceiversFor("db", map[string]string{"team": "web"})))
+}
+
+func TestConfig_UnmarshalYAML_Route(t *testing.T) {
+	for _, test := range []struct {
+		route  string
+		errMsg string
+	}{
+		{"route:\n  matchers: ['a=b']\n", "bad config in route section: the root route must not have matchers"},
+		{"route:\n  receiver: unknown\n", `bad config in route section: unknown receiver "unknown"`},
+		{"route:\n  routes:\n    - matchers: ['a=b']\n      receiver: unknown\n", `bad config in route section: unknown receiver "unknown"`},
+		{"route:\n  routes:\n    - matchers: ['a']\n      receiver: db\n", `invalid matcher "a"`},
+		{"route:\n  routes:\n    - match: {a: b}\n      receiver: db\n", "unknown fields in route: match"},
+	} {
+		var cfg Config
+		err := yaml.Unmarshal([]byte(fmt.Sprintf(routeConfigYAML, test.route)), &cfg)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), test.errMsg)
+	}
+}
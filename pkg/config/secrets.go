@@ -0,0 +1,73 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// keyVaultScheme prefixes the secret values referencing Azure Key Vault secrets.
+const keyVaultScheme = "azurekeyvault://"
+
+// KeyVaultReference is a reference to an Azure Key Vault secret, written azurekeyvault://<vault>/<secret>[/<version>]
+// in place of a secret value. Vault is the name of the vault, or its host name when it contains dots.
+type KeyVaultReference struct {
+	Vault   string
+	Name    string
+	Version string
+}
+
+// ParseKeyVaultReference parses the Key Vault reference of a secret value, returning nil when the value is not a
+// reference.
+func ParseKeyVaultReference(s Secret) (*KeyVaultReference, error) {
+	if !strings.HasPrefix(string(s), keyVaultScheme) {
+		return nil, nil
+	}
+	parts := strings.Split(strings.TrimPrefix(string(s), keyVaultScheme), "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" || (len(parts) == 3 && parts[2] == "") {
+		return nil, fmt.Errorf("invalid Key Vault reference, must be %s<vault>/<secret>[/<version>]", keyVaultScheme)
+	}
+	ref := &KeyVaultReference{Vault: parts[0], Name: parts[1]}
+	if len(parts) == 3 {
+		ref.Version = parts[2]
+	}
+	return ref, nil
+}
+
+// secretSet reports whether a secret is set, inline or through its file.
+func secretSet(secret Secret, file string) bool {
+	return secret != "" || file != ""
+}
+
+// checkSecret checks that a secret is set either inline or through its file, and that inline Key Vault references are
+// valid.
+func checkSecret(name string, secret Secret, file string) error {
+	if secret != "" && file != "" {
+		return fmt.Errorf("'%s' and '%s_file' are mutually exclusive", name, name)
+	}
+	if _, err := ParseKeyVaultReference(secret); err != nil {
+		return fmt.Errorf("'%s': %s", name, err)
+	}
+	return nil
+}
+
+// checkSecrets checks the client secret and personal access token of a credential holder with checkSecret.
+func checkSecrets(clientSecret Secret, clientSecretFile string, personalAccessToken Secret, personalAccessTokenFile string) error {
+	if err := checkSecret("client_secret", clientSecret, clientSecretFile); err != nil {
+		return err
+	}
+	return checkSecret("personal_access_token", personalAccessToken, personalAccessTokenFile)
+}
@@ -0,0 +1,282 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package input maps arbitrary JSON documents into Alertmanager webhook notifications, following the declarative
+// field mapping of a configured input.
+package input
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stakater/alert-az-do/pkg/alertmanager"
+	"github.com/stakater/alert-az-do/pkg/config"
+	"github.com/stakater/alert-az-do/pkg/template"
+)
+
+// webhookVersion is the webhook protocol version of the mapped notifications.
+const webhookVersion = "4"
+
+// expression is a compiled mapped value: a JSONPath expression or a template.
+type expression struct {
+	text string
+	path path
+}
+
+// Input maps the JSON documents received by a configured input into notifications.
+type Input struct {
+	conf *config.InputConfig
+	tmpl *template.Template
+
+	alerts       *expression
+	status       *expression
+	fingerprint  *expression
+	labels       map[string]*expression
+	annotations  map[string]*expression
+	startsAt     *expression
+	endsAt       *expression
+	generatorURL *expression
+}
+
+// New compiles the field mapping of the input. Templates are executed with the given templates, so that they may
+// use the template definitions and functions available to receivers.
+func New(conf *config.InputConfig, tmpl *template.Template) (*Input, error) {
+	in := &Input{
+		conf:        conf,
+		tmpl:        tmpl,
+		labels:      map[string]*expression{},
+		annotations: map[string]*expression{},
+	}
+
+	var err error
+	compile := func(field, text string) *expression {
+		if err != nil || text == "" {
+			return nil
+		}
+		e := &expression{text: text}
+		if strings.HasPrefix(text, "$") {
+			if e.path, err = compilePath(text); err != nil {
+				err = errors.Wrap(err, field)
+			}
+		}
+		return e
+	}
+	in.alerts = compile("alerts", conf.Alerts)
+	in.status = compile("status", conf.Status)
+	in.fingerprint = compile("fingerprint", conf.Fingerprint)
+	in.startsAt = compile("starts_at", conf.StartsAt)
+	in.endsAt = compile("ends_at", conf.EndsAt)
+	in.generatorURL = compile("generator_url", conf.GeneratorURL)
+	for name, text := range conf.Labels {
+		in.labels[name] = compile("labels."+name, text)
+	}
+	for name, text := range conf.Annotations {
+		in.annotations[name] = compile("annotations."+name, text)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return in, nil
+}
+
+// Receiver returns the name of the receiver notified of the input's alerts.
+func (in *Input) Receiver() string {
+	return in.conf.Receiver
+}
+
+// ToData decodes the JSON document and maps it into a notification of the input's receiver.
+func (in *Input) ToData(r io.Reader) (*alertmanager.Data, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, errors.Wrap(err, "decode document")
+	}
+
+	items := []interface{}{doc}
+	if in.alerts != nil {
+		var ok bool
+		if items, ok = in.alerts.path.lookup(doc).([]interface{}); !ok {
+			return nil, errors.Errorf("alerts: %s does not select an array", in.alerts.text)
+		}
+	}
+
+	data := &alertmanager.Data{
+		Version:  webhookVersion,
+		Receiver: in.conf.Receiver,
+		Status:   alertmanager.AlertResolved,
+	}
+	for i, item := range items {
+		alert, err := in.alert(item)
+		if err != nil {
+			if in.alerts != nil {
+				return nil, errors.Wrapf(err, "alert %d", i)
+			}
+			return nil, err
+		}
+		if alert.Status == alertmanager.AlertFiring {
+			data.Status = alertmanager.AlertFiring
+		}
+		data.Alerts = append(data.Alerts, alert)
+	}
+	if len(data.Alerts) == 0 {
+		return nil, errors.New("no alerts in document")
+	}
+
+	var labels, annotations []alertmanager.KV
+	for _, a := range data.Alerts {
+		labels = append(labels, a.Labels)
+		annotations = append(annotations, a.Annotations)
+	}
+	data.CommonLabels = alertmanager.CommonKV(labels)
+	data.CommonAnnotations = alertmanager.CommonKV(annotations)
+
+	groupBy := in.conf.GroupBy
+	if len(groupBy) == 0 {
+		groupBy = []string{alertmanager.AlertNameLabel}
+	}
+	data.GroupLabels = alertmanager.KV{}
+	for _, name := range groupBy {
+		if v, ok := data.CommonLabels[name]; ok {
+			data.GroupLabels[name] = v
+		}
+	}
+	var pairs []string
+	for _, pair := range data.GroupLabels.SortedPairs() {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", pair.Name, pair.Value))
+	}
+	data.GroupKey = fmt.Sprintf("{}/%s:{%s}", in.conf.Receiver, strings.Join(pairs, ", "))
+	return data, nil
+}
+
+// alert maps a single alert.
+func (in *Input) alert(item interface{}) (alertmanager.Alert, error) {
+	alert := alertmanager.Alert{
+		Status:      alertmanager.AlertFiring,
+		Labels:      alertmanager.KV{},
+		Annotations: alertmanager.KV{},
+	}
+
+	status, err := in.eval(in.status, item)
+	if err != nil {
+		return alert, errors.Wrap(err, "status")
+	}
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "", alertmanager.AlertFiring:
+	case alertmanager.AlertResolved:
+		alert.Status = alertmanager.AlertResolved
+	default:
+		return alert, errors.Errorf("status: %q is neither %q nor %q", status, alertmanager.AlertFiring, alertmanager.AlertResolved)
+	}
+
+	for name, e := range in.labels {
+		if alert.Labels[name], err = in.eval(e, item); err != nil {
+			return alert, errors.Wrapf(err, "labels.%s", name)
+		}
+		if alert.Labels[name] == "" {
+			delete(alert.Labels, name)
+		}
+	}
+	for name, e := range in.annotations {
+		if alert.Annotations[name], err = in.eval(e, item); err != nil {
+			return alert, errors.Wrapf(err, "annotations.%s", name)
+		}
+		if alert.Annotations[name] == "" {
+			delete(alert.Annotations, name)
+		}
+	}
+	if len(alert.Labels) == 0 {
+		return alert, errors.New("labels: all labels are empty")
+	}
+
+	// The identity is hashed, so that any value makes a valid work item tag.
+	identity, err := in.eval(in.fingerprint, item)
+	if err != nil {
+		return alert, errors.Wrap(err, "fingerprint")
+	}
+	if identity == "" {
+		var pairs []string
+		for _, pair := range alert.Labels.SortedPairs() {
+			pairs = append(pairs, pair.Name+"="+pair.Value)
+		}
+		identity = strings.Join(pairs, "\xff")
+	}
+	sum := sha256.Sum256([]byte(identity))
+	alert.Fingerprint = hex.EncodeToString(sum[:8])
+
+	if alert.StartsAt, err = in.evalTime(in.startsAt, item); err != nil {
+		return alert, errors.Wrap(err, "starts_at")
+	}
+	if alert.EndsAt, err = in.evalTime(in.endsAt, item); err != nil {
+		return alert, errors.Wrap(err, "ends_at")
+	}
+	if alert.GeneratorURL, err = in.eval(in.generatorURL, item); err != nil {
+		return alert, errors.Wrap(err, "generator_url")
+	}
+	return alert, nil
+}
+
+// eval evaluates the expression against the item, returning an empty string for unset expressions and missing
+// values.
+func (in *Input) eval(e *expression, item interface{}) (string, error) {
+	if e == nil {
+		return "", nil
+	}
+	if e.path == nil {
+		s, err := in.tmpl.ExecuteStrict(e.text, item)
+		if template.IsMissingKey(err) {
+			// Templates using keys missing from the JSON object render empty, as JSONPath expressions do.
+			return "", nil
+		}
+		return s, err
+	}
+	switch v := e.path.lookup(item).(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+}
+
+// evalTime evaluates the expression into a time, given in RFC 3339 format or as seconds since the Unix epoch.
+func (in *Input) evalTime(e *expression, item interface{}) (time.Time, error) {
+	s, err := in.eval(e, item)
+	if err != nil || s == "" {
+		return time.Time{}, err
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	seconds, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return time.Time{}, errors.Errorf("%q is neither an RFC 3339 time nor a Unix timestamp", s)
+	}
+	whole := int64(seconds)
+	return time.Unix(whole, int64((seconds-float64(whole))*float64(time.Second))).UTC(), nil
+}
@@ -0,0 +1,189 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package input
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stakater/alert-az-do/pkg/alertmanager"
+	"github.com/stakater/alert-az-do/pkg/config"
+	"github.com/stakater/alert-az-do/pkg/template"
+	"github.com/stretchr/testify/require"
+)
+
+func ciInput() *config.InputConfig {
+	return &config.InputConfig{
+		Name:        "ci",
+		Receiver:    "ci-failures",
+		Status:      `{{ if eq .result "success" }}resolved{{ else }}firing{{ end }}`,
+		Fingerprint: "$.pipeline.id",
+		Labels: map[string]string{
+			"alertname": "$.pipeline.name",
+			"branch":    "$.branch",
+			"team":      "platform",
+		},
+		Annotations: map[string]string{
+			"summary": "Pipeline {{ .pipeline.name }} {{ .result }} on {{ .branch }}",
+			"steps":   "$.steps",
+		},
+		StartsAt:     "$.started",
+		EndsAt:       "$.finished",
+		GeneratorURL: "$.url",
+	}
+}
+
+func TestInput_ToData(t *testing.T) {
+	in, err := New(ciInput(), template.SimpleTemplate())
+	require.NoError(t, err)
+	require.Equal(t, "ci-failures", in.Receiver())
+
+	doc := `{"pipeline": {"id": 42, "name": "build"}, "branch": "main", "result": "failed",
+		"steps": ["lint", "test"], "started": "2025-01-02T03:04:05Z", "finished": 1735787400, "url": "https://ci/42"}`
+	data, err := in.ToData(strings.NewReader(doc))
+	require.NoError(t, err)
+
+	require.Equal(t, "ci-failures", data.Receiver)
+	require.Equal(t, alertmanager.AlertFiring, data.Status)
+	require.Equal(t, alertmanager.KV{"alertname": "build"}, data.GroupLabels)
+	require.Equal(t, `{}/ci-failures:{alertname="build"}`, data.GroupKey)
+	require.Len(t, data.Alerts, 1)
+
+	alert := data.Alerts[0]
+	require.Equal(t, alertmanager.AlertFiring, alert.Status)
+	require.Equal(t, alertmanager.KV{"alertname": "build", "branch": "main", "team": "platform"}, alert.Labels)
+	require.Equal(t, alertmanager.KV{"summary": "Pipeline build failed on main", "steps": `["lint","test"]`}, alert.Annotations)
+	require.Equal(t, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), alert.StartsAt)
+	require.Equal(t, time.Unix(1735787400, 0).UTC(), alert.EndsAt)
+	require.Equal(t, "https://ci/42", alert.GeneratorURL)
+	require.Len(t, alert.Fingerprint, 16)
+	require.Equal(t, alert.Labels, data.CommonLabels)
+
+	// The same identity resolves the same alert, whatever the other fields.
+	resolved, err := in.ToData(strings.NewReader(`{"pipeline": {"id": 42, "name": "build"}, "branch": "main", "result": "success"}`))
+	require.NoError(t, err)
+	require.Equal(t, alertmanager.AlertResolved, resolved.Status)
+	require.Equal(t, alert.Fingerprint, resolved.Alerts[0].Fingerprint)
+}
+
+func TestInput_ToData_MissingKeys(t *testing.T) {
+	conf := ciInput()
+	conf.Labels["owner"] = "{{ .owner }}"
+	conf.Annotations["summary"] = "Pipeline {{ .pipeline.name }} owned by {{ .owner }}"
+	conf.GeneratorURL = "{{ .url }}"
+	in, err := New(conf, template.SimpleTemplate())
+	require.NoError(t, err)
+
+	// Templates using missing keys render empty, and empty labels and annotations are left out.
+	data, err := in.ToData(strings.NewReader(`{"pipeline": {"id": 42, "name": "build"}, "branch": "main", "result": "failed"}`))
+	require.NoError(t, err)
+	alert := data.Alerts[0]
+	require.NotContains(t, alert.Labels, "owner")
+	require.NotContains(t, alert.Annotations, "summary")
+	require.Empty(t, alert.GeneratorURL)
+
+	data, err = in.ToData(strings.NewReader(`{"pipeline": {"id": 42, "name": "build"}, "branch": "main", "result": "failed", "owner": "ci"}`))
+	require.NoError(t, err)
+	require.Equal(t, "ci", data.Alerts[0].Labels["owner"])
+	require.Equal(t, "Pipeline build owned by ci", data.Alerts[0].Annotations["summary"])
+
+	// Other template errors are reported.
+	conf.Annotations["summary"] = `{{ template "undefined" . }}`
+	in, err = New(conf, template.SimpleTemplate())
+	require.NoError(t, err)
+	_, err = in.ToData(strings.NewReader(`{"pipeline": {"id": 42, "name": "build"}, "branch": "main", "result": "failed"}`))
+	require.Error(t, err)
+}
+
+func TestInput_ToData_AlertsArray(t *testing.T) {
+	conf := &config.InputConfig{
+		Name:     "monitor",
+		Receiver: "ops",
+		Alerts:   "$.checks",
+		Status:   "$.state",
+		Labels: map[string]string{
+			"alertname": "$.name",
+			"host":      "$.host",
+		},
+		GroupBy: []string{"host"},
+	}
+	in, err := New(conf, template.SimpleTemplate())
+	require.NoError(t, err)
+
+	data, err := in.ToData(strings.NewReader(`{"checks": [
+		{"name": "disk", "host": "db1", "state": "FIRING"},
+		{"name": "load", "host": "db1", "state": "resolved"}
+	]}`))
+	require.NoError(t, err)
+	require.Equal(t, alertmanager.AlertFiring, data.Status)
+	require.Len(t, data.Alerts, 2)
+	require.Equal(t, alertmanager.AlertFiring, data.Alerts[0].Status)
+	require.Equal(t, alertmanager.AlertResolved, data.Alerts[1].Status)
+	require.Equal(t, alertmanager.KV{"host": "db1"}, data.GroupLabels)
+	require.Equal(t, alertmanager.KV{"host": "db1"}, data.CommonLabels)
+	// Without a fingerprint expression, the fingerprint is derived from the labels.
+	require.NotEqual(t, data.Alerts[0].Fingerprint, data.Alerts[1].Fingerprint)
+}
+
+func TestInput_ToData_Errors(t *testing.T) {
+	in, err := New(ciInput(), template.SimpleTemplate())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		doc  string
+		err  string
+	}{
+		{name: "invalid JSON", doc: `{`, err: "decode document"},
+		{name: "invalid time", doc: `{"pipeline": {"name": "build"}, "started": "yesterday"}`, err: "starts_at"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := in.ToData(strings.NewReader(tt.doc))
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+
+	conf := ciInput()
+	conf.Status = "$.state"
+	in, err = New(conf, template.SimpleTemplate())
+	require.NoError(t, err)
+	_, err = in.ToData(strings.NewReader(`{"pipeline": {"name": "build"}, "state": "pending"}`))
+	require.ErrorContains(t, err, `"pending" is neither "firing" nor "resolved"`)
+
+	conf = ciInput()
+	delete(conf.Labels, "team")
+	in, err = New(conf, template.SimpleTemplate())
+	require.NoError(t, err)
+	_, err = in.ToData(strings.NewReader(`{"pipeline": {}}`))
+	require.ErrorContains(t, err, "all labels are empty")
+
+	conf = ciInput()
+	conf.Alerts = "$.checks"
+	in, err = New(conf, template.SimpleTemplate())
+	require.NoError(t, err)
+	_, err = in.ToData(strings.NewReader(`{"checks": {}}`))
+	require.ErrorContains(t, err, "does not select an array")
+	_, err = in.ToData(strings.NewReader(`{"checks": []}`))
+	require.ErrorContains(t, err, "no alerts in document")
+}
+
+func TestNew_InvalidPath(t *testing.T) {
+	conf := ciInput()
+	conf.Labels["alertname"] = "$.pipeline[name"
+	_, err := New(conf, template.SimpleTemplate())
+	require.ErrorContains(t, err, "labels.alertname")
+}
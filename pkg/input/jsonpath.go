@@ -0,0 +1,98 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package input
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// path is a compiled JSONPath expression. Only the subset selecting a single value is supported: the root "$",
+// child members in dot (".name") or bracket ("['name']") notation and array indices ("[0]", "[-1]" for the last
+// element).
+type path []interface{}
+
+// compilePath compiles the JSONPath expression into its member names (strings) and array indices (ints).
+func compilePath(expr string) (path, error) {
+	if !strings.HasPrefix(expr, "$") {
+		return nil, errors.Errorf("JSONPath %q must start with '$'", expr)
+	}
+	p := path{}
+	rest := expr[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, errors.Errorf("JSONPath %q: empty member name", expr)
+			}
+			p = append(p, rest[:end])
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, errors.Errorf("JSONPath %q: unterminated bracket", expr)
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				p = append(p, inner[1:len(inner)-1])
+				continue
+			}
+			index, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, errors.Errorf("JSONPath %q: unsupported selector [%s]", expr, inner)
+			}
+			p = append(p, index)
+		default:
+			return nil, errors.Errorf("JSONPath %q: unexpected %q", expr, rest[0])
+		}
+	}
+	return p, nil
+}
+
+// lookup returns the value selected in the document, or nil when it does not exist.
+func (p path) lookup(doc interface{}) interface{} {
+	v := doc
+	for _, step := range p {
+		switch step := step.(type) {
+		case string:
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			v = m[step]
+		case int:
+			a, ok := v.([]interface{})
+			if !ok {
+				return nil
+			}
+			if step < 0 {
+				step += len(a)
+			}
+			if step < 0 || step >= len(a) {
+				return nil
+			}
+			v = a[step]
+		}
+	}
+	return v
+}
@@ -0,0 +1,76 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package input
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompilePath(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected path
+	}{
+		{expr: "$", expected: path{}},
+		{expr: "$.a", expected: path{"a"}},
+		{expr: "$.a.b", expected: path{"a", "b"}},
+		{expr: "$.a[0].b", expected: path{"a", 0, "b"}},
+		{expr: "$['a b'][\"c\"]", expected: path{"a b", "c"}},
+		{expr: "$.items[-1]", expected: path{"items", -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			p, err := compilePath(tt.expr)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, p)
+		})
+	}
+}
+
+func TestCompilePath_Errors(t *testing.T) {
+	for _, expr := range []string{"a.b", "$.", "$.a..b", "$[0", "$[*]", "$a"} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := compilePath(expr)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestPath_Lookup(t *testing.T) {
+	var doc interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"a": {"b": [1, {"c": "x"}, 3]}, "d": null}`), &doc))
+
+	tests := []struct {
+		expr     string
+		expected interface{}
+	}{
+		{expr: "$.a.b[1].c", expected: "x"},
+		{expr: "$.a.b[-1]", expected: float64(3)},
+		{expr: "$.a.b[3]", expected: nil},
+		{expr: "$.a.missing", expected: nil},
+		{expr: "$.a.b.c", expected: nil},
+		{expr: "$.d.e", expected: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			p, err := compilePath(tt.expr)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, p.lookup(doc))
+		})
+	}
+}
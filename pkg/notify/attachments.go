@@ -0,0 +1,138 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-kit/log/level"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/webapi"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/workitemtracking"
+	"github.com/pkg/errors"
+	"github.com/stakater/alert-az-do/pkg/alertmanager"
+)
+
+const (
+	// RelationTypeAttachedFile is the relation type of work item attachments.
+	RelationTypeAttachedFile = "AttachedFile"
+
+	// defaultPayloadFileName is the attachment file name used when attach_payload.file_name is not set.
+	defaultPayloadFileName = "alertmanager-payload.json"
+
+	// payloadAttachmentComment marks the attachments created by alert-az-do, so that only those are rotated.
+	payloadAttachmentComment = "Alertmanager payload attached by alert-az-do"
+)
+
+// attachPayload attaches the raw Alertmanager payload to the work item once it has been created or updated, so that
+// no attachment is left behind when that fails. The relations are those of the work item before the update, which
+// only adds relations, so that the indexes of the payload attachments to rotate still hold.
+func (r *Receiver) attachPayload(ctx context.Context, data *alertmanager.Data, project string, id int, relations *[]workitemtracking.WorkItemRelation) error {
+	document, err := r.payloadOperations(ctx, data, project, relations)
+	if err != nil || len(document) == 0 {
+		return err
+	}
+	if _, err := r.client.UpdateWorkItem(ctx, workitemtracking.UpdateWorkItemArgs{
+		Document: &document,
+		Id:       &id,
+		Project:  &project,
+	}); err != nil {
+		return errors.Wrap(err, "link payload attachment")
+	}
+	return nil
+}
+
+// payloadOperations uploads the raw Alertmanager payload as an attachment and returns the JSON patch operations
+// linking it to the work item. When the number of payload attachments would exceed max_attachments, operations
+// removing the oldest ones are returned as well.
+func (r *Receiver) payloadOperations(ctx context.Context, data *alertmanager.Data, project string, existing *[]workitemtracking.WorkItemRelation) ([]webapi.JsonPatchOperation, error) {
+	if r.conf.AttachPayload == nil {
+		return nil, nil
+	}
+
+	fileName := defaultPayloadFileName
+	if r.conf.AttachPayload.FileName != "" {
+		var err error
+		fileName, err = r.tmpl.Execute(r.conf.AttachPayload.FileName, data)
+		if err != nil {
+			return nil, errors.Wrap(err, "render payload file name")
+		}
+	}
+
+	payload, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal payload")
+	}
+
+	attachment, err := r.client.CreateAttachment(ctx, workitemtracking.CreateAttachmentArgs{
+		UploadStream: bytes.NewReader(payload),
+		Project:      &project,
+		FileName:     &fileName,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "upload payload attachment")
+	}
+	if attachment.Url == nil {
+		return nil, errors.New("upload payload attachment: no url returned")
+	}
+	level.Debug(r.logger).Log("msg", "payload attachment uploaded", "url", *attachment.Url, "size", len(payload))
+
+	var document []webapi.JsonPatchOperation
+
+	// Relations are returned in the order they were added, so the first payload attachments are the oldest ones.
+	// Remove them from the highest index down, as each removal shifts the indexes of the following relations.
+	if limit := r.conf.AttachPayload.MaxAttachments; limit > 0 {
+		indexes := payloadAttachmentIndexes(existing)
+		if excess := len(indexes) + 1 - limit; excess > 0 {
+			for i := excess - 1; i >= 0; i-- {
+				document = append(document, webapi.JsonPatchOperation{
+					Op:   &webapi.OperationValues.Remove,
+					Path: stringPtr(fmt.Sprintf("/relations/%d", indexes[i])),
+				})
+			}
+			level.Debug(r.logger).Log("msg", "rotating payload attachments", "removed", excess)
+		}
+	}
+
+	document = append(document, webapi.JsonPatchOperation{
+		Op:   &webapi.OperationValues.Add,
+		Path: stringPtr(relationsPath),
+		Value: workitemtracking.WorkItemRelation{
+			Rel:        stringPtr(RelationTypeAttachedFile),
+			Url:        attachment.Url,
+			Attributes: &map[string]interface{}{"comment": payloadAttachmentComment},
+		},
+	})
+	return document, nil
+}
+
+// payloadAttachmentIndexes returns the indexes of the payload attachments created by alert-az-do, oldest first.
+func payloadAttachmentIndexes(relations *[]workitemtracking.WorkItemRelation) []int {
+	if relations == nil {
+		return nil
+	}
+	var indexes []int
+	for i, relation := range *relations {
+		if relation.Rel == nil || *relation.Rel != RelationTypeAttachedFile || relation.Attributes == nil {
+			continue
+		}
+		if comment, ok := (*relation.Attributes)["comment"].(string); ok && comment == payloadAttachmentComment {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
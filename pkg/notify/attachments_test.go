@@ -0,0 +1,174 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/workitemtracking"
+	"github.com/stakater/alert-az-do/pkg/alertmanager"
+	"github.com/stakater/alert-az-do/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func testPayloadReceiver(client *mockWorkItemTrackingClient, attach *config.AttachPayload) *Receiver {
+	conf := testReceiverConfig1()
+	conf.AttachPayload = attach
+	return newTestReceiver(client, conf)
+}
+
+func testPayloadData() *alertmanager.Data {
+	return &alertmanager.Data{
+		Receiver: "test-receiver",
+		Status:   alertmanager.AlertFiring,
+		Alerts: alertmanager.Alerts{
+			{Status: alertmanager.AlertFiring, Fingerprint: "fp1", Labels: alertmanager.KV{"alertname": "TestAlert"}},
+		},
+		GroupLabels: alertmanager.KV{"alertname": "TestAlert"},
+	}
+}
+
+func payloadRelation(url string) workitemtracking.WorkItemRelation {
+	return workitemtracking.WorkItemRelation{
+		Rel:        stringPtr(RelationTypeAttachedFile),
+		Url:        stringPtr(url),
+		Attributes: &map[string]interface{}{"comment": payloadAttachmentComment},
+	}
+}
+
+func TestReceiver_Notify_CreateWorkItemWithPayload(t *testing.T) {
+	mockClient := newMockWorkItemTrackingClient()
+	receiver := testPayloadReceiver(mockClient, &config.AttachPayload{})
+
+	require.NoError(t, receiver.Notify(context.Background(), testPayloadData()))
+	require.Len(t, mockClient.attachCalls, 1)
+	require.Equal(t, defaultPayloadFileName, *mockClient.attachCalls[0].FileName)
+	require.Equal(t, "TestProject", *mockClient.attachCalls[0].Project)
+
+	// The uploaded content is the JSON encoded notification.
+	content, err := io.ReadAll(mockClient.attachCalls[0].UploadStream)
+	require.NoError(t, err)
+	var uploaded alertmanager.Data
+	require.NoError(t, json.Unmarshal(content, &uploaded))
+	require.Equal(t, "test-receiver", uploaded.Receiver)
+
+	// The payload is linked once the work item was created.
+	require.Len(t, mockClient.updateCalls, 1)
+	relations := *mockClient.workItems[1].Relations
+	require.Len(t, relations, 1)
+	require.Equal(t, RelationTypeAttachedFile, *relations[0].Rel)
+	require.Equal(t, "https://dev.azure.com/org/_apis/wit/attachments/1", *relations[0].Url)
+}
+
+func TestReceiver_Notify_UpdateWorkItemRotatesPayloads(t *testing.T) {
+	mockClient := newMockWorkItemTrackingClient()
+	receiver := testPayloadReceiver(mockClient, &config.AttachPayload{
+		FileName:       "{{ .GroupLabels.alertname }}.json",
+		MaxAttachments: 2,
+	})
+
+	existingWorkItem := &workitemtracking.WorkItem{
+		Id: intPtr(1),
+		Fields: &map[string]interface{}{
+			"System.Title": "[FIRING:1] TestAlert",
+			"System.Tags":  "Fingerprint:fp1",
+		},
+		Relations: &[]workitemtracking.WorkItemRelation{
+			payloadRelation("old-1"),
+			{Rel: stringPtr(RelationTypeAttachedFile), Url: stringPtr("manual")},
+			payloadRelation("old-2"),
+			payloadRelation("old-3"),
+		},
+	}
+	mockClient.workItems[1] = existingWorkItem
+	mockClient.workItemsByTag["Fingerprint:fp1"] = []*workitemtracking.WorkItem{existingWorkItem}
+
+	require.NoError(t, receiver.Notify(context.Background(), testPayloadData()))
+	require.Len(t, mockClient.attachCalls, 1)
+	require.Equal(t, "TestAlert.json", *mockClient.attachCalls[0].FileName)
+
+	var urls []string
+	for _, relation := range *existingWorkItem.Relations {
+		urls = append(urls, *relation.Url)
+	}
+	// The two oldest payloads are removed, the manually attached file is kept.
+	require.Equal(t, []string{"manual", "old-3", "https://dev.azure.com/org/_apis/wit/attachments/1"}, urls)
+}
+
+func TestReceiver_PayloadOperations_Disabled(t *testing.T) {
+	mockClient := newMockWorkItemTrackingClient()
+	receiver := testPayloadReceiver(mockClient, nil)
+
+	document, err := receiver.payloadOperations(context.Background(), testPayloadData(), "TestProject", nil)
+	require.NoError(t, err)
+	require.Empty(t, document)
+	require.Empty(t, mockClient.attachCalls)
+}
+
+func TestReceiver_PayloadOperations_ErrorPaths(t *testing.T) {
+	t.Run("upload failure", func(t *testing.T) {
+		mockClient := newMockWorkItemTrackingClient()
+		mockClient.shouldFailAttachment = true
+		receiver := testPayloadReceiver(mockClient, &config.AttachPayload{})
+
+		err := receiver.Notify(context.Background(), testPayloadData())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "upload payload attachment")
+		// The payload is uploaded once the work item was created.
+		require.Len(t, mockClient.createCalls, 1)
+		require.Empty(t, mockClient.updateCalls)
+	})
+
+	t.Run("update failure", func(t *testing.T) {
+		mockClient := newMockWorkItemTrackingClient()
+		mockClient.shouldFailUpdate = true
+		receiver := testPayloadReceiver(mockClient, &config.AttachPayload{})
+		existingWorkItem := &workitemtracking.WorkItem{
+			Id:     intPtr(1),
+			Fields: &map[string]interface{}{"System.Title": "[FIRING:1] TestAlert", "System.Tags": "Fingerprint:fp1"},
+		}
+		mockClient.workItems[1] = existingWorkItem
+		mockClient.workItemsByTag["Fingerprint:fp1"] = []*workitemtracking.WorkItem{existingWorkItem}
+
+		err := receiver.Notify(context.Background(), testPayloadData())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "update work item")
+		// No attachment is left behind.
+		require.Empty(t, mockClient.attachCalls)
+	})
+
+	t.Run("file name template error", func(t *testing.T) {
+		mockClient := newMockWorkItemTrackingClient()
+		receiver := testPayloadReceiver(mockClient, &config.AttachPayload{FileName: "{{ .Invalid }"})
+
+		_, err := receiver.payloadOperations(context.Background(), testPayloadData(), "TestProject", nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "render payload file name")
+	})
+}
+
+func TestPayloadAttachmentIndexes(t *testing.T) {
+	relations := &[]workitemtracking.WorkItemRelation{
+		payloadRelation("a"),
+		{Rel: stringPtr(RelationTypeHyperlink), Url: stringPtr("b"), Attributes: &map[string]interface{}{"comment": payloadAttachmentComment}},
+		{Rel: stringPtr(RelationTypeAttachedFile), Url: stringPtr("c")},
+		payloadRelation("d"),
+	}
+	require.Equal(t, []int{0, 3}, payloadAttachmentIndexes(relations))
+	require.Nil(t, payloadAttachmentIndexes(nil))
+}
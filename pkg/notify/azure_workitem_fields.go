@@ -213,6 +213,8 @@ var (
 
 // AllWorkItemFields contains every declared field constant. It's used to build
 // a reverse lookup map so callers can parse a string into a known AzureWorkItemField.
+// Fields are checked against the definitions fetched from Azure DevOps when they are
+// available (see checkFields); this list is the fallback when they are not.
 var AllWorkItemFields = []AzureWorkItemField{
 	// System fields
 	WorkItemFieldTitle,
@@ -0,0 +1,194 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/webapi"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/workitemtracking"
+	"github.com/pkg/errors"
+	"github.com/stakater/alert-az-do/pkg/alertmanager"
+	"github.com/stakater/alert-az-do/pkg/config"
+)
+
+const (
+	// RelationTypeRelated links two related work items.
+	RelationTypeRelated = "System.LinkTypes.Related"
+
+	// correlationTagPrefix prefixes the tag identifying work items of correlated notifications.
+	correlationTagPrefix = "Correlation:"
+
+	// defaultCorrelationComment is posted on correlated work items when no comment template is configured.
+	defaultCorrelationComment = `Correlated with {{ range $i, $id := .RelatedIDs }}{{ if $i }}, {{ end }}#{{ $id }}{{ end }}`
+)
+
+// correlationData is the data passed to the correlation comment template. It embeds the notification, so the
+// template has access to the usual fields, plus the work item being commented on and the work items it was
+// correlated with.
+type correlationData struct {
+	*alertmanager.Data
+	WorkItemID int
+	RelatedIDs []int
+}
+
+// correlationTag returns the tag shared by work items of notifications correlated by the given rule, or an empty
+// string when the notification lacks one of the rule's labels.
+func correlationTag(rule *config.Correlation, data *alertmanager.Data) string {
+	labels := append([]string(nil), rule.Labels...)
+	sort.Strings(labels)
+
+	h := fnv.New64a()
+	for _, name := range labels {
+		value, ok := data.CommonLabels[name]
+		if !ok || value == "" {
+			return ""
+		}
+		_, _ = h.Write([]byte(name))
+		_, _ = h.Write([]byte{0xff})
+		_, _ = h.Write([]byte(value))
+		_, _ = h.Write([]byte{0xff})
+	}
+	return fmt.Sprintf("%s%016x", correlationTagPrefix, h.Sum64())
+}
+
+// correlationTags returns the correlation tags of the notification for all configured rules.
+func (r *Receiver) correlationTags(data *alertmanager.Data) []string {
+	var tags []string
+	for _, rule := range r.conf.Correlations {
+		if tag := correlationTag(rule, data); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// correlate links a newly created work item to the work items of correlated notifications created within the
+// configured window, and comments on all of them.
+func (r *Receiver) correlate(ctx context.Context, data *alertmanager.Data, workItem *workitemtracking.WorkItem) error {
+	if len(r.conf.Correlations) == 0 {
+		return nil
+	}
+
+	related := map[int]bool{}
+	var relatedIDs []int
+	commentTemplate := ""
+	for _, rule := range r.conf.Correlations {
+		tag := correlationTag(rule, data)
+		if tag == "" {
+			continue
+		}
+		since := time.Now().UTC().Add(-*rule.Window).Format(time.RFC3339)
+		wiql := fmt.Sprintf("SELECT [%s] FROM WorkItems WHERE [%s] CONTAINS '%s'%s AND [%s] >= '%s' AND [%s] <> %d",
+			WorkItemFieldId.String(),
+			WorkItemFieldTags.String(),
+			tag,
+			r.projectCondition(),
+			WorkItemFieldCreatedDate.String(),
+			since,
+			WorkItemFieldId.String(),
+			*workItem.Id)
+		timePrecision := true
+		queryResult, err := r.client.QueryByWiql(ctx, workitemtracking.QueryByWiqlArgs{
+			Wiql:          &workitemtracking.Wiql{Query: &wiql},
+			TimePrecision: &timePrecision,
+		})
+		if err != nil {
+			return errors.Wrap(err, "query correlated work items")
+		}
+		for _, ref := range *queryResult.WorkItems {
+			if ref.Id == nil || *ref.Id == *workItem.Id || related[*ref.Id] {
+				continue
+			}
+			related[*ref.Id] = true
+			relatedIDs = append(relatedIDs, *ref.Id)
+			// The comment of the first rule that correlated the work item is used.
+			if commentTemplate == "" {
+				commentTemplate = rule.Comment
+				if commentTemplate == "" {
+					commentTemplate = defaultCorrelationComment
+				}
+			}
+		}
+	}
+	if len(relatedIDs) == 0 {
+		return nil
+	}
+	sort.Ints(relatedIDs)
+
+	relatedItems, err := r.getWorkItems(ctx, workitemtracking.GetWorkItemsArgs{
+		Ids:    &relatedIDs,
+		Fields: &[]string{WorkItemFieldTeamProject.String()},
+	})
+	if err != nil {
+		return errors.Wrap(err, "get correlated work items")
+	}
+
+	var document []webapi.JsonPatchOperation
+	for _, item := range relatedItems {
+		if item.Url == nil || hasRelation(workItem.Relations, RelationTypeRelated, *item.Url) {
+			continue
+		}
+		document = append(document, webapi.JsonPatchOperation{
+			Op:   &webapi.OperationValues.Add,
+			Path: stringPtr(relationsPath),
+			Value: workitemtracking.WorkItemRelation{
+				Rel: stringPtr(RelationTypeRelated),
+				Url: item.Url,
+			},
+		})
+	}
+	if len(document) > 0 {
+		if _, err := r.client.UpdateWorkItem(ctx, workitemtracking.UpdateWorkItemArgs{
+			Document: &document,
+			Id:       workItem.Id,
+		}); err != nil {
+			return errors.Wrap(err, "link correlated work items")
+		}
+	}
+	level.Info(r.logger).Log("msg", "work item correlated", "id", *workItem.Id, "related", fmt.Sprint(relatedIDs))
+
+	project, _ := (*workItem.Fields)[WorkItemFieldTeamProject.String()].(string)
+	if err := r.correlationComment(ctx, commentTemplate, data, project, *workItem.Id, relatedIDs); err != nil {
+		return err
+	}
+	for _, item := range relatedItems {
+		if item.Id == nil || item.Fields == nil {
+			continue
+		}
+		itemProject, _ := (*item.Fields)[WorkItemFieldTeamProject.String()].(string)
+		if err := r.correlationComment(ctx, commentTemplate, data, itemProject, *item.Id, []int{*workItem.Id}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Receiver) correlationComment(ctx context.Context, commentTemplate string, data *alertmanager.Data, project string, id int, relatedIDs []int) error {
+	text, err := r.tmpl.Execute(commentTemplate, &correlationData{Data: data, WorkItemID: id, RelatedIDs: relatedIDs})
+	if err != nil {
+		return errors.Wrap(err, "render correlation comment")
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	return r.postComment(ctx, project, id, text)
+}
@@ -0,0 +1,150 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stakater/alert-az-do/pkg/alertmanager"
+	"github.com/stakater/alert-az-do/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func testCorrelationReceiver(client *mockWorkItemTrackingClient) *Receiver {
+	window := 10 * time.Minute
+	conf := testReceiverConfig1()
+	conf.Correlations = []*config.Correlation{
+		{Labels: []string{"namespace", "cluster"}, Window: &window},
+	}
+	return newTestReceiver(client, conf)
+}
+
+func testCorrelationData(alertname, fingerprint string) *alertmanager.Data {
+	return &alertmanager.Data{
+		Status:       alertmanager.AlertFiring,
+		Alerts:       alertmanager.Alerts{{Status: alertmanager.AlertFiring, Fingerprint: fingerprint}},
+		GroupLabels:  alertmanager.KV{"alertname": alertname},
+		CommonLabels: alertmanager.KV{"alertname": alertname, "cluster": "prod", "namespace": "shop"},
+	}
+}
+
+func TestCorrelationTag(t *testing.T) {
+	window := time.Minute
+	rule := &config.Correlation{Labels: []string{"namespace", "cluster"}, Window: &window}
+
+	tag := correlationTag(rule, testCorrelationData("A", "fp1"))
+	require.Regexp(t, `^Correlation:[0-9a-f]{16}$`, tag)
+
+	// The tag only depends on the correlated labels, not on their order or on other labels.
+	reordered := &config.Correlation{Labels: []string{"cluster", "namespace"}, Window: &window}
+	require.Equal(t, tag, correlationTag(reordered, testCorrelationData("B", "fp2")))
+
+	other := testCorrelationData("A", "fp1")
+	other.CommonLabels["namespace"] = "billing"
+	require.NotEqual(t, tag, correlationTag(rule, other))
+
+	missing := testCorrelationData("A", "fp1")
+	delete(missing.CommonLabels, "cluster")
+	require.Empty(t, correlationTag(rule, missing))
+}
+
+func TestReceiver_Notify_CorrelatesWorkItems(t *testing.T) {
+	mockClient := newMockWorkItemTrackingClient()
+	receiver := testCorrelationReceiver(mockClient)
+	ctx := context.Background()
+
+	require.NoError(t, receiver.Notify(ctx, testCorrelationData("NodeDown", "fp1")))
+	require.Empty(t, mockClient.commentCalls)
+
+	require.NoError(t, receiver.Notify(ctx, testCorrelationData("KubePodCrashLooping", "fp2")))
+
+	second := mockClient.workItems[2]
+	require.Contains(t, (*second.Fields)["System.Tags"], "Correlation:")
+	require.Len(t, relationsOfType(second.Relations, RelationTypeRelated), 1)
+	require.Equal(t, *mockClient.workItems[1].Url, *relationsOfType(second.Relations, RelationTypeRelated)[0].Url)
+
+	require.Len(t, mockClient.commentCalls, 2)
+	require.Equal(t, 2, *mockClient.commentCalls[0].WorkItemId)
+	require.Equal(t, "Correlated with #1", *mockClient.commentCalls[0].Request.Text)
+	require.Equal(t, 1, *mockClient.commentCalls[1].WorkItemId)
+	require.Equal(t, "Correlated with #2", *mockClient.commentCalls[1].Request.Text)
+
+	require.Contains(t, mockClient.queryCalls[len(mockClient.queryCalls)-1], "[System.CreatedDate] >= ")
+	require.Contains(t, mockClient.queryCalls[len(mockClient.queryCalls)-1], receiver.projectCondition())
+}
+
+func TestReceiver_Notify_CorrelationCustomComment(t *testing.T) {
+	mockClient := newMockWorkItemTrackingClient()
+	receiver := testCorrelationReceiver(mockClient)
+	receiver.conf.Correlations[0].Comment = `{{ .GroupLabels.alertname }} on #{{ .WorkItemID }} relates to {{ .RelatedIDs }}`
+	ctx := context.Background()
+
+	require.NoError(t, receiver.Notify(ctx, testCorrelationData("NodeDown", "fp1")))
+	require.NoError(t, receiver.Notify(ctx, testCorrelationData("SLOBurn", "fp2")))
+
+	require.Len(t, mockClient.commentCalls, 2)
+	require.Equal(t, "SLOBurn on #2 relates to [1]", *mockClient.commentCalls[0].Request.Text)
+	require.Equal(t, "SLOBurn on #1 relates to [2]", *mockClient.commentCalls[1].Request.Text)
+}
+
+func TestReceiver_Notify_UpdateKeepsCorrelationTags(t *testing.T) {
+	mockClient := newMockWorkItemTrackingClient()
+	receiver := testCorrelationReceiver(mockClient)
+	ctx := context.Background()
+
+	require.NoError(t, receiver.Notify(ctx, testCorrelationData("NodeDown", "fp1")))
+	tag := correlationTag(receiver.conf.Correlations[0], testCorrelationData("NodeDown", "fp1"))
+
+	data := testCorrelationData("NodeDown", "fp1")
+	data.Alerts = append(data.Alerts, alertmanager.Alert{Status: alertmanager.AlertFiring, Fingerprint: "fp3"})
+	require.NoError(t, receiver.Notify(ctx, data))
+
+	require.Len(t, mockClient.updateCalls, 1)
+	require.Equal(t, "Fingerprint:fp1; Fingerprint:fp3; "+tag, (*mockClient.workItems[1].Fields)["System.Tags"])
+}
+
+func TestReceiver_Correlate_ErrorPaths(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("comment failure", func(t *testing.T) {
+		mockClient := newMockWorkItemTrackingClient()
+		receiver := testCorrelationReceiver(mockClient)
+		require.NoError(t, receiver.Notify(ctx, testCorrelationData("NodeDown", "fp1")))
+
+		mockClient.shouldFailAddComment = true
+		err := receiver.Notify(ctx, testCorrelationData("SLOBurn", "fp2"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "correlate work item")
+	})
+
+	t.Run("comment template error", func(t *testing.T) {
+		mockClient := newMockWorkItemTrackingClient()
+		receiver := testCorrelationReceiver(mockClient)
+		receiver.conf.Correlations[0].Comment = "{{ .Invalid }"
+		require.NoError(t, receiver.Notify(ctx, testCorrelationData("NodeDown", "fp1")))
+
+		err := receiver.Notify(ctx, testCorrelationData("SLOBurn", "fp2"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "render correlation comment")
+	})
+}
+
+func TestMergeTags(t *testing.T) {
+	require.Equal(t, "Fingerprint:new; Parent:x; manual", mergeTags("Fingerprint:old; Parent:x;manual; ", []string{"Fingerprint:new"}))
+	require.Equal(t, "Fingerprint:new", mergeTags(nil, []string{"Fingerprint:new"}))
+	require.Equal(t, "", mergeTags("Fingerprint:old", nil))
+}
@@ -0,0 +1,309 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/webapi"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/workitemtracking"
+	"github.com/pkg/errors"
+	"github.com/stakater/alert-az-do/pkg/alertmanager"
+)
+
+const (
+	// receiverTagPrefix prefixes the tag identifying the receiver that created a work item.
+	receiverTagPrefix = "Receiver:"
+
+	// defaultSLAState is the initial state of work items when escalation.sla.state is not set.
+	defaultSLAState = "New"
+)
+
+// priorityChange describes a priority raised by an escalation.
+type priorityChange struct {
+	From   int
+	To     int
+	Reason string
+}
+
+// receiverTag returns the tag identifying the work items created by the receiver.
+func (r *Receiver) receiverTag() string {
+	return receiverTagPrefix + strings.NewReplacer(";", "_", ",", "_").Replace(r.conf.Name)
+}
+
+// projectCondition returns the WIQL condition restricting a query of the work items of the receiver to its project,
+// so that the targets of a receiver in a single organization do not see each other's work items. Templated projects
+// are only known on a notification, and are not restricted.
+func (r *Receiver) projectCondition() string {
+	if r.conf.TemplatedProject() {
+		return ""
+	}
+	return fmt.Sprintf(" AND [%s] = '%s'", WorkItemFieldTeamProject.String(), r.conf.Project)
+}
+
+// receiverTags returns the tags identifying the receiver, added to new work items when a feature needs to look up
+// all work items of the receiver.
+func (r *Receiver) receiverTags() []string {
+	if (r.conf.Escalation != nil && r.conf.Escalation.SLA != nil) || r.conf.Silence != nil || r.conf.Reconcile != nil {
+		return []string{r.receiverTag()}
+	}
+	return nil
+}
+
+// escalatedPriority returns the highest priority mapped from the escalation label of the firing alerts, and the
+// label value that called for it.
+func (r *Receiver) escalatedPriority(data *alertmanager.Data) (int, string, bool) {
+	e := r.conf.Escalation
+	if e == nil || e.Label == "" {
+		return 0, "", false
+	}
+	var (
+		priority int
+		reason   string
+		found    bool
+	)
+	for _, alert := range data.Alerts.Firing() {
+		value := alert.Labels[e.Label]
+		p, ok := e.Priorities[value]
+		if !ok {
+			continue
+		}
+		if !found || p < priority {
+			priority, reason, found = p, fmt.Sprintf("%s=%s", e.Label, value), true
+		}
+	}
+	return priority, reason, found
+}
+
+// escalateDocument applies the escalation rules to the priority operations of the document. The configured priority
+// is raised to the one mapped from the alerts' labels, if higher. When the work item already exists (current is not
+// nil) the priority is only ever raised, never lowered. The returned change is nil unless an existing work item's
+// priority is raised.
+func (r *Receiver) escalateDocument(data *alertmanager.Data, document []webapi.JsonPatchOperation, current map[string]interface{}) ([]webapi.JsonPatchOperation, *priorityChange) {
+	if r.conf.Escalation == nil {
+		return document, nil
+	}
+	if current != nil && len(data.Alerts.Firing()) == 0 {
+		// Resolved notifications never escalate, and must not lower the priority either.
+		return withoutPriority(document), nil
+	}
+
+	var (
+		priority int
+		found    bool
+		reason   = "configured priority"
+		res      = withoutPriority(document)
+	)
+	for _, op := range document {
+		if op.Path == nil || *op.Path != WorkItemFieldPriority.FieldPath() {
+			continue
+		}
+		if p, ok := priorityOf(op.Value); ok && (!found || p < priority) {
+			priority, found = p, true
+		}
+	}
+	if p, why, ok := r.escalatedPriority(data); ok && (!found || p < priority) {
+		priority, reason, found = p, why, true
+	}
+	if !found {
+		return res, nil
+	}
+
+	if current == nil {
+		return append(res, webapi.JsonPatchOperation{
+			Op:    &webapi.OperationValues.Add,
+			Path:  stringPtr(WorkItemFieldPriority.FieldPath()),
+			Value: strconv.Itoa(priority),
+		}), nil
+	}
+
+	currentPriority, ok := priorityOf(current[WorkItemFieldPriority.String()])
+	if ok && priority >= currentPriority {
+		// Never lower the priority, which may have been raised by hand or by an earlier escalation.
+		return res, nil
+	}
+	res = append(res, webapi.JsonPatchOperation{
+		Op:    &webapi.OperationValues.Replace,
+		Path:  stringPtr(WorkItemFieldPriority.FieldPath()),
+		Value: strconv.Itoa(priority),
+	})
+	if !ok {
+		return res, nil
+	}
+	return res, &priorityChange{From: currentPriority, To: priority, Reason: reason}
+}
+
+// EscalateOverdue escalates the work items of the receiver that stayed in their initial state for longer than the
+// SLA: their priority is raised and/or they are reassigned, and a comment explains why. Work items that were
+// already escalated are left alone.
+func (r *Receiver) EscalateOverdue(ctx context.Context) error {
+	if r.conf.Escalation == nil || r.conf.Escalation.SLA == nil {
+		return nil
+	}
+	sla := r.conf.Escalation.SLA
+	state := sla.State
+	if state == "" {
+		state = defaultSLAState
+	}
+
+	wiql := fmt.Sprintf("SELECT [%s] FROM WorkItems WHERE [%s] CONTAINS '%s'%s AND [%s] = '%s' AND [%s] < '%s'",
+		WorkItemFieldId.String(),
+		WorkItemFieldTags.String(),
+		r.receiverTag(),
+		r.projectCondition(),
+		WorkItemFieldState.String(),
+		state,
+		WorkItemFieldCreatedDate.String(),
+		time.Now().UTC().Add(-*sla.After).Format(time.RFC3339))
+	timePrecision := true
+	queryResult, err := r.client.QueryByWiql(ctx, workitemtracking.QueryByWiqlArgs{
+		Wiql:          &workitemtracking.Wiql{Query: &wiql},
+		TimePrecision: &timePrecision,
+	})
+	if err != nil {
+		return errors.Wrap(err, "query overdue work items")
+	}
+	if queryResult.WorkItems == nil || len(*queryResult.WorkItems) == 0 {
+		return nil
+	}
+
+	var ids []int
+	for _, ref := range *queryResult.WorkItems {
+		if ref.Id != nil {
+			ids = append(ids, *ref.Id)
+		}
+	}
+	workItems, err := r.getWorkItems(ctx, workitemtracking.GetWorkItemsArgs{
+		Ids: &ids,
+		Fields: &[]string{
+			WorkItemFieldPriority.String(),
+			WorkItemFieldAssignedTo.String(),
+			WorkItemFieldTeamProject.String(),
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "get overdue work items")
+	}
+
+	failed := 0
+	for _, workItem := range workItems {
+		if err := r.escalateOverdue(ctx, state, &workItem); err != nil {
+			level.Error(r.logger).Log("msg", "failed to escalate overdue work item", "id", workItem.Id, "err", err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return errors.Errorf("failed to escalate %d of %d overdue work items", failed, len(workItems))
+	}
+	return nil
+}
+
+func (r *Receiver) escalateOverdue(ctx context.Context, state string, workItem *workitemtracking.WorkItem) error {
+	if workItem.Id == nil || workItem.Fields == nil {
+		return nil
+	}
+	sla := r.conf.Escalation.SLA
+	fields := *workItem.Fields
+
+	var (
+		document []webapi.JsonPatchOperation
+		actions  []string
+	)
+	if sla.Priority > 0 {
+		current, ok := priorityOf(fields[WorkItemFieldPriority.String()])
+		if !ok || current > sla.Priority {
+			document = append(document, webapi.JsonPatchOperation{
+				Op:    &webapi.OperationValues.Replace,
+				Path:  stringPtr(WorkItemFieldPriority.FieldPath()),
+				Value: strconv.Itoa(sla.Priority),
+			})
+			if ok {
+				actions = append(actions, fmt.Sprintf("priority raised from %d to %d", current, sla.Priority))
+			} else {
+				actions = append(actions, fmt.Sprintf("priority set to %d", sla.Priority))
+			}
+		}
+	}
+	if sla.AssignTo != "" && !strings.EqualFold(assignee(fields[WorkItemFieldAssignedTo.String()]), sla.AssignTo) {
+		document = append(document, webapi.JsonPatchOperation{
+			Op:    &webapi.OperationValues.Replace,
+			Path:  stringPtr(WorkItemFieldAssignedTo.FieldPath()),
+			Value: sla.AssignTo,
+		})
+		actions = append(actions, fmt.Sprintf("assigned to %s", sla.AssignTo))
+	}
+	if len(document) == 0 {
+		return nil
+	}
+
+	if _, err := r.client.UpdateWorkItem(ctx, workitemtracking.UpdateWorkItemArgs{
+		Document: &document,
+		Id:       workItem.Id,
+	}); err != nil {
+		return errors.Wrap(err, "update work item")
+	}
+	level.Info(r.logger).Log("msg", "overdue work item escalated", "id", *workItem.Id, "actions", strings.Join(actions, ", "))
+
+	project, _ := fields[WorkItemFieldTeamProject.String()].(string)
+	comment := fmt.Sprintf("Escalated: the work item has been in state %s for more than %s; %s.", state, sla.After, strings.Join(actions, ", "))
+	return r.postComment(ctx, project, *workItem.Id, comment)
+}
+
+// withoutPriority returns the document without its priority operations.
+func withoutPriority(document []webapi.JsonPatchOperation) []webapi.JsonPatchOperation {
+	res := document[:0:0]
+	for _, op := range document {
+		if op.Path == nil || *op.Path != WorkItemFieldPriority.FieldPath() {
+			res = append(res, op)
+		}
+	}
+	return res
+}
+
+// priorityOf parses a work item priority, which may be a number (as returned by the API) or a string (as rendered
+// from templates).
+func priorityOf(v interface{}) (int, bool) {
+	switch p := v.(type) {
+	case int:
+		return p, true
+	case float64:
+		return int(p), true
+	case string:
+		i, err := strconv.Atoi(strings.TrimSpace(p))
+		return i, err == nil
+	}
+	return 0, false
+}
+
+// assignee returns the unique name of the identity a work item is assigned to.
+func assignee(v interface{}) string {
+	switch a := v.(type) {
+	case string:
+		return a
+	case map[string]interface{}:
+		if s, ok := a["uniqueName"].(string); ok {
+			return s
+		}
+		if s, ok := a["displayName"].(string); ok {
+			return s
+		}
+	}
+	return ""
+}
@@ -0,0 +1,212 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/workitemtracking"
+	"github.com/stakater/alert-az-do/pkg/alertmanager"
+	"github.com/stakater/alert-az-do/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func testEscalationReceiver(client *mockWorkItemTrackingClient) *Receiver {
+	after := time.Hour
+	conf := testReceiverConfig1()
+	conf.Name = "test-receiver"
+	conf.Priority = "3"
+	conf.AutoResolve = &config.AutoResolve{State: "Closed"}
+	conf.Escalation = &config.Escalation{
+		Label:      "severity",
+		Priorities: map[string]int{"critical": 1, "warning": 2},
+		SLA:        &config.SLA{After: &after, Priority: 1, AssignTo: "oncall@contoso.com"},
+	}
+	return newTestReceiver(client, conf)
+}
+
+func testEscalationData(status string, severities ...string) *alertmanager.Data {
+	data := &alertmanager.Data{
+		Status:      status,
+		GroupLabels: alertmanager.KV{"alertname": "HighLatency"},
+	}
+	for i, severity := range severities {
+		data.Alerts = append(data.Alerts, alertmanager.Alert{
+			Status:      status,
+			Fingerprint: "fp" + string(rune('1'+i)),
+			Labels:      alertmanager.KV{"alertname": "HighLatency", "severity": severity},
+		})
+	}
+	return data
+}
+
+func TestReceiver_Notify_EscalatesOnSeverity(t *testing.T) {
+	mockClient := newMockWorkItemTrackingClient()
+	receiver := testEscalationReceiver(mockClient)
+	ctx := context.Background()
+
+	require.NoError(t, receiver.Notify(ctx, testEscalationData(alertmanager.AlertFiring, "info")))
+	workItem := mockClient.workItems[1]
+	require.Equal(t, "3", (*workItem.Fields)[WorkItemFieldPriority.String()])
+	require.Contains(t, (*workItem.Fields)[WorkItemFieldTags.String()], "Receiver:test-receiver")
+
+	// A warning turning into critical within the same group raises the priority and explains why.
+	require.NoError(t, receiver.Notify(ctx, testEscalationData(alertmanager.AlertFiring, "info", "critical")))
+	require.Equal(t, "1", (*workItem.Fields)[WorkItemFieldPriority.String()])
+	require.Len(t, mockClient.commentCalls, 1)
+	require.Equal(t, "Priority raised from 3 to 1 (severity=critical).", *mockClient.commentCalls[0].Request.Text)
+
+	// The priority is never lowered again, neither by less severe alerts nor on resolution.
+	require.NoError(t, receiver.Notify(ctx, testEscalationData(alertmanager.AlertFiring, "warning")))
+	require.NoError(t, receiver.Notify(ctx, testEscalationData(alertmanager.AlertResolved, "warning")))
+	require.Equal(t, "1", (*workItem.Fields)[WorkItemFieldPriority.String()])
+	require.Len(t, mockClient.commentCalls, 1)
+	for _, call := range mockClient.updateCalls[1:] {
+		for _, op := range *call.args.Document {
+			require.NotEqual(t, WorkItemFieldPriority.FieldPath(), *op.Path)
+		}
+	}
+}
+
+func TestReceiver_Notify_EscalatedPriorityOnCreate(t *testing.T) {
+	mockClient := newMockWorkItemTrackingClient()
+	receiver := testEscalationReceiver(mockClient)
+
+	require.NoError(t, receiver.Notify(context.Background(), testEscalationData(alertmanager.AlertFiring, "warning", "info")))
+	require.Equal(t, "2", (*mockClient.workItems[1].Fields)[WorkItemFieldPriority.String()])
+	require.Empty(t, mockClient.commentCalls)
+}
+
+func TestReceiver_EscalateDocument_Disabled(t *testing.T) {
+	receiver := testEscalationReceiver(newMockWorkItemTrackingClient())
+	receiver.conf.Escalation = nil
+
+	document, err := receiver.generateWorkItemDocument(testEscalationData(alertmanager.AlertFiring, "critical"), false)
+	require.NoError(t, err)
+	escalated, change := receiver.escalateDocument(testEscalationData(alertmanager.AlertFiring, "critical"), document, map[string]interface{}{WorkItemFieldPriority.String(): 1.0})
+	require.Equal(t, document, escalated)
+	require.Nil(t, change)
+}
+
+func TestReceiver_EscalateOverdue(t *testing.T) {
+	mockClient := newMockWorkItemTrackingClient()
+	receiver := testEscalationReceiver(mockClient)
+	ctx := context.Background()
+
+	require.NoError(t, receiver.Notify(ctx, testEscalationData(alertmanager.AlertFiring, "info")))
+	require.NoError(t, receiver.EscalateOverdue(ctx))
+
+	query := mockClient.queryCalls[len(mockClient.queryCalls)-1]
+	require.Contains(t, query, "[System.Tags] CONTAINS 'Receiver:test-receiver'")
+	require.Contains(t, query, "[System.TeamProject] = 'TestProject'")
+	require.Contains(t, query, "[System.State] = 'New'")
+	require.Contains(t, query, "[System.CreatedDate] < ")
+
+	workItem := mockClient.workItems[1]
+	require.Equal(t, "1", (*workItem.Fields)[WorkItemFieldPriority.String()])
+	require.Equal(t, "oncall@contoso.com", (*workItem.Fields)[WorkItemFieldAssignedTo.String()])
+	require.Len(t, mockClient.commentCalls, 1)
+	require.Equal(t, "Escalated: the work item has been in state New for more than 1h0m0s; priority raised from 3 to 1, assigned to oncall@contoso.com.", *mockClient.commentCalls[0].Request.Text)
+
+	// Already escalated work items are left alone.
+	updates := len(mockClient.updateCalls)
+	require.NoError(t, receiver.EscalateOverdue(ctx))
+	require.Len(t, mockClient.updateCalls, updates)
+	require.Len(t, mockClient.commentCalls, 1)
+}
+
+func TestReceiver_ProjectCondition(t *testing.T) {
+	receiver := testEscalationReceiver(newMockWorkItemTrackingClient())
+	require.Equal(t, " AND [System.TeamProject] = 'TestProject'", receiver.projectCondition())
+
+	// Templated projects are only known on a notification.
+	receiver.conf.Project = "{{ .CommonLabels.project }}"
+	require.Empty(t, receiver.projectCondition())
+}
+
+func TestReceiver_EscalateOverdue_Batches(t *testing.T) {
+	mockClient := newMockWorkItemTrackingClient()
+	receiver := testEscalationReceiver(mockClient)
+	ctx := context.Background()
+
+	for i := 0; i < 250; i++ {
+		data := testEscalationData(alertmanager.AlertFiring, "info")
+		data.GroupLabels = alertmanager.KV{"alertname": fmt.Sprintf("alert%03d", i)}
+		data.Alerts[0].Fingerprint = fmt.Sprintf("fp%03d", i)
+		require.NoError(t, receiver.Notify(ctx, data))
+	}
+	require.NoError(t, receiver.EscalateOverdue(ctx))
+	require.Equal(t, 2, mockClient.getWorkItemsCalls)
+	require.Len(t, mockClient.commentCalls, 250)
+}
+
+func TestReceiver_EscalateOverdue_ErrorPaths(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("disabled", func(t *testing.T) {
+		mockClient := newMockWorkItemTrackingClient()
+		receiver := testEscalationReceiver(mockClient)
+		receiver.conf.Escalation.SLA = nil
+		require.NoError(t, receiver.EscalateOverdue(ctx))
+		require.Empty(t, mockClient.queryCalls)
+	})
+
+	t.Run("query failure", func(t *testing.T) {
+		mockClient := newMockWorkItemTrackingClient()
+		mockClient.shouldFailQuery = true
+		err := testEscalationReceiver(mockClient).EscalateOverdue(ctx)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "query overdue work items")
+	})
+
+	t.Run("update failure", func(t *testing.T) {
+		mockClient := newMockWorkItemTrackingClient()
+		receiver := testEscalationReceiver(mockClient)
+		require.NoError(t, receiver.Notify(ctx, testEscalationData(alertmanager.AlertFiring, "info")))
+
+		mockClient.shouldFailUpdate = true
+		err := receiver.EscalateOverdue(ctx)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to escalate 1 of 1 overdue work items")
+	})
+}
+
+func TestPriorityOf(t *testing.T) {
+	for _, test := range []struct {
+		in    interface{}
+		out   int
+		valid bool
+	}{
+		{2, 2, true},
+		{3.0, 3, true},
+		{" 1 ", 1, true},
+		{"High", 0, false},
+		{nil, 0, false},
+	} {
+		p, ok := priorityOf(test.in)
+		require.Equal(t, test.valid, ok, "%v", test.in)
+		require.Equal(t, test.out, p, "%v", test.in)
+	}
+}
+
+func TestAssignee(t *testing.T) {
+	require.Equal(t, "a@contoso.com", assignee("a@contoso.com"))
+	require.Equal(t, "b@contoso.com", assignee(map[string]interface{}{"uniqueName": "b@contoso.com", "displayName": "B"}))
+	require.Equal(t, "C", assignee(map[string]interface{}{"displayName": "C"}))
+	require.Equal(t, "", assignee(&workitemtracking.WorkItem{}))
+}
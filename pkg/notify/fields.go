@@ -0,0 +1,219 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/webapi"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/workitemtracking"
+	"github.com/pkg/errors"
+)
+
+// fieldDefinitionsTTL is how long the field definitions of a work item type are cached before they are fetched again,
+// picking up fields added to the process.
+var fieldDefinitionsTTL = time.Hour
+
+// fieldDefinitionsCache caches the field definitions of work item types by organization, project and work item type.
+var fieldDefinitionsCache = struct {
+	sync.Mutex
+	m map[string]cachedFieldDefinitions
+}{m: map[string]cachedFieldDefinitions{}}
+
+type cachedFieldDefinitions struct {
+	fields  *fieldDefinitions
+	expires time.Time
+}
+
+// fieldDefinition is the definition of a field of a work item type.
+type fieldDefinition struct {
+	ReferenceName string
+	Name          string
+	Type          workitemtracking.FieldType
+	ReadOnly      bool
+	AllowedValues []string
+	// Suggested is set for suggested picklists, whose values are suggestions rather than the only allowed values.
+	Suggested bool
+}
+
+// fieldDefinitions are the field definitions of a work item type, by lower case reference and display name.
+type fieldDefinitions struct {
+	byReferenceName map[string]*fieldDefinition
+	byName          map[string]*fieldDefinition
+}
+
+// fieldDefinitions returns the field definitions of the work item type in the project, fetched from Azure DevOps and
+// cached for fieldDefinitionsTTL. It returns nil when the definitions are not available, leaving the fields unchecked.
+func (r *Receiver) fieldDefinitions(ctx context.Context, project, workItemType string) *fieldDefinitions {
+	key := strings.ToLower(r.conf.Organization + "/" + project + "/" + workItemType)
+	fieldDefinitionsCache.Lock()
+	cached, ok := fieldDefinitionsCache.m[key]
+	fieldDefinitionsCache.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.fields
+	}
+
+	fields, err := fetchFieldDefinitions(ctx, r.client, project, workItemType)
+	if err != nil {
+		level.Warn(r.logger).Log("msg", "failed to fetch work item field definitions, fields are not checked", "project", project, "type", workItemType, "err", err)
+		return nil
+	}
+	level.Debug(r.logger).Log("msg", "fetched work item field definitions", "project", project, "type", workItemType, "fields", len(fields.byReferenceName))
+	if len(fields.byReferenceName) == 0 {
+		fields = nil
+	}
+
+	fieldDefinitionsCache.Lock()
+	fieldDefinitionsCache.m[key] = cachedFieldDefinitions{fields: fields, expires: time.Now().Add(fieldDefinitionsTTL)}
+	fieldDefinitionsCache.Unlock()
+	return fields
+}
+
+// fetchFieldDefinitions fetches the fields of the work item type with their allowed values, and the field types from
+// the fields of the project.
+func fetchFieldDefinitions(ctx context.Context, client workitemtracking.Client, project, workItemType string) (*fieldDefinitions, error) {
+	typeFields, err := client.GetWorkItemTypeFieldsWithReferences(ctx, workitemtracking.GetWorkItemTypeFieldsWithReferencesArgs{
+		Project: &project,
+		Type:    &workItemType,
+		Expand:  &workitemtracking.WorkItemTypeFieldsExpandLevelValues.AllowedValues,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list fields of work item type")
+	}
+	projectFields, err := client.GetWorkItemFields(ctx, workitemtracking.GetWorkItemFieldsArgs{Project: &project})
+	if err != nil {
+		return nil, errors.Wrap(err, "list fields of project")
+	}
+	fieldsByReferenceName := map[string]workitemtracking.WorkItemField2{}
+	for _, f := range *projectFields {
+		if f.ReferenceName != nil {
+			fieldsByReferenceName[strings.ToLower(*f.ReferenceName)] = f
+		}
+	}
+
+	fields := &fieldDefinitions{byReferenceName: map[string]*fieldDefinition{}, byName: map[string]*fieldDefinition{}}
+	for _, f := range *typeFields {
+		if f.ReferenceName == nil {
+			continue
+		}
+		def := &fieldDefinition{ReferenceName: *f.ReferenceName}
+		if f.Name != nil {
+			def.Name = *f.Name
+		}
+		if f.AllowedValues != nil {
+			for _, v := range *f.AllowedValues {
+				def.AllowedValues = append(def.AllowedValues, fmt.Sprint(v))
+			}
+		}
+		if pf, ok := fieldsByReferenceName[strings.ToLower(def.ReferenceName)]; ok {
+			if pf.Type != nil {
+				def.Type = *pf.Type
+			}
+			def.ReadOnly = pf.ReadOnly != nil && *pf.ReadOnly
+			def.Suggested = pf.IsPicklistSuggested != nil && *pf.IsPicklistSuggested
+		}
+		fields.byReferenceName[strings.ToLower(def.ReferenceName)] = def
+		if def.Name != "" {
+			fields.byName[strings.ToLower(def.Name)] = def
+		}
+	}
+	return fields, nil
+}
+
+// field returns the definition of the field of the key: its reference name, its JSON patch path or its display name,
+// e.g. Severity for Microsoft.VSTS.Common.Severity. It returns nil for unknown fields.
+func (f *fieldDefinitions) field(key string) *fieldDefinition {
+	key = strings.ToLower(strings.TrimPrefix(key, "/fields/"))
+	if def, ok := f.byReferenceName[key]; ok {
+		return def
+	}
+	return f.byName[key]
+}
+
+// check checks that the value can be set to the field, returning the value to set: allowed values are matched
+// case-insensitively and replaced by the allowed value. Empty values clear the field and are not checked.
+func (d *fieldDefinition) check(value string) (string, error) {
+	if d.ReadOnly {
+		return "", errors.Errorf("field %s is read-only", d.ReferenceName)
+	}
+	if value == "" {
+		return value, nil
+	}
+	var err error
+	switch d.Type {
+	case workitemtracking.FieldTypeValues.Integer, workitemtracking.FieldTypeValues.PicklistInteger:
+		_, err = strconv.Atoi(strings.TrimSpace(value))
+	case workitemtracking.FieldTypeValues.Double, workitemtracking.FieldTypeValues.PicklistDouble:
+		_, err = strconv.ParseFloat(strings.TrimSpace(value), 64)
+	case workitemtracking.FieldTypeValues.Boolean:
+		_, err = strconv.ParseBool(strings.TrimSpace(value))
+	}
+	if err != nil {
+		return "", errors.Errorf("value %q of field %s is not of type %s", value, d.ReferenceName, d.Type)
+	}
+	if len(d.AllowedValues) == 0 {
+		return value, nil
+	}
+	for _, allowed := range d.AllowedValues {
+		if strings.EqualFold(strings.TrimSpace(value), allowed) {
+			return allowed, nil
+		}
+	}
+	if d.Suggested {
+		return value, nil
+	}
+	return "", errors.Errorf("value %q of field %s is not allowed (allowed values: %s)", value, d.ReferenceName, strings.Join(d.AllowedValues, ", "))
+}
+
+// checkFields checks the field operations of the document against the field definitions of the work item type,
+// before sending it: fields set by display name are replaced by their reference names, unknown fields and values that
+// are not of the type of their field or not allowed are rejected. Without field definitions, the document is returned
+// as is.
+func (r *Receiver) checkFields(ctx context.Context, project, workItemType string, document []webapi.JsonPatchOperation) ([]webapi.JsonPatchOperation, error) {
+	if workItemType == "" {
+		return document, nil
+	}
+	fields := r.fieldDefinitions(ctx, project, workItemType)
+	if fields == nil {
+		return document, nil
+	}
+	res := make([]webapi.JsonPatchOperation, 0, len(document))
+	for _, op := range document {
+		if op.Path == nil || !strings.HasPrefix(*op.Path, "/fields/") {
+			res = append(res, op)
+			continue
+		}
+		def := fields.field(*op.Path)
+		if def == nil {
+			return nil, errors.Errorf("unknown field %s of work item type %s", strings.TrimPrefix(*op.Path, "/fields/"), workItemType)
+		}
+		op.Path = stringPtr(fmt.Sprintf("/fields/%s", def.ReferenceName))
+		if value, ok := op.Value.(string); ok {
+			value, err := def.check(value)
+			if err != nil {
+				return nil, err
+			}
+			op.Value = value
+		}
+		res = append(res, op)
+	}
+	return res, nil
+}
@@ -0,0 +1,175 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"context"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/webapi"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/workitemtracking"
+	"github.com/stakater/alert-az-do/pkg/alertmanager"
+	"github.com/stretchr/testify/require"
+)
+
+// testFieldsClient returns a mock client with the field definitions of a Bug with a Severity picklist, an integer
+// priority, a suggested Custom.Team picklist and a read-only Changed Date.
+func testFieldsClient() *mockWorkItemTrackingClient {
+	client := newMockWorkItemTrackingClient()
+	field := func(referenceName, name string, fieldType workitemtracking.FieldType, allowedValues ...interface{}) {
+		client.typeFields = append(client.typeFields, workitemtracking.WorkItemTypeFieldWithReferences{
+			ReferenceName: stringPtr(referenceName),
+			Name:          stringPtr(name),
+			AllowedValues: &allowedValues,
+		})
+		client.fields = append(client.fields, workitemtracking.WorkItemField2{
+			ReferenceName:       stringPtr(referenceName),
+			Name:                stringPtr(name),
+			Type:                &fieldType,
+			ReadOnly:            boolPtr(referenceName == "System.ChangedDate"),
+			IsPicklistSuggested: boolPtr(referenceName == "Custom.Team"),
+		})
+	}
+	field("System.Title", "Title", workitemtracking.FieldTypeValues.String)
+	field("System.Description", "Description", workitemtracking.FieldTypeValues.Html)
+	field("System.Tags", "Tags", workitemtracking.FieldTypeValues.PlainText)
+	field("System.ChangedDate", "Changed Date", workitemtracking.FieldTypeValues.DateTime)
+	field("Microsoft.VSTS.Common.Priority", "Priority", workitemtracking.FieldTypeValues.Integer, float64(1), float64(2), float64(3), float64(4))
+	field("Microsoft.VSTS.Common.Severity", "Severity", workitemtracking.FieldTypeValues.String, "1 - Critical", "2 - High", "3 - Medium", "4 - Low")
+	field("Custom.Team", "Team", workitemtracking.FieldTypeValues.PicklistString, "Platform", "Payments")
+	return client
+}
+
+// resetFieldDefinitionsCache empties the field definitions cache, now and at the end of the test, so that tests do not
+// see the field definitions of each other.
+func resetFieldDefinitionsCache(t *testing.T) {
+	reset := func() {
+		fieldDefinitionsCache.Lock()
+		fieldDefinitionsCache.m = map[string]cachedFieldDefinitions{}
+		fieldDefinitionsCache.Unlock()
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+func testFieldsReceiver(t *testing.T, client *mockWorkItemTrackingClient, fields map[string]interface{}) *Receiver {
+	resetFieldDefinitionsCache(t)
+	conf := testReceiverConfig1()
+	conf.Fields = fields
+	return newTestReceiver(client, conf)
+}
+
+func testFieldsData() *alertmanager.Data {
+	return &alertmanager.Data{
+		Status:       alertmanager.AlertFiring,
+		Alerts:       alertmanager.Alerts{{Status: alertmanager.AlertFiring, Fingerprint: "fp1"}},
+		GroupLabels:  alertmanager.KV{"alertname": "HighLatency"},
+		CommonLabels: alertmanager.KV{"team": "payments", "severity": "critical"},
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestReceiver_CheckFields(t *testing.T) {
+	client := testFieldsClient()
+	receiver := testFieldsReceiver(t, client, map[string]interface{}{
+		"Severity":    `{{ if eq .CommonLabels.severity "critical" }}1 - critical{{ else }}3 - Medium{{ end }}`,
+		"custom.team": "{{ .CommonLabels.team }}",
+	})
+	receiver.conf.Priority = "2"
+
+	require.NoError(t, receiver.Notify(context.Background(), testFieldsData()))
+	require.Len(t, client.createCalls, 1)
+	fields := map[string]interface{}{}
+	for _, op := range *client.createCalls[0].args.Document {
+		fields[*op.Path] = op.Value
+	}
+	require.Equal(t, "1 - Critical", fields["/fields/Microsoft.VSTS.Common.Severity"])
+	require.Equal(t, "Payments", fields["/fields/Custom.Team"])
+	require.Equal(t, "2", fields[WorkItemFieldPriority.FieldPath()])
+	require.NotContains(t, fields, "/fields/Severity")
+
+	// The definitions are cached, by case-insensitive work item type.
+	client.typeFields = nil
+	document, err := receiver.checkFields(context.Background(), "TestProject", "bug", []webapi.JsonPatchOperation{
+		{Op: &webapi.OperationValues.Add, Path: stringPtr("/fields/Team"), Value: "platform"},
+		{Op: &webapi.OperationValues.Add, Path: stringPtr("/relations/-"), Value: map[string]interface{}{}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "/fields/Custom.Team", *document[0].Path)
+	require.Equal(t, "Platform", document[0].Value)
+	require.Equal(t, "/relations/-", *document[1].Path)
+
+	// Values of suggested picklists are not restricted to the suggestions.
+	document, err = receiver.checkFields(context.Background(), "TestProject", "Bug", []webapi.JsonPatchOperation{
+		{Op: &webapi.OperationValues.Add, Path: stringPtr("/fields/Custom.Team"), Value: "SRE"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "SRE", document[0].Value)
+}
+
+func TestReceiver_CheckFields_Errors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		fields   map[string]interface{}
+		priority string
+		err      string
+	}{
+		"unknown field": {
+			fields: map[string]interface{}{"Custom.Tema": "Platform"},
+			err:    "unknown field Custom.Tema of work item type Bug",
+		},
+		"not allowed": {
+			fields: map[string]interface{}{"Severity": "{{ .CommonLabels.severity }}"},
+			err:    `value "critical" of field Microsoft.VSTS.Common.Severity is not allowed (allowed values: 1 - Critical, 2 - High, 3 - Medium, 4 - Low)`,
+		},
+		"not an integer": {
+			priority: "P1",
+			err:      `value "P1" of field Microsoft.VSTS.Common.Priority is not of type integer`,
+		},
+		"read-only": {
+			fields: map[string]interface{}{"Changed Date": "2025-01-01"},
+			err:    "field System.ChangedDate is read-only",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			client := testFieldsClient()
+			receiver := testFieldsReceiver(t, client, tc.fields)
+			receiver.conf.Priority = tc.priority
+
+			err := receiver.Notify(context.Background(), testFieldsData())
+			require.EqualError(t, err, "check work item fields: "+tc.err)
+			require.Empty(t, client.createCalls)
+		})
+	}
+}
+
+func TestReceiver_CheckFields_Fallback(t *testing.T) {
+	// Without field definitions, fields are sent as configured.
+	client := newMockWorkItemTrackingClient()
+	receiver := testFieldsReceiver(t, client, map[string]interface{}{"Custom.Anything": "{{ .CommonLabels.severity }}"})
+
+	require.NoError(t, receiver.Notify(context.Background(), testFieldsData()))
+	require.Len(t, client.createCalls, 1)
+	var found bool
+	for _, op := range *client.createCalls[0].args.Document {
+		if *op.Path == "/fields/Custom.Anything" {
+			found = true
+			require.Equal(t, "critical", op.Value)
+		}
+	}
+	require.True(t, found)
+}
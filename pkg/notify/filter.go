@@ -0,0 +1,97 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"github.com/stakater/alert-az-do/pkg/alertmanager"
+	"github.com/stakater/alert-az-do/pkg/config"
+)
+
+// Reasons for leaving alerts out of a notification, as returned by Filter.
+const (
+	FilterNotIncluded = "not_included"
+	FilterExcluded    = "excluded"
+	FilterRelabeled   = "relabel_dropped"
+)
+
+// Filter applies the include and exclude matchers and the relabel configs of the receiver to the alerts of the
+// notification. It returns the notification to handle, nil when all alerts were left out, and the number of alerts
+// left out by reason.
+//
+// Relabeled alerts get the fingerprint of their new labels, and alerts whose labels became equal are merged, firing
+// ones taking precedence. The configuration rejects relabel configs on receivers with silences, reconciliation or
+// polling, which need the fingerprints of Alertmanager.
+func Filter(conf *config.ReceiverConfig, data *alertmanager.Data) (*alertmanager.Data, map[string]int) {
+	filtered := map[string]int{}
+	if len(conf.Include) == 0 && len(conf.Exclude) == 0 && len(conf.RelabelConfigs) == 0 {
+		return data, filtered
+	}
+
+	var alerts alertmanager.Alerts
+	index := map[string]int{}
+	for _, alert := range data.Alerts {
+		if !conf.Include.Matches(alert.Labels) {
+			filtered[FilterNotIncluded]++
+			continue
+		}
+		if conf.Exclude.MatchesAny(alert.Labels) {
+			filtered[FilterExcluded]++
+			continue
+		}
+		if len(conf.RelabelConfigs) > 0 {
+			labels := config.Relabel(alert.Labels, conf.RelabelConfigs)
+			if labels == nil {
+				filtered[FilterRelabeled]++
+				continue
+			}
+			alert.Labels = labels
+			alert.Fingerprint = alert.Labels.Fingerprint()
+		}
+
+		if i, ok := index[alert.Fingerprint]; ok {
+			if alerts[i].Status != alertmanager.AlertFiring && alert.Status == alertmanager.AlertFiring {
+				alerts[i] = alert
+			}
+			continue
+		}
+		index[alert.Fingerprint] = len(alerts)
+		alerts = append(alerts, alert)
+	}
+	if len(alerts) == 0 {
+		return nil, filtered
+	}
+
+	res := *data
+	res.Alerts = alerts
+	res.Status = alertmanager.AlertResolved
+	if len(alerts.Firing()) > 0 {
+		res.Status = alertmanager.AlertFiring
+	}
+	var labels, annotations []alertmanager.KV
+	for _, a := range alerts {
+		labels = append(labels, a.Labels)
+		annotations = append(annotations, a.Annotations)
+	}
+	res.CommonLabels = alertmanager.CommonKV(labels)
+	res.CommonAnnotations = alertmanager.CommonKV(annotations)
+	// Group labels that were rewritten or dropped follow the common labels.
+	res.GroupLabels = alertmanager.KV{}
+	for name := range data.GroupLabels {
+		if v, ok := res.CommonLabels[name]; ok {
+			res.GroupLabels[name] = v
+		}
+	}
+	return &res, filtered
+}
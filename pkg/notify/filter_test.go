@@ -0,0 +1,107 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"testing"
+
+	"github.com/stakater/alert-az-do/pkg/alertmanager"
+	"github.com/stakater/alert-az-do/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func testFilterData() *alertmanager.Data {
+	alert := func(status, pod, severity string) alertmanager.Alert {
+		labels := alertmanager.KV{"alertname": "PodCrashLooping", "namespace": "shop", "pod": pod, "severity": severity}
+		return alertmanager.Alert{Status: status, Labels: labels, Fingerprint: labels.Fingerprint()}
+	}
+	return &alertmanager.Data{
+		Status: alertmanager.AlertFiring,
+		Alerts: alertmanager.Alerts{
+			alert(alertmanager.AlertResolved, "web-1", "warning"),
+			alert(alertmanager.AlertFiring, "web-2", "warning"),
+			alert(alertmanager.AlertFiring, "web-3", "info"),
+		},
+		GroupLabels:  alertmanager.KV{"alertname": "PodCrashLooping", "namespace": "shop"},
+		CommonLabels: alertmanager.KV{"alertname": "PodCrashLooping", "namespace": "shop"},
+	}
+}
+
+func mustMatchers(t *testing.T, ss ...string) config.Matchers {
+	matchers, err := config.ParseMatchers(ss)
+	require.NoError(t, err)
+	return matchers
+}
+
+func TestReceiver_Filter_Unconfigured(t *testing.T) {
+	data := testFilterData()
+	filtered, dropped := Filter(testReceiverConfig1(), data)
+	require.Same(t, data, filtered)
+	require.Empty(t, dropped)
+}
+
+func TestReceiver_Filter_IncludeExclude(t *testing.T) {
+	conf := testReceiverConfig1()
+	conf.Include = mustMatchers(t, `namespace="shop"`, `pod=~"web-.*"`)
+	conf.Exclude = mustMatchers(t, `severity="info"`, `pod="web-1"`)
+	data := testFilterData()
+
+	filtered, dropped := Filter(conf, data)
+	require.Equal(t, map[string]int{FilterExcluded: 2}, dropped)
+	require.Len(t, filtered.Alerts, 1)
+	require.Equal(t, "web-2", filtered.Alerts[0].Labels["pod"])
+	require.Equal(t, data.Alerts[1].Fingerprint, filtered.Alerts[0].Fingerprint)
+	require.Equal(t, alertmanager.AlertFiring, filtered.Status)
+	require.Equal(t, "web-2", filtered.CommonLabels["pod"])
+	// The original notification is left untouched.
+	require.Len(t, data.Alerts, 3)
+
+	conf.Include = mustMatchers(t, `namespace="cart"`)
+	filtered, dropped = Filter(conf, data)
+	require.Nil(t, filtered)
+	require.Equal(t, map[string]int{FilterNotIncluded: 3}, dropped)
+}
+
+func TestReceiver_Filter_Relabel(t *testing.T) {
+	conf := testReceiverConfig1()
+	conf.RelabelConfigs = []*config.RelabelConfig{
+		{Action: config.RelabelLabelDrop, Regex: config.MustNewRegexp("pod")},
+		{Action: config.RelabelDrop, SourceLabels: []string{"severity"}, Separator: ";", Regex: config.MustNewRegexp("info")},
+		{Action: config.RelabelReplace, SourceLabels: []string{"namespace"}, Separator: ";", Regex: config.MustNewRegexp("(.*)"),
+			TargetLabel: "alertname", Replacement: "PodCrashLooping in $1"},
+	}
+
+	filtered, dropped := Filter(conf, testFilterData())
+	require.Equal(t, map[string]int{FilterRelabeled: 1}, dropped)
+
+	// Without the pod label, the two warnings are the same alert, and the firing one wins.
+	labels := alertmanager.KV{"alertname": "PodCrashLooping in shop", "namespace": "shop", "severity": "warning"}
+	require.Len(t, filtered.Alerts, 1)
+	require.Equal(t, labels, filtered.Alerts[0].Labels)
+	require.Equal(t, labels.Fingerprint(), filtered.Alerts[0].Fingerprint)
+	require.Equal(t, alertmanager.AlertFiring, filtered.Alerts[0].Status)
+	require.Equal(t, labels, filtered.CommonLabels)
+	// Group labels follow the rewritten labels.
+	require.Equal(t, alertmanager.KV{"alertname": "PodCrashLooping in shop", "namespace": "shop"}, filtered.GroupLabels)
+}
+
+func TestReceiver_Filter_ResolvedStatus(t *testing.T) {
+	conf := testReceiverConfig1()
+	conf.Include = mustMatchers(t, `pod="web-1"`)
+
+	filtered, _ := Filter(conf, testFilterData())
+	require.Len(t, filtered.Alerts, 1)
+	require.Equal(t, alertmanager.AlertResolved, filtered.Status)
+}
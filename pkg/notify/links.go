@@ -0,0 +1,125 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/go-kit/log/level"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/webapi"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/workitemtracking"
+	"github.com/pkg/errors"
+	"github.com/stakater/alert-az-do/pkg/alertmanager"
+)
+
+const (
+	// RelationTypeHyperlink is the relation type of plain hyperlinks.
+	RelationTypeHyperlink = "Hyperlink"
+
+	// relationsPath is the JSON patch path used to append a relation to a work item.
+	relationsPath = "/relations/-"
+)
+
+// renderLinks renders the configured links for the given alert data. Links rendering to an empty or invalid URL are
+// skipped, and every URL is only returned once.
+func (r *Receiver) renderLinks(data *alertmanager.Data) ([]workitemtracking.WorkItemRelation, error) {
+	var relations []workitemtracking.WorkItemRelation
+	seen := map[string]bool{}
+
+	add := func(urlTemplate, commentTemplate string, tmplData interface{}) error {
+		link, err := r.tmpl.Execute(urlTemplate, tmplData)
+		if err != nil {
+			return errors.Wrap(err, "render link url")
+		}
+		link = strings.TrimSpace(link)
+		if link == "" || seen[link] {
+			return nil
+		}
+		if u, err := url.Parse(link); err != nil || !u.IsAbs() {
+			level.Warn(r.logger).Log("msg", "skipping link with invalid url", "url", link)
+			return nil
+		}
+		seen[link] = true
+
+		relation := workitemtracking.WorkItemRelation{
+			Rel: stringPtr(RelationTypeHyperlink),
+			Url: stringPtr(link),
+		}
+		if commentTemplate != "" {
+			comment, err := r.tmpl.Execute(commentTemplate, tmplData)
+			if err != nil {
+				return errors.Wrap(err, "render link comment")
+			}
+			relation.Attributes = &map[string]interface{}{"comment": comment}
+		}
+		relations = append(relations, relation)
+		return nil
+	}
+
+	for _, l := range r.conf.Links {
+		if !l.PerAlert {
+			if err := add(l.URL, l.Comment, data); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		for _, a := range data.Alerts {
+			if err := add(l.URL, l.Comment, a); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return relations, nil
+}
+
+// linkOperations returns the JSON patch operations adding every configured link that is not yet present in the
+// existing relations of the work item.
+func (r *Receiver) linkOperations(data *alertmanager.Data, existing *[]workitemtracking.WorkItemRelation) ([]webapi.JsonPatchOperation, error) {
+	if len(r.conf.Links) == 0 {
+		return nil, nil
+	}
+
+	relations, err := r.renderLinks(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var document []webapi.JsonPatchOperation
+	for _, relation := range relations {
+		if hasRelation(existing, RelationTypeHyperlink, *relation.Url) {
+			continue
+		}
+		document = append(document, webapi.JsonPatchOperation{
+			Op:    &webapi.OperationValues.Add,
+			Path:  stringPtr(relationsPath),
+			Value: relation,
+		})
+	}
+	return document, nil
+}
+
+// hasRelation reports whether relations contain a relation of the given type pointing to the given URL.
+func hasRelation(relations *[]workitemtracking.WorkItemRelation, rel, target string) bool {
+	if relations == nil {
+		return false
+	}
+	for _, relation := range *relations {
+		if relation.Rel != nil && *relation.Rel == rel && relation.Url != nil && *relation.Url == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"context"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/workitemtracking"
+	"github.com/stakater/alert-az-do/pkg/alertmanager"
+	"github.com/stakater/alert-az-do/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func testLinksData() *alertmanager.Data {
+	return &alertmanager.Data{
+		ExternalURL: "http://alertmanager.example.com",
+		Status:      alertmanager.AlertFiring,
+		Alerts: alertmanager.Alerts{
+			{
+				Status:       alertmanager.AlertFiring,
+				Fingerprint:  "fp1",
+				GeneratorURL: "http://prometheus.example.com/graph?g0.expr=up",
+				Annotations:  alertmanager.KV{"runbook_url": "https://runbooks.example.com/up"},
+			},
+			{
+				Status:       alertmanager.AlertFiring,
+				Fingerprint:  "fp2",
+				GeneratorURL: "http://prometheus.example.com/graph?g0.expr=up",
+			},
+		},
+	}
+}
+
+func testLinksReceiver(client *mockWorkItemTrackingClient) *Receiver {
+	conf := testReceiverConfig1()
+	conf.Links = []*config.Link{
+		{URL: "{{ .ExternalURL }}", Comment: "Alertmanager"},
+		{URL: "{{ .GeneratorURL }}", Comment: "Prometheus ({{ .Fingerprint }})", PerAlert: true},
+		{URL: "{{ .Annotations.runbook_url }}", Comment: "Runbook", PerAlert: true},
+	}
+	return newTestReceiver(client, conf)
+}
+
+func TestReceiver_RenderLinks(t *testing.T) {
+	receiver := testLinksReceiver(newMockWorkItemTrackingClient())
+
+	relations, err := receiver.renderLinks(testLinksData())
+	require.NoError(t, err)
+
+	// The generator URL is shared by both alerts and the second alert has no runbook, so only three links remain.
+	require.Len(t, relations, 3)
+	require.Equal(t, "http://alertmanager.example.com", *relations[0].Url)
+	require.Equal(t, "Alertmanager", (*relations[0].Attributes)["comment"])
+	require.Equal(t, "http://prometheus.example.com/graph?g0.expr=up", *relations[1].Url)
+	require.Equal(t, "Prometheus (fp1)", (*relations[1].Attributes)["comment"])
+	require.Equal(t, "https://runbooks.example.com/up", *relations[2].Url)
+	for _, relation := range relations {
+		require.Equal(t, RelationTypeHyperlink, *relation.Rel)
+	}
+}
+
+func TestReceiver_RenderLinks_InvalidURL(t *testing.T) {
+	receiver := testLinksReceiver(newMockWorkItemTrackingClient())
+	receiver.conf.Links = []*config.Link{{URL: "not a url"}, {URL: "{{ .ExternalURL }}"}}
+
+	relations, err := receiver.renderLinks(testLinksData())
+	require.NoError(t, err)
+	require.Len(t, relations, 1)
+	require.Nil(t, relations[0].Attributes)
+}
+
+func TestReceiver_RenderLinks_TemplateError(t *testing.T) {
+	receiver := testLinksReceiver(newMockWorkItemTrackingClient())
+	receiver.conf.Links = []*config.Link{{URL: "{{ .Invalid }"}}
+
+	_, err := receiver.renderLinks(testLinksData())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "render link url")
+
+	receiver.conf.Links = []*config.Link{{URL: "{{ .ExternalURL }}", Comment: "{{ .Invalid }"}}
+	_, err = receiver.renderLinks(testLinksData())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "render link comment")
+}
+
+func TestReceiver_Notify_CreateWorkItemWithLinks(t *testing.T) {
+	mockClient := newMockWorkItemTrackingClient()
+	receiver := testLinksReceiver(mockClient)
+
+	require.NoError(t, receiver.Notify(context.Background(), testLinksData()))
+	require.Len(t, mockClient.createCalls, 1)
+
+	var relationOps int
+	for _, op := range *mockClient.createCalls[0].args.Document {
+		if *op.Path == "/relations/-" {
+			relationOps++
+		}
+	}
+	require.Equal(t, 3, relationOps)
+	require.Len(t, *mockClient.workItems[1].Relations, 3)
+}
+
+func TestReceiver_Notify_UpdateWorkItemAddsOnlyNewLinks(t *testing.T) {
+	mockClient := newMockWorkItemTrackingClient()
+	receiver := testLinksReceiver(mockClient)
+
+	existingWorkItem := &workitemtracking.WorkItem{
+		Id: intPtr(1),
+		Fields: &map[string]interface{}{
+			"System.Title": "[FIRING:2] Test Alert",
+			"System.Tags":  "Fingerprint:fp1",
+		},
+		Relations: &[]workitemtracking.WorkItemRelation{
+			{Rel: stringPtr(RelationTypeHyperlink), Url: stringPtr("http://alertmanager.example.com")},
+			{Rel: stringPtr("System.LinkTypes.Related"), Url: stringPtr("https://runbooks.example.com/up")},
+		},
+	}
+	mockClient.workItems[1] = existingWorkItem
+	mockClient.workItemsByTag["Fingerprint:fp1"] = []*workitemtracking.WorkItem{existingWorkItem}
+
+	require.NoError(t, receiver.Notify(context.Background(), testLinksData()))
+	require.Len(t, mockClient.updateCalls, 1)
+
+	var added []string
+	for _, op := range *mockClient.updateCalls[0].args.Document {
+		if *op.Path == "/relations/-" {
+			added = append(added, *op.Value.(workitemtracking.WorkItemRelation).Url)
+		}
+	}
+	require.Equal(t, []string{"http://prometheus.example.com/graph?g0.expr=up", "https://runbooks.example.com/up"}, added)
+
+	// A second notification must not add the links again.
+	require.NoError(t, receiver.Notify(context.Background(), testLinksData()))
+	require.Len(t, mockClient.updateCalls, 2)
+	for _, op := range *mockClient.updateCalls[1].args.Document {
+		require.NotEqual(t, "/relations/-", *op.Path)
+	}
+}
+
+func TestHasRelation(t *testing.T) {
+	relations := &[]workitemtracking.WorkItemRelation{
+		{Rel: stringPtr(RelationTypeHyperlink), Url: stringPtr("http://a")},
+		{Rel: nil, Url: stringPtr("http://b")},
+	}
+	require.True(t, hasRelation(relations, RelationTypeHyperlink, "http://a"))
+	require.False(t, hasRelation(relations, RelationTypeHyperlink, "http://b"))
+	require.False(t, hasRelation(relations, "System.LinkTypes.Related", "http://a"))
+	require.False(t, hasRelation(nil, RelationTypeHyperlink, "http://a"))
+}
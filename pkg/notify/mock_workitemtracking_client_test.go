@@ -16,7 +16,10 @@ package notify
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"strconv"
+	"strings"
 
 	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/webapi"
 	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/workitemtracking"
@@ -31,12 +34,21 @@ type mockWorkItemTrackingClient struct {
 	createCalls    []mockCreateCall
 	updateCalls    []mockUpdateCall
 	queryCalls     []string
+	attachCalls    []workitemtracking.CreateAttachmentArgs
+	commentCalls   []workitemtracking.AddWorkItemCommentArgs
+
+	getWorkItemsCalls int
+
+	// Field definitions returned for every project and work item type
+	fields     []workitemtracking.WorkItemField2
+	typeFields []workitemtracking.WorkItemTypeFieldWithReferences
 
 	// Error control flags for testing error paths
 	shouldFailCreate     bool
 	shouldFailUpdate     bool
 	shouldFailQuery      bool
 	shouldFailAddComment bool
+	shouldFailAttachment bool
 	duplicateResults     bool
 }
 
@@ -71,8 +83,11 @@ func (m *mockWorkItemTrackingClient) CreateWorkItem(ctx context.Context, args wo
 		return nil, errors.New("mock create work item failed")
 	}
 
+	id := m.nextID
+	url := fmt.Sprintf("https://dev.azure.com/org/_apis/wit/workItems/%d", id)
 	workItem := &workitemtracking.WorkItem{
-		Id:     &m.nextID,
+		Id:     &id,
+		Url:    &url,
 		Fields: &map[string]interface{}{},
 	}
 
@@ -88,8 +103,13 @@ func (m *mockWorkItemTrackingClient) CreateWorkItem(ctx context.Context, args wo
 				(*workItem.Fields)["System.Tags"] = op.Value
 				// Index by tags for querying
 				if tagValue, ok := op.Value.(string); ok {
-					m.workItemsByTag[tagValue] = append(m.workItemsByTag[tagValue], workItem)
+					for _, tag := range strings.Split(tagValue, ";") {
+						tag = strings.TrimSpace(tag)
+						m.workItemsByTag[tag] = append(m.workItemsByTag[tag], workItem)
+					}
 				}
+			case "/relations/-":
+				m.addRelation(workItem, op.Value)
 			default:
 				// Handle custom fields
 				if len(*op.Path) > 8 && (*op.Path)[:8] == "/fields/" {
@@ -132,11 +152,16 @@ func (m *mockWorkItemTrackingClient) UpdateWorkItem(ctx context.Context, args wo
 				(*workItem.Fields)["System.Description"] = op.Value
 			case "/fields/System.State":
 				(*workItem.Fields)["System.State"] = op.Value
+			case "/relations/-":
+				m.addRelation(workItem, op.Value)
 			default:
 				// Handle custom fields
 				if len(*op.Path) > 8 && (*op.Path)[:8] == "/fields/" {
 					(*workItem.Fields)[(*op.Path)[8:]] = op.Value
 				}
+				if *op.Op == webapi.OperationValues.Remove && strings.HasPrefix(*op.Path, "/relations/") {
+					m.removeRelation(workItem, strings.TrimPrefix(*op.Path, "/relations/"))
+				}
 			}
 		}
 	}
@@ -144,6 +169,41 @@ func (m *mockWorkItemTrackingClient) UpdateWorkItem(ctx context.Context, args wo
 	return workItem, nil
 }
 
+// addRelation appends a relation added through a "/relations/-" patch operation to the work item.
+func (m *mockWorkItemTrackingClient) addRelation(workItem *workitemtracking.WorkItem, value interface{}) {
+	relation, ok := value.(workitemtracking.WorkItemRelation)
+	if !ok {
+		return
+	}
+	if workItem.Relations == nil {
+		workItem.Relations = &[]workitemtracking.WorkItemRelation{}
+	}
+	*workItem.Relations = append(*workItem.Relations, relation)
+
+	// Azure DevOps maintains the reverse end of hierarchy links on the parent.
+	if relation.Rel != nil && *relation.Rel == RelationTypeHierarchyReverse {
+		parentID, err := workItemIDFromURL(*relation.Url)
+		if err != nil {
+			return
+		}
+		if parent, ok := m.workItems[parentID]; ok && workItem.Url != nil {
+			m.addRelation(parent, workitemtracking.WorkItemRelation{
+				Rel: stringPtr(RelationTypeHierarchyForward),
+				Url: workItem.Url,
+			})
+		}
+	}
+}
+
+// removeRelation removes the relation at the given index, as done by a "/relations/{index}" remove operation.
+func (m *mockWorkItemTrackingClient) removeRelation(workItem *workitemtracking.WorkItem, index string) {
+	i, err := strconv.Atoi(index)
+	if err != nil || workItem.Relations == nil || i >= len(*workItem.Relations) {
+		return
+	}
+	*workItem.Relations = append((*workItem.Relations)[:i], (*workItem.Relations)[i+1:]...)
+}
+
 func (m *mockWorkItemTrackingClient) QueryByWiql(ctx context.Context, args workitemtracking.QueryByWiqlArgs) (*workitemtracking.WorkItemQueryResult, error) {
 	m.queryCalls = append(m.queryCalls, *args.Wiql.Query)
 
@@ -156,9 +216,14 @@ func (m *mockWorkItemTrackingClient) QueryByWiql(ctx context.Context, args worki
 	var workItems []workitemtracking.WorkItemReference
 
 	// Look for fingerprint in the query
+	seen := map[int]bool{}
 	for tag, items := range m.workItemsByTag {
 		if containsSubstring(*args.Wiql.Query, tag) {
 			for _, item := range items {
+				if seen[*item.Id] {
+					continue
+				}
+				seen[*item.Id] = true
 				workItems = append(workItems, workitemtracking.WorkItemReference{
 					Id: item.Id,
 				})
@@ -194,6 +259,7 @@ func (m *mockWorkItemTrackingClient) AddComment(ctx context.Context, args workit
 
 // [Preview API] Add a comment on a work item.
 func (m *mockWorkItemTrackingClient) AddWorkItemComment(ctx context.Context, args workitemtracking.AddWorkItemCommentArgs) (*workitemtracking.Comment, error) {
+	m.commentCalls = append(m.commentCalls, args)
 	if m.shouldFailAddComment {
 		return nil, errors.New("mock add work item comment failed")
 	}
@@ -206,7 +272,15 @@ func (m *mockWorkItemTrackingClient) AddWorkItemComment(ctx context.Context, arg
 
 // [Preview API] Uploads an attachment.
 func (m *mockWorkItemTrackingClient) CreateAttachment(ctx context.Context, args workitemtracking.CreateAttachmentArgs) (*workitemtracking.AttachmentReference, error) {
-	return &workitemtracking.AttachmentReference{}, nil
+	m.attachCalls = append(m.attachCalls, args)
+	if m.shouldFailAttachment {
+		return nil, errors.New("mock create attachment failed")
+	}
+
+	url := fmt.Sprintf("https://dev.azure.com/org/_apis/wit/attachments/%d", len(m.attachCalls))
+	return &workitemtracking.AttachmentReference{
+		Url: &url,
+	}, nil
 }
 
 // [Preview API] Adds a new reaction to a comment.
@@ -478,7 +552,7 @@ func (m *mockWorkItemTrackingClient) GetWorkItemField(ctx context.Context, args
 
 // [Preview API] Returns information for all fields. The project ID/name parameter is optional.
 func (m *mockWorkItemTrackingClient) GetWorkItemFields(ctx context.Context, args workitemtracking.GetWorkItemFieldsArgs) (*[]workitemtracking.WorkItemField2, error) {
-	return &[]workitemtracking.WorkItemField2{}, nil
+	return &m.fields, nil
 }
 
 // [Preview API] Get a work item icon given the friendly name and icon color.
@@ -508,7 +582,20 @@ func (m *mockWorkItemTrackingClient) GetWorkItemNextStatesOnCheckinAction(ctx co
 
 // [Preview API] Returns a list of work items (Maximum 200)
 func (m *mockWorkItemTrackingClient) GetWorkItems(ctx context.Context, args workitemtracking.GetWorkItemsArgs) (*[]workitemtracking.WorkItem, error) {
-	return &[]workitemtracking.WorkItem{}, nil
+	workItems := []workitemtracking.WorkItem{}
+	if args.Ids == nil {
+		return &workItems, nil
+	}
+	if len(*args.Ids) > 200 {
+		return nil, errors.Errorf("too many work item IDs: %d", len(*args.Ids))
+	}
+	m.getWorkItemsCalls++
+	for _, id := range *args.Ids {
+		if workItem, ok := m.workItems[id]; ok {
+			workItems = append(workItems, *workItem)
+		}
+	}
+	return &workItems, nil
 }
 
 // [Preview API] Gets work items for a list of work item ids (Maximum 200)
@@ -538,7 +625,7 @@ func (m *mockWorkItemTrackingClient) GetWorkItemTypeCategory(ctx context.Context
 
 // [Preview API] Get a list of fields for a work item type with detailed references.
 func (m *mockWorkItemTrackingClient) GetWorkItemTypeFieldsWithReferences(ctx context.Context, args workitemtracking.GetWorkItemTypeFieldsWithReferencesArgs) (*[]workitemtracking.WorkItemTypeFieldWithReferences, error) {
-	return &[]workitemtracking.WorkItemTypeFieldWithReferences{}, nil
+	return &m.typeFields, nil
 }
 
 // [Preview API] Get a field for a work item type with detailed references.
@@ -30,6 +30,12 @@ import (
 	"github.com/stakater/alert-az-do/pkg/template"
 )
 
+// fingerprintTagPrefix prefixes the tags holding alert fingerprints, see alertmanager.Alerts.Fingerprints.
+const fingerprintTagPrefix = "Fingerprint:"
+
+// maxWorkItemsPerRequest is the maximum number of work items that can be read in a single request.
+const maxWorkItemsPerRequest = 200
+
 // Receiver wraps Azure DevOps client with configuration
 type Receiver struct {
 	logger log.Logger
@@ -88,16 +94,35 @@ func (r *Receiver) updateWorkItem(ctx context.Context, data *alertmanager.Data,
 	if err != nil {
 		return errors.Wrap(err, "generate work item document")
 	}
+	workItemType, _ := (*workItemRef.Fields)[WorkItemFieldWorkItemType.String()].(string)
+	document, err = r.checkFields(ctx, project, workItemType, document)
+	if err != nil {
+		return errors.Wrap(err, "check work item fields")
+	}
+	document, escalation := r.escalateDocument(data, document, *workItemRef.Fields)
 
-	// Add/update fingerprints for updates - use Replace to ensure we have all current fingerprints
+	// Add/update fingerprints for updates - use Replace to ensure we have all current fingerprints, while keeping
+	// the tags that are not fingerprints (e.g. correlation tags or tags added by hand)
 	if len(data.Alerts) > 0 {
 		document = append(document, webapi.JsonPatchOperation{
 			Op:    &webapi.OperationValues.Replace,
 			Path:  stringPtr(WorkItemFieldTags.FieldPath()),
-			Value: strings.Join(data.Alerts.Fingerprints(), "; "),
+			Value: mergeTags((*workItemRef.Fields)[WorkItemFieldTags.String()], data.Alerts.Fingerprints()),
 		})
 	}
 
+	linkOps, err := r.linkOperations(data, workItemRef.Relations)
+	if err != nil {
+		return errors.Wrap(err, "generate link relations")
+	}
+	document = append(document, linkOps...)
+
+	parentOps, err := r.parentOperations(ctx, data, project, workItemRef.Relations)
+	if err != nil {
+		return errors.Wrap(err, "link parent work item")
+	}
+	document = append(document, parentOps...)
+
 	if r.conf.AutoResolve != nil && (*workItemRef.Fields)[WorkItemFieldState.String()] == r.conf.AutoResolve.State {
 		document = append(document, webapi.JsonPatchOperation{
 			Op:    &webapi.OperationValues.Replace,
@@ -119,6 +144,17 @@ func (r *Receiver) updateWorkItem(ctx context.Context, data *alertmanager.Data,
 
 	level.Info(r.logger).Log("msg", "work item updated", "id", workItem.Id, "title", (*workItem.Fields)[WorkItemFieldTitle.String()].(string))
 
+	if err := r.attachPayload(ctx, data, project, *workItemRef.Id, workItemRef.Relations); err != nil {
+		return errors.Wrap(err, "attach payload")
+	}
+
+	if escalation != nil {
+		comment := fmt.Sprintf("Priority raised from %d to %d (%s).", escalation.From, escalation.To, escalation.Reason)
+		if err := r.postComment(ctx, project, *workItemRef.Id, comment); err != nil {
+			return errors.Wrap(err, "add escalation comment to work item")
+		}
+	}
+
 	if r.conf.UpdateInComment != nil && *r.conf.UpdateInComment {
 		if err := r.addComment(ctx, data, workItemRef); err != nil {
 			return errors.Wrap(err, "add comment to work item")
@@ -138,6 +174,23 @@ func (r *Receiver) createWorkItem(ctx context.Context, data *alertmanager.Data,
 	if err != nil {
 		return errors.Wrap(err, "generate work item document")
 	}
+	document, err = r.checkFields(ctx, project, workItemType, document)
+	if err != nil {
+		return errors.Wrap(err, "check work item fields")
+	}
+	document, _ = r.escalateDocument(data, document, nil)
+
+	linkOps, err := r.linkOperations(data, nil)
+	if err != nil {
+		return errors.Wrap(err, "generate link relations")
+	}
+	document = append(document, linkOps...)
+
+	parentOps, err := r.parentOperations(ctx, data, project, nil)
+	if err != nil {
+		return errors.Wrap(err, "link parent work item")
+	}
+	document = append(document, parentOps...)
 
 	payload := workitemtracking.CreateWorkItemArgs{
 		Document:     &document,
@@ -152,6 +205,14 @@ func (r *Receiver) createWorkItem(ctx context.Context, data *alertmanager.Data,
 	}
 
 	level.Info(r.logger).Log("msg", "work item created", "id", workItem.Id, "title", (*workItem.Fields)[WorkItemFieldTitle.String()].(string))
+
+	if err := r.attachPayload(ctx, data, project, *workItem.Id, nil); err != nil {
+		return errors.Wrap(err, "attach payload")
+	}
+
+	if err := r.correlate(ctx, data, workItem); err != nil {
+		return errors.Wrap(err, "correlate work item")
+	}
 	return nil
 }
 
@@ -193,7 +254,7 @@ func (r *Receiver) findWorkItem(ctx context.Context, data *alertmanager.Data, pr
 	workItemRef := (*queryResult.WorkItems)[0]
 	return r.client.GetWorkItem(ctx, workitemtracking.GetWorkItemArgs{
 		Id:     workItemRef.Id,
-		Expand: nil,
+		Expand: &workitemtracking.WorkItemExpandValues.Relations,
 	})
 }
 
@@ -210,6 +271,12 @@ func (r *Receiver) resolveWorkItem(ctx context.Context, data *alertmanager.Data,
 	if err != nil {
 		return errors.Wrap(err, "generate resolve document")
 	}
+	workItemType, _ := (*workItemRef.Fields)[WorkItemFieldWorkItemType.String()].(string)
+	document, err = r.checkFields(ctx, project, workItemType, document)
+	if err != nil {
+		return errors.Wrap(err, "check work item fields")
+	}
+	document, _ = r.escalateDocument(data, document, *workItemRef.Fields)
 
 	if r.conf.AutoResolve.State != "" {
 		document = append(document, webapi.JsonPatchOperation{
@@ -231,6 +298,10 @@ func (r *Receiver) resolveWorkItem(ctx context.Context, data *alertmanager.Data,
 	}
 
 	level.Info(r.logger).Log("msg", "work item resolved", "id", workItem.Id, "title", (*workItem.Fields)["System.Title"])
+
+	if err := r.closeParentIfDone(ctx, workItemRef); err != nil {
+		return errors.Wrap(err, "close parent work item")
+	}
 	return nil
 }
 
@@ -270,7 +341,7 @@ func (r *Receiver) generateWorkItemDocument(data *alertmanager.Data, addFingerpr
 		document = append(document, webapi.JsonPatchOperation{
 			Op:    &webapi.OperationValues.Add,
 			Path:  stringPtr(WorkItemFieldTags.FieldPath()),
-			Value: strings.Join(data.Alerts.FiringFingerprints(), "; "),
+			Value: strings.Join(append(append(data.Alerts.FiringFingerprints(), r.correlationTags(data)...), r.receiverTags()...), "; "),
 		})
 	}
 
@@ -312,17 +383,42 @@ func (r *Receiver) generateWorkItemDocument(data *alertmanager.Data, addFingerpr
 	return document, nil
 }
 
+// getWorkItems reads the work items of args.Ids, in batches of maxWorkItemsPerRequest.
+func (r *Receiver) getWorkItems(ctx context.Context, args workitemtracking.GetWorkItemsArgs) ([]workitemtracking.WorkItem, error) {
+	ids := *args.Ids
+	var workItems []workitemtracking.WorkItem
+	for start := 0; start < len(ids); start += maxWorkItemsPerRequest {
+		end := start + maxWorkItemsPerRequest
+		if end > len(ids) {
+			end = len(ids)
+		}
+		batch := ids[start:end]
+		args.Ids = &batch
+		res, err := r.client.GetWorkItems(ctx, args)
+		if err != nil {
+			return nil, err
+		}
+		if res != nil {
+			workItems = append(workItems, *res...)
+		}
+	}
+	return workItems, nil
+}
+
 func (r *Receiver) addComment(ctx context.Context, _ *alertmanager.Data, workItem *workitemtracking.WorkItem) error {
 	project := (*workItem.Fields)[WorkItemFieldTeamProject.String()].(string)
 
-	comment := "Issue updated with new alert data"
+	return r.postComment(ctx, project, *workItem.Id, "Issue updated with new alert data")
+}
 
+// postComment adds a markdown comment to the given work item.
+func (r *Receiver) postComment(ctx context.Context, project string, id int, comment string) error {
 	payload := workitemtracking.AddWorkItemCommentArgs{
 		Request: &workitemtracking.CommentCreate{
 			Text: &comment,
 		},
 		Project:    stringPtr(project),
-		WorkItemId: workItem.Id,
+		WorkItemId: &id,
 		Format:     &workitemtracking.CommentFormatValues.Markdown,
 	}
 
@@ -331,10 +427,37 @@ func (r *Receiver) addComment(ctx context.Context, _ *alertmanager.Data, workIte
 		return errors.Wrap(err, "create work item comment")
 	}
 
-	level.Info(r.logger).Log("msg", "work item comment created", "id", workItemComment.Id, "workItemId", workItem.Id)
+	level.Info(r.logger).Log("msg", "work item comment created", "id", workItemComment.Id, "workItemId", id)
 	return nil
 }
 
+// mergeTags replaces the fingerprint tags in the existing "; " separated tags with the given fingerprints, keeping
+// all other tags.
+func mergeTags(existing interface{}, fingerprints []string) string {
+	tags := append([]string(nil), fingerprints...)
+	for _, tag := range splitTags(existing) {
+		if !strings.HasPrefix(tag, fingerprintTagPrefix) {
+			tags = append(tags, tag)
+		}
+	}
+	return strings.Join(tags, "; ")
+}
+
+// splitTags splits the "; " separated tags of a work item.
+func splitTags(v interface{}) []string {
+	s, ok := v.(string)
+	if !ok {
+		return nil
+	}
+	var tags []string
+	for _, tag := range strings.Split(s, ";") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
 // Helper function to create string pointers
 func stringPtr(s string) *string {
 	return &s
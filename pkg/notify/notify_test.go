@@ -64,6 +64,11 @@ func testReceiverConfigWithFields() *config.ReceiverConfig {
 	}
 }
 
+// newTestReceiver returns a receiver with the configuration, notifying through the mock client.
+func newTestReceiver(client *mockWorkItemTrackingClient, conf *config.ReceiverConfig) *Receiver {
+	return &Receiver{logger: log.NewNopLogger(), client: client, conf: conf, tmpl: template.SimpleTemplate()}
+}
+
 func TestReceiver_Notify_CreateWorkItem(t *testing.T) {
 	logger := log.NewLogfmtLogger(os.Stderr)
 	mockClient := newMockWorkItemTrackingClient()
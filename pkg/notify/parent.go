@@ -0,0 +1,286 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log/level"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/webapi"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/workitemtracking"
+	"github.com/pkg/errors"
+	"github.com/stakater/alert-az-do/pkg/alertmanager"
+)
+
+const (
+	// RelationTypeHierarchyReverse links a work item to its parent.
+	RelationTypeHierarchyReverse = "System.LinkTypes.Hierarchy-Reverse"
+
+	// RelationTypeHierarchyForward links a work item to its children.
+	RelationTypeHierarchyForward = "System.LinkTypes.Hierarchy-Forward"
+
+	// parentTagPrefix prefixes the tag identifying a parent work item by its rendered key.
+	parentTagPrefix = "Parent:"
+)
+
+// parentTag returns the tag identifying the parent work item for the given key. Semicolons and commas separate tags
+// in Azure DevOps, so they are replaced.
+func parentTag(key string) string {
+	return parentTagPrefix + strings.NewReplacer(";", "_", ",", "_").Replace(strings.TrimSpace(key))
+}
+
+// parentOperations returns the JSON patch operations linking the work item to its parent, creating the parent when
+// it does not exist yet. Work items that already have a parent are left alone.
+func (r *Receiver) parentOperations(ctx context.Context, data *alertmanager.Data, project string, existing *[]workitemtracking.WorkItemRelation) ([]webapi.JsonPatchOperation, error) {
+	if r.conf.Parent == nil || parentURL(existing) != "" {
+		return nil, nil
+	}
+
+	parent, err := r.ensureParent(ctx, data, project)
+	if err != nil {
+		return nil, err
+	}
+	if parent == nil || parent.Url == nil {
+		return nil, nil
+	}
+
+	return []webapi.JsonPatchOperation{{
+		Op:   &webapi.OperationValues.Add,
+		Path: stringPtr(relationsPath),
+		Value: workitemtracking.WorkItemRelation{
+			Rel: stringPtr(RelationTypeHierarchyReverse),
+			Url: parent.Url,
+		},
+	}}, nil
+}
+
+// ensureParent finds the parent work item for the notification and creates it when missing. A parent that was
+// closed automatically is reopened, as it gets a new child.
+func (r *Receiver) ensureParent(ctx context.Context, data *alertmanager.Data, project string) (*workitemtracking.WorkItem, error) {
+	key, err := r.tmpl.Execute(r.conf.Parent.Key, data)
+	if err != nil {
+		return nil, errors.Wrap(err, "render parent key")
+	}
+	if strings.TrimSpace(key) == "" {
+		level.Warn(r.logger).Log("msg", "parent key rendered empty, not linking to a parent")
+		return nil, nil
+	}
+	tag := parentTag(key)
+
+	wiql := fmt.Sprintf("SELECT [%s] FROM WorkItems WHERE [%s] = '%s' AND [%s] CONTAINS '%s'",
+		WorkItemFieldId.String(),
+		WorkItemFieldTeamProject.String(),
+		project,
+		WorkItemFieldTags.String(),
+		tag)
+	queryResult, err := r.client.QueryByWiql(ctx, workitemtracking.QueryByWiqlArgs{
+		Wiql: &workitemtracking.Wiql{Query: &wiql},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "query parent work item")
+	}
+
+	if queryResult.WorkItems != nil && len(*queryResult.WorkItems) > 0 {
+		parent, err := r.client.GetWorkItem(ctx, workitemtracking.GetWorkItemArgs{
+			Id: (*queryResult.WorkItems)[0].Id,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "get parent work item")
+		}
+		if autoClose := r.conf.Parent.AutoClose; autoClose != nil && (*parent.Fields)[WorkItemFieldState.String()] == autoClose.State {
+			if _, err := r.client.UpdateWorkItem(ctx, workitemtracking.UpdateWorkItemArgs{
+				Id:      parent.Id,
+				Project: &project,
+				Document: &[]webapi.JsonPatchOperation{{
+					Op:    &webapi.OperationValues.Replace,
+					Path:  stringPtr(WorkItemFieldState.FieldPath()),
+					Value: r.conf.ReopenState,
+				}},
+			}); err != nil {
+				return nil, errors.Wrap(err, "reopen parent work item")
+			}
+			level.Info(r.logger).Log("msg", "parent work item reopened", "id", parent.Id, "key", key)
+		}
+		return parent, nil
+	}
+
+	return r.createParent(ctx, data, project, key, tag)
+}
+
+func (r *Receiver) createParent(ctx context.Context, data *alertmanager.Data, project, key, tag string) (*workitemtracking.WorkItem, error) {
+	title := key
+	if r.conf.Parent.Summary != "" {
+		var err error
+		title, err = r.tmpl.Execute(r.conf.Parent.Summary, data)
+		if err != nil {
+			return nil, errors.Wrap(err, "render parent title")
+		}
+	}
+	if len(title) > 128 {
+		title = title[:128]
+	}
+
+	document := []webapi.JsonPatchOperation{
+		{
+			Op:    &webapi.OperationValues.Add,
+			Path:  stringPtr(WorkItemFieldTitle.FieldPath()),
+			Value: title,
+		},
+		{
+			Op:    &webapi.OperationValues.Add,
+			Path:  stringPtr(WorkItemFieldTags.FieldPath()),
+			Value: tag,
+		},
+	}
+	if r.conf.Parent.Description != "" {
+		description, err := r.tmpl.Execute(r.conf.Parent.Description, data)
+		if err != nil {
+			return nil, errors.Wrap(err, "render parent description")
+		}
+		document = append(document, webapi.JsonPatchOperation{
+			Op:    &webapi.OperationValues.Add,
+			Path:  stringPtr(WorkItemFieldDescription.FieldPath()),
+			Value: description,
+		})
+	}
+
+	parent, err := r.client.CreateWorkItem(ctx, workitemtracking.CreateWorkItemArgs{
+		Document: &document,
+		Project:  &project,
+		Type:     &r.conf.Parent.IssueType,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "create parent work item")
+	}
+
+	level.Info(r.logger).Log("msg", "parent work item created", "id", parent.Id, "key", key)
+	return parent, nil
+}
+
+// closeParentIfDone closes the parent of the given work item when all of the parent's children are closed.
+func (r *Receiver) closeParentIfDone(ctx context.Context, workItem *workitemtracking.WorkItem) error {
+	if r.conf.Parent == nil || r.conf.Parent.AutoClose == nil {
+		return nil
+	}
+	u := parentURL(workItem.Relations)
+	if u == "" {
+		return nil
+	}
+	parentID, err := workItemIDFromURL(u)
+	if err != nil {
+		return err
+	}
+
+	parent, err := r.client.GetWorkItem(ctx, workitemtracking.GetWorkItemArgs{
+		Id:     &parentID,
+		Expand: &workitemtracking.WorkItemExpandValues.Relations,
+	})
+	if err != nil {
+		return errors.Wrap(err, "get parent work item")
+	}
+	if (*parent.Fields)[WorkItemFieldState.String()] == r.conf.Parent.AutoClose.State {
+		return nil
+	}
+
+	var childIDs []int
+	for _, relation := range relationsOfType(parent.Relations, RelationTypeHierarchyForward) {
+		id, err := workItemIDFromURL(*relation.Url)
+		if err != nil {
+			return err
+		}
+		childIDs = append(childIDs, id)
+	}
+	if len(childIDs) == 0 {
+		return nil
+	}
+
+	children, err := r.getWorkItems(ctx, workitemtracking.GetWorkItemsArgs{
+		Ids:    &childIDs,
+		Fields: &[]string{WorkItemFieldState.String()},
+	})
+	if err != nil {
+		return errors.Wrap(err, "get child work items")
+	}
+
+	closedStates := r.conf.Parent.ClosedStates
+	if len(closedStates) == 0 {
+		closedStates = []string{r.conf.AutoResolve.State}
+	}
+	for _, child := range children {
+		state, _ := (*child.Fields)[WorkItemFieldState.String()].(string)
+		if !containsString(closedStates, state) {
+			level.Debug(r.logger).Log("msg", "parent work item has open children", "id", parentID, "child", child.Id, "state", state)
+			return nil
+		}
+	}
+
+	if _, err := r.client.UpdateWorkItem(ctx, workitemtracking.UpdateWorkItemArgs{
+		Id: &parentID,
+		Document: &[]webapi.JsonPatchOperation{{
+			Op:    &webapi.OperationValues.Replace,
+			Path:  stringPtr(WorkItemFieldState.FieldPath()),
+			Value: r.conf.Parent.AutoClose.State,
+		}},
+	}); err != nil {
+		return errors.Wrap(err, "close parent work item")
+	}
+	level.Info(r.logger).Log("msg", "parent work item closed", "id", parentID)
+	return nil
+}
+
+// parentURL returns the URL of the parent work item, or an empty string when there is none.
+func parentURL(relations *[]workitemtracking.WorkItemRelation) string {
+	for _, relation := range relationsOfType(relations, RelationTypeHierarchyReverse) {
+		return *relation.Url
+	}
+	return ""
+}
+
+// relationsOfType returns the relations of the given type that have a URL.
+func relationsOfType(relations *[]workitemtracking.WorkItemRelation, rel string) []workitemtracking.WorkItemRelation {
+	if relations == nil {
+		return nil
+	}
+	var res []workitemtracking.WorkItemRelation
+	for _, relation := range *relations {
+		if relation.Rel != nil && *relation.Rel == rel && relation.Url != nil {
+			res = append(res, relation)
+		}
+	}
+	return res
+}
+
+// workItemIDFromURL extracts the work item ID from a work item API URL such as
+// https://dev.azure.com/org/_apis/wit/workItems/42.
+func workItemIDFromURL(u string) (int, error) {
+	id, err := strconv.Atoi(path.Base(u))
+	if err != nil {
+		return 0, errors.Errorf("invalid work item url %q", u)
+	}
+	return id, nil
+}
+
+func containsString(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/workitemtracking"
+	"github.com/stakater/alert-az-do/pkg/alertmanager"
+	"github.com/stakater/alert-az-do/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func testParentReceiver(client *mockWorkItemTrackingClient) *Receiver {
+	conf := testReceiverConfig1()
+	conf.ReopenState = "Active"
+	conf.AutoResolve = &config.AutoResolve{State: "Closed"}
+	conf.Parent = &config.Parent{
+		Key:       "{{ .GroupLabels.alertname }}",
+		IssueType: "Feature",
+		Summary:   "Alerts: {{ .GroupLabels.alertname }}",
+		AutoClose: &config.AutoResolve{State: "Done"},
+	}
+	return newTestReceiver(client, conf)
+}
+
+func testParentData(status, fingerprint string) *alertmanager.Data {
+	return &alertmanager.Data{
+		Status:      status,
+		Alerts:      alertmanager.Alerts{{Status: status, Fingerprint: fingerprint}},
+		GroupLabels: alertmanager.KV{"alertname": "KubePodCrashLooping", "pod": fingerprint},
+	}
+}
+
+func TestParentTag(t *testing.T) {
+	require.Equal(t, "Parent:KubePodCrashLooping", parentTag(" KubePodCrashLooping "))
+	require.Equal(t, "Parent:a_b_c", parentTag("a;b,c"))
+}
+
+func TestReceiver_Notify_CreatesAndReusesParent(t *testing.T) {
+	mockClient := newMockWorkItemTrackingClient()
+	receiver := testParentReceiver(mockClient)
+	ctx := context.Background()
+
+	require.NoError(t, receiver.Notify(ctx, testParentData(alertmanager.AlertFiring, "fp1")))
+	require.NoError(t, receiver.Notify(ctx, testParentData(alertmanager.AlertFiring, "fp2")))
+
+	// One parent and two children.
+	require.Len(t, mockClient.createCalls, 3)
+	require.Equal(t, "Feature", *mockClient.createCalls[0].args.Type)
+	parent := mockClient.workItems[1]
+	require.Equal(t, "Alerts: KubePodCrashLooping", (*parent.Fields)["System.Title"])
+	require.Equal(t, "Parent:KubePodCrashLooping", (*parent.Fields)["System.Tags"])
+
+	for _, id := range []int{2, 3} {
+		require.Equal(t, *parent.Url, parentURL(mockClient.workItems[id].Relations))
+	}
+	require.Len(t, relationsOfType(parent.Relations, RelationTypeHierarchyForward), 2)
+}
+
+func TestReceiver_Notify_UpdateLinksOrphanToParent(t *testing.T) {
+	mockClient := newMockWorkItemTrackingClient()
+	receiver := testParentReceiver(mockClient)
+
+	orphan := &workitemtracking.WorkItem{
+		Id:     intPtr(1),
+		Fields: &map[string]interface{}{"System.Title": "orphan", "System.Tags": "Fingerprint:fp1"},
+	}
+	mockClient.workItems[1] = orphan
+	mockClient.workItemsByTag["Fingerprint:fp1"] = []*workitemtracking.WorkItem{orphan}
+	mockClient.nextID = 2
+
+	require.NoError(t, receiver.Notify(context.Background(), testParentData(alertmanager.AlertFiring, "fp1")))
+	require.Len(t, mockClient.createCalls, 1)
+	require.Equal(t, *mockClient.workItems[2].Url, parentURL(orphan.Relations))
+
+	// Already linked work items are not linked again.
+	require.NoError(t, receiver.Notify(context.Background(), testParentData(alertmanager.AlertFiring, "fp1")))
+	require.Len(t, *orphan.Relations, 1)
+}
+
+func TestReceiver_Notify_ClosesParentWhenAllChildrenClosed(t *testing.T) {
+	mockClient := newMockWorkItemTrackingClient()
+	receiver := testParentReceiver(mockClient)
+	ctx := context.Background()
+
+	require.NoError(t, receiver.Notify(ctx, testParentData(alertmanager.AlertFiring, "fp1")))
+	require.NoError(t, receiver.Notify(ctx, testParentData(alertmanager.AlertFiring, "fp2")))
+	parent := mockClient.workItems[1]
+
+	require.NoError(t, receiver.Notify(ctx, testParentData(alertmanager.AlertResolved, "fp1")))
+	require.Equal(t, "New", (*parent.Fields)["System.State"])
+
+	require.NoError(t, receiver.Notify(ctx, testParentData(alertmanager.AlertResolved, "fp2")))
+	require.Equal(t, "Done", (*parent.Fields)["System.State"])
+
+	// A new child reopens the closed parent.
+	require.NoError(t, receiver.Notify(ctx, testParentData(alertmanager.AlertFiring, "fp3")))
+	require.Equal(t, "Active", (*parent.Fields)["System.State"])
+	require.Len(t, relationsOfType(parent.Relations, RelationTypeHierarchyForward), 3)
+}
+
+func TestReceiver_Notify_ClosesParentWithManyChildren(t *testing.T) {
+	mockClient := newMockWorkItemTrackingClient()
+	receiver := testParentReceiver(mockClient)
+	ctx := context.Background()
+
+	children := maxWorkItemsPerRequest + 50
+	for i := 0; i < children; i++ {
+		require.NoError(t, receiver.Notify(ctx, testParentData(alertmanager.AlertFiring, fmt.Sprintf("fp%03d", i))))
+	}
+	parent := mockClient.workItems[1]
+	require.Len(t, relationsOfType(parent.Relations, RelationTypeHierarchyForward), children)
+
+	for i := 0; i < children; i++ {
+		require.NoError(t, receiver.Notify(ctx, testParentData(alertmanager.AlertResolved, fmt.Sprintf("fp%03d", i))))
+	}
+	require.Equal(t, "Done", (*parent.Fields)["System.State"])
+}
+
+func TestReceiver_ParentOperations_EmptyKey(t *testing.T) {
+	mockClient := newMockWorkItemTrackingClient()
+	receiver := testParentReceiver(mockClient)
+	receiver.conf.Parent.Key = "{{ .CommonLabels.service }}"
+
+	document, err := receiver.parentOperations(context.Background(), testParentData(alertmanager.AlertFiring, "fp1"), "TestProject", nil)
+	require.NoError(t, err)
+	require.Empty(t, document)
+	require.Empty(t, mockClient.createCalls)
+}
+
+func TestReceiver_ParentOperations_ErrorPaths(t *testing.T) {
+	t.Run("query failure", func(t *testing.T) {
+		mockClient := newMockWorkItemTrackingClient()
+		mockClient.shouldFailQuery = true
+		receiver := testParentReceiver(mockClient)
+
+		_, err := receiver.parentOperations(context.Background(), testParentData(alertmanager.AlertFiring, "fp1"), "TestProject", nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "query parent work item")
+	})
+
+	t.Run("create failure", func(t *testing.T) {
+		mockClient := newMockWorkItemTrackingClient()
+		mockClient.shouldFailCreate = true
+		receiver := testParentReceiver(mockClient)
+
+		_, err := receiver.parentOperations(context.Background(), testParentData(alertmanager.AlertFiring, "fp1"), "TestProject", nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "create parent work item")
+	})
+
+	t.Run("key template error", func(t *testing.T) {
+		receiver := testParentReceiver(newMockWorkItemTrackingClient())
+		receiver.conf.Parent.Key = "{{ .Invalid }"
+
+		_, err := receiver.parentOperations(context.Background(), testParentData(alertmanager.AlertFiring, "fp1"), "TestProject", nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "render parent key")
+	})
+}
+
+func TestWorkItemIDFromURL(t *testing.T) {
+	id, err := workItemIDFromURL("https://dev.azure.com/org/_apis/wit/workItems/42")
+	require.NoError(t, err)
+	require.Equal(t, 42, id)
+
+	_, err = workItemIDFromURL("https://dev.azure.com/org/_apis/wit/workItems/abc")
+	require.Error(t, err)
+}
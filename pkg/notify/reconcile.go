@@ -0,0 +1,158 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-kit/log/level"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/webapi"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/workitemtracking"
+	"github.com/pkg/errors"
+	"github.com/stakater/alert-az-do/pkg/alertmanager"
+)
+
+// AlertLister lists the active alerts of a receiver. It is implemented by alertmanager.Client.
+type AlertLister interface {
+	ListAlerts(ctx context.Context, receiver string) ([]alertmanager.GettableAlert, error)
+}
+
+// Reconcile auto-resolves the open work items of the receiver whose alerts are not active in Alertmanager anymore,
+// e.g. because their resolved notification was lost. It returns the number of work items that were resolved. Nothing
+// is resolved when Alertmanager has no active alerts for the receiver, or when none of the open work items has one.
+func (r *Receiver) Reconcile(ctx context.Context, lister AlertLister) (int, error) {
+	if r.conf.Reconcile == nil || r.conf.AutoResolve == nil {
+		return 0, nil
+	}
+
+	// List the alerts first: when Alertmanager is unavailable, no work item must be resolved.
+	alerts, err := lister.ListAlerts(ctx, r.conf.Name)
+	if err != nil {
+		return 0, errors.Wrap(err, "list active alerts")
+	}
+	if len(alerts) == 0 {
+		// Alertmanager may have just restarted, and not received the alerts again yet.
+		level.Info(r.logger).Log("msg", "no active alerts in Alertmanager, not reconciling")
+		return 0, nil
+	}
+	active := make(map[string]bool, len(alerts))
+	for _, alert := range alerts {
+		active[alert.Fingerprint] = true
+	}
+
+	wiql := fmt.Sprintf("SELECT [%s] FROM WorkItems WHERE [%s] CONTAINS '%s'%s AND [%s] <> '%s'",
+		WorkItemFieldId.String(),
+		WorkItemFieldTags.String(),
+		r.receiverTag(),
+		r.projectCondition(),
+		WorkItemFieldState.String(),
+		r.conf.AutoResolve.State)
+	queryResult, err := r.client.QueryByWiql(ctx, workitemtracking.QueryByWiqlArgs{
+		Wiql: &workitemtracking.Wiql{Query: &wiql},
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "query open work items")
+	}
+	if queryResult.WorkItems == nil || len(*queryResult.WorkItems) == 0 {
+		return 0, nil
+	}
+
+	var ids []int
+	for _, ref := range *queryResult.WorkItems {
+		if ref.Id != nil {
+			ids = append(ids, *ref.Id)
+		}
+	}
+	workItems, err := r.getWorkItems(ctx, workitemtracking.GetWorkItemsArgs{
+		Ids:    &ids,
+		Expand: &workitemtracking.WorkItemExpandValues.Relations,
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "get open work items")
+	}
+
+	var (
+		stale        []*workitemtracking.WorkItem
+		fingerprints = map[int][]string{}
+		matched      = false
+	)
+	for i := range workItems {
+		workItem := &workItems[i]
+		if workItem.Id == nil || workItem.Fields == nil {
+			continue
+		}
+		state, _ := (*workItem.Fields)[WorkItemFieldState.String()].(string)
+		if state == r.conf.AutoResolve.State || (r.conf.SkipReopenState != "" && state == r.conf.SkipReopenState) {
+			continue
+		}
+
+		firing := false
+		for _, tag := range splitTags((*workItem.Fields)[WorkItemFieldTags.String()]) {
+			if !strings.HasPrefix(tag, fingerprintTagPrefix) {
+				continue
+			}
+			fingerprint := strings.TrimPrefix(tag, fingerprintTagPrefix)
+			fingerprints[*workItem.Id] = append(fingerprints[*workItem.Id], fingerprint)
+			firing = firing || active[fingerprint]
+		}
+		matched = matched || firing
+		if len(fingerprints[*workItem.Id]) > 0 && !firing {
+			stale = append(stale, workItem)
+		}
+	}
+	if len(stale) > 0 && !matched {
+		// The active alerts are none of the receiver's, e.g. because the alerts are not sent by Alertmanager to a
+		// receiver of that name: resolving all open work items would most likely be wrong.
+		level.Warn(r.logger).Log("msg", "none of the open work items has an active alert in Alertmanager, not reconciling", "open", len(stale))
+		return 0, nil
+	}
+
+	resolved := 0
+	for _, workItem := range stale {
+		if err := r.resolveStale(ctx, workItem, fingerprints[*workItem.Id]); err != nil {
+			return resolved, err
+		}
+		resolved++
+	}
+	return resolved, nil
+}
+
+// resolveStale resolves a work item none of whose alerts is active anymore.
+func (r *Receiver) resolveStale(ctx context.Context, workItem *workitemtracking.WorkItem, fingerprints []string) error {
+	if _, err := r.client.UpdateWorkItem(ctx, workitemtracking.UpdateWorkItemArgs{
+		Id: workItem.Id,
+		Document: &[]webapi.JsonPatchOperation{{
+			Op:    &webapi.OperationValues.Replace,
+			Path:  stringPtr(WorkItemFieldState.FieldPath()),
+			Value: r.conf.AutoResolve.State,
+		}},
+	}); err != nil {
+		return errors.Wrap(err, "resolve work item")
+	}
+	level.Info(r.logger).Log("msg", "stale work item resolved", "id", *workItem.Id, "fingerprints", strings.Join(fingerprints, ","))
+
+	project, _ := (*workItem.Fields)[WorkItemFieldTeamProject.String()].(string)
+	comment := fmt.Sprintf("Resolved by reconciliation: none of the work item's alerts (%s) is active in Alertmanager anymore, "+
+		"its resolved notification was probably lost.", strings.Join(fingerprints, ", "))
+	if err := r.postComment(ctx, project, *workItem.Id, comment); err != nil {
+		return err
+	}
+	if err := r.closeParentIfDone(ctx, workItem); err != nil {
+		return errors.Wrap(err, "close parent work item")
+	}
+	return nil
+}
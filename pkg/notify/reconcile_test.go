@@ -0,0 +1,189 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stakater/alert-az-do/pkg/alertmanager"
+	"github.com/stakater/alert-az-do/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAlertLister struct {
+	alerts     []alertmanager.GettableAlert
+	receivers  []string
+	shouldFail bool
+}
+
+func (m *mockAlertLister) ListAlerts(_ context.Context, receiver string) ([]alertmanager.GettableAlert, error) {
+	m.receivers = append(m.receivers, receiver)
+	if m.shouldFail {
+		return nil, errors.New("mock list alerts failed")
+	}
+	return m.alerts, nil
+}
+
+func testReconcileReceiver(client *mockWorkItemTrackingClient) *Receiver {
+	conf := testReceiverConfig1()
+	conf.Name = "team-a"
+	conf.AutoResolve = &config.AutoResolve{State: "Closed"}
+	conf.Reconcile = &config.Reconcile{}
+	return newTestReceiver(client, conf)
+}
+
+func testReconcileData(fingerprints ...string) *alertmanager.Data {
+	data := &alertmanager.Data{Status: alertmanager.AlertFiring}
+	for _, fp := range fingerprints {
+		data.Alerts = append(data.Alerts, alertmanager.Alert{Status: alertmanager.AlertFiring, Fingerprint: fp})
+	}
+	return data
+}
+
+func TestReceiver_Reconcile(t *testing.T) {
+	mockClient := newMockWorkItemTrackingClient()
+	receiver := testReconcileReceiver(mockClient)
+	ctx := context.Background()
+
+	require.NoError(t, receiver.Notify(ctx, testReconcileData("fp1", "fp2")))
+	require.NoError(t, receiver.Notify(ctx, testReconcileData("fp3")))
+	require.Equal(t, "Fingerprint:fp1; Fingerprint:fp2; Receiver:team-a", (*mockClient.workItems[1].Fields)["System.Tags"])
+
+	// One alert of the first work item is still active, the alert of the second one is gone.
+	lister := &mockAlertLister{alerts: []alertmanager.GettableAlert{{Fingerprint: "fp2"}}}
+	resolved, err := receiver.Reconcile(ctx, lister)
+	require.NoError(t, err)
+	require.Equal(t, 1, resolved)
+	require.Equal(t, []string{"team-a"}, lister.receivers)
+
+	query := mockClient.queryCalls[len(mockClient.queryCalls)-1]
+	require.Contains(t, query, "[System.Tags] CONTAINS 'Receiver:team-a'")
+	require.Contains(t, query, "[System.TeamProject] = 'TestProject'")
+	require.Contains(t, query, "[System.State] <> 'Closed'")
+
+	require.Equal(t, "New", (*mockClient.workItems[1].Fields)["System.State"])
+	require.Equal(t, "Closed", (*mockClient.workItems[2].Fields)["System.State"])
+	require.Len(t, mockClient.commentCalls, 1)
+	require.Equal(t, 2, *mockClient.commentCalls[0].WorkItemId)
+	require.Contains(t, *mockClient.commentCalls[0].Request.Text, "none of the work item's alerts (fp3) is active in Alertmanager anymore")
+
+	// Resolved work items are not resolved again.
+	resolved, err = receiver.Reconcile(ctx, lister)
+	require.NoError(t, err)
+	require.Equal(t, 0, resolved)
+	require.Len(t, mockClient.commentCalls, 1)
+}
+
+func TestReceiver_Reconcile_SkipsSkipReopenState(t *testing.T) {
+	mockClient := newMockWorkItemTrackingClient()
+	receiver := testReconcileReceiver(mockClient)
+	receiver.conf.SkipReopenState = "Removed"
+	ctx := context.Background()
+
+	require.NoError(t, receiver.Notify(ctx, testReconcileData("fp1")))
+	(*mockClient.workItems[1].Fields)["System.State"] = "Removed"
+
+	resolved, err := receiver.Reconcile(ctx, &mockAlertLister{alerts: []alertmanager.GettableAlert{{Fingerprint: "other"}}})
+	require.NoError(t, err)
+	require.Equal(t, 0, resolved)
+	require.Equal(t, "Removed", (*mockClient.workItems[1].Fields)["System.State"])
+}
+
+func TestReceiver_Reconcile_NothingActive(t *testing.T) {
+	mockClient := newMockWorkItemTrackingClient()
+	receiver := testReconcileReceiver(mockClient)
+	ctx := context.Background()
+
+	require.NoError(t, receiver.Notify(ctx, testReconcileData("fp1")))
+	require.NoError(t, receiver.Notify(ctx, testReconcileData("fp2")))
+
+	// No active alerts, e.g. after a restart of Alertmanager.
+	resolved, err := receiver.Reconcile(ctx, &mockAlertLister{})
+	require.NoError(t, err)
+	require.Equal(t, 0, resolved)
+	require.Empty(t, mockClient.queryCalls[2:])
+
+	// Active alerts that are none of the work items', e.g. of another Alertmanager receiver of the same name.
+	resolved, err = receiver.Reconcile(ctx, &mockAlertLister{alerts: []alertmanager.GettableAlert{{Fingerprint: "other"}}})
+	require.NoError(t, err)
+	require.Equal(t, 0, resolved)
+	require.Equal(t, "New", (*mockClient.workItems[1].Fields)["System.State"])
+	require.Equal(t, "New", (*mockClient.workItems[2].Fields)["System.State"])
+}
+
+func TestReceiver_Reconcile_Batches(t *testing.T) {
+	mockClient := newMockWorkItemTrackingClient()
+	receiver := testReconcileReceiver(mockClient)
+	ctx := context.Background()
+
+	for i := 0; i < 250; i++ {
+		require.NoError(t, receiver.Notify(ctx, testReconcileData(fmt.Sprintf("fp%03d", i))))
+	}
+	resolved, err := receiver.Reconcile(ctx, &mockAlertLister{alerts: []alertmanager.GettableAlert{{Fingerprint: "fp000"}}})
+	require.NoError(t, err)
+	require.Equal(t, 249, resolved)
+	require.Equal(t, 2, mockClient.getWorkItemsCalls)
+}
+
+func TestReceiver_Reconcile_ErrorPaths(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("disabled", func(t *testing.T) {
+		mockClient := newMockWorkItemTrackingClient()
+		receiver := testReconcileReceiver(mockClient)
+		receiver.conf.Reconcile = nil
+		lister := &mockAlertLister{}
+
+		resolved, err := receiver.Reconcile(ctx, lister)
+		require.NoError(t, err)
+		require.Equal(t, 0, resolved)
+		require.Empty(t, lister.receivers)
+	})
+
+	t.Run("list alerts failure resolves nothing", func(t *testing.T) {
+		mockClient := newMockWorkItemTrackingClient()
+		receiver := testReconcileReceiver(mockClient)
+		require.NoError(t, receiver.Notify(ctx, testReconcileData("fp1")))
+
+		_, err := receiver.Reconcile(ctx, &mockAlertLister{shouldFail: true})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "list active alerts")
+		require.Equal(t, "New", (*mockClient.workItems[1].Fields)["System.State"])
+	})
+
+	t.Run("query failure", func(t *testing.T) {
+		mockClient := newMockWorkItemTrackingClient()
+		mockClient.shouldFailQuery = true
+
+		_, err := testReconcileReceiver(mockClient).Reconcile(ctx, &mockAlertLister{alerts: []alertmanager.GettableAlert{{Fingerprint: "fp1"}}})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "query open work items")
+	})
+
+	t.Run("update failure", func(t *testing.T) {
+		mockClient := newMockWorkItemTrackingClient()
+		receiver := testReconcileReceiver(mockClient)
+		require.NoError(t, receiver.Notify(ctx, testReconcileData("fp1")))
+		require.NoError(t, receiver.Notify(ctx, testReconcileData("fp2")))
+
+		mockClient.shouldFailUpdate = true
+		_, err := receiver.Reconcile(ctx, &mockAlertLister{alerts: []alertmanager.GettableAlert{{Fingerprint: "fp2"}}})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "resolve work item")
+	})
+}
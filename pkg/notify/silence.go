@@ -0,0 +1,311 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/webapi"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/workitemtracking"
+	"github.com/pkg/errors"
+	"github.com/stakater/alert-az-do/pkg/alertmanager"
+)
+
+const (
+	// WorkItemUpdatedEventType is the event type of Azure DevOps service hooks sent on work item updates.
+	WorkItemUpdatedEventType = "workitem.updated"
+
+	// silenceRequestTagPrefix prefixes the tag requesting a silence, e.g. "silence:4h". Azure DevOps tags are case
+	// insensitive, so it is matched as such.
+	silenceRequestTagPrefix = "silence:"
+
+	// silenceIDTagPrefix prefixes the tags holding the IDs of the silences created for a work item.
+	silenceIDTagPrefix = "SilenceID:"
+
+	// defaultSilenceCreatedBy is the author of silences when silence.created_by is not set.
+	defaultSilenceCreatedBy = "alert-az-do"
+)
+
+// Silencer creates and expires Alertmanager silences. It is implemented by alertmanager.Client.
+type Silencer interface {
+	ListAlertGroups(ctx context.Context, receiver string) ([]alertmanager.AlertGroup, error)
+	CreateSilence(ctx context.Context, silence alertmanager.Silence) (string, error)
+	ExpireSilence(ctx context.Context, id string) error
+}
+
+// WorkItemUpdatedEvent is the payload of an Azure DevOps "workitem.updated" service hook.
+type WorkItemUpdatedEvent struct {
+	EventType string         `json:"eventType"`
+	Resource  WorkItemUpdate `json:"resource"`
+}
+
+// WorkItemUpdate is the resource of a "workitem.updated" event: the changed fields and the revision they led to.
+type WorkItemUpdate struct {
+	WorkItemID int                    `json:"workItemId"`
+	Fields     map[string]FieldChange `json:"fields"`
+	Revision   WorkItemRevision       `json:"revision"`
+}
+
+// FieldChange is the change of a single work item field.
+type FieldChange struct {
+	OldValue interface{} `json:"oldValue"`
+	NewValue interface{} `json:"newValue"`
+}
+
+// WorkItemRevision is the state of the work item after an update.
+type WorkItemRevision struct {
+	ID     int                    `json:"id"`
+	Fields map[string]interface{} `json:"fields"`
+}
+
+// ReceiverName returns the name of the receiver that created the updated work item, or an empty string when the
+// work item was not created by a receiver that tags its work items.
+func (e *WorkItemUpdatedEvent) ReceiverName() string {
+	for _, tag := range splitTags(e.Resource.Revision.Fields[WorkItemFieldTags.String()]) {
+		if strings.HasPrefix(tag, receiverTagPrefix) {
+			return strings.TrimPrefix(tag, receiverTagPrefix)
+		}
+	}
+	return ""
+}
+
+// HandleWorkItemUpdate creates an Alertmanager silence when the work item moves to the acknowledged state or gets a
+// "silence:<duration>" tag, and expires the work item's silences when it is closed. The event only identifies the
+// work item: its tags, state and project are read from Azure DevOps, so that a forged event cannot silence alerts.
+func (r *Receiver) HandleWorkItemUpdate(ctx context.Context, event *WorkItemUpdatedEvent, silencer Silencer) error {
+	if r.conf.Silence == nil {
+		return nil
+	}
+
+	update := event.Resource
+	id := update.WorkItemID
+	if id == 0 {
+		id = update.Revision.ID
+	}
+	workItem, err := r.client.GetWorkItem(ctx, workitemtracking.GetWorkItemArgs{Id: &id})
+	if err != nil {
+		return errors.Wrap(err, "get work item")
+	}
+	if workItem.Fields == nil {
+		return errors.Errorf("work item %d has no fields", id)
+	}
+	fields := *workItem.Fields
+	if !containsFold(splitTags(fields[WorkItemFieldTags.String()]), r.receiverTag()) {
+		level.Warn(r.logger).Log("msg", "ignoring update of work item not created by the receiver", "id", id)
+		return nil
+	}
+	project, _ := fields[WorkItemFieldTeamProject.String()].(string)
+	state, _ := fields[WorkItemFieldState.String()].(string)
+	_, stateChanged := update.Fields[WorkItemFieldState.String()]
+	tags := splitTags(fields[WorkItemFieldTags.String()])
+
+	if stateChanged && containsString(r.silenceClosedStates(), state) {
+		return r.expireSilences(ctx, silencer, project, id, tags)
+	}
+
+	for _, tag := range tags {
+		if !hasPrefixFold(tag, silenceRequestTagPrefix) {
+			continue
+		}
+		duration, err := time.ParseDuration(tag[len(silenceRequestTagPrefix):])
+		if err != nil || duration <= 0 {
+			level.Warn(r.logger).Log("msg", "ignoring invalid silence tag", "id", id, "tag", tag)
+			continue
+		}
+		return r.createSilence(ctx, silencer, project, id, tags, duration)
+	}
+
+	if stateChanged && state == r.conf.Silence.AcknowledgedState && r.conf.Silence.AcknowledgedState != "" && len(silenceIDs(tags)) == 0 {
+		return r.createSilence(ctx, silencer, project, id, tags, *r.conf.Silence.Duration)
+	}
+	return nil
+}
+
+// createSilence silences the alert group of the work item's alerts for the given duration. Silences created earlier
+// for the work item are replaced.
+func (r *Receiver) createSilence(ctx context.Context, silencer Silencer, project string, id int, tags []string, duration time.Duration) error {
+	fingerprints := map[string]bool{}
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, fingerprintTagPrefix) {
+			fingerprints[strings.TrimPrefix(tag, fingerprintTagPrefix)] = true
+		}
+	}
+
+	groups, err := silencer.ListAlertGroups(ctx, r.conf.Name)
+	if err != nil {
+		return errors.Wrap(err, "list alert groups")
+	}
+	matchers := silenceMatchers(groups, fingerprints)
+
+	remaining := withoutSilenceRequests(tags)
+	if len(matchers) == 0 {
+		level.Warn(r.logger).Log("msg", "no active alerts to silence", "id", id)
+		if err := r.replaceTags(ctx, project, id, remaining); err != nil {
+			return err
+		}
+		return r.postComment(ctx, project, id, "No silence was created: none of the work item's alerts is active in Alertmanager.")
+	}
+
+	createdBy := r.conf.Silence.CreatedBy
+	if createdBy == "" {
+		createdBy = defaultSilenceCreatedBy
+	}
+	now := time.Now().UTC()
+	silenceID, err := silencer.CreateSilence(ctx, alertmanager.Silence{
+		Matchers:  matchers,
+		StartsAt:  now,
+		EndsAt:    now.Add(duration),
+		CreatedBy: createdBy,
+		Comment:   fmt.Sprintf("Acknowledged in Azure DevOps work item #%d", id),
+	})
+	if err != nil {
+		return errors.Wrap(err, "create silence")
+	}
+	level.Info(r.logger).Log("msg", "silence created", "id", id, "silence", silenceID, "duration", duration)
+
+	for _, old := range silenceIDs(remaining) {
+		if err := silencer.ExpireSilence(ctx, old); err != nil {
+			level.Warn(r.logger).Log("msg", "failed to expire replaced silence", "id", id, "silence", old, "err", err)
+		}
+	}
+	if err := r.replaceTags(ctx, project, id, append(withoutSilenceIDs(remaining), silenceIDTagPrefix+silenceID)); err != nil {
+		return err
+	}
+
+	var pairs []string
+	for _, m := range matchers {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", m.Name, m.Value))
+	}
+	comment := fmt.Sprintf("Silenced in Alertmanager until %s (silence ID %s), matching %s.",
+		now.Add(duration).Format(time.RFC3339), silenceID, strings.Join(pairs, ", "))
+	return r.postComment(ctx, project, id, comment)
+}
+
+// expireSilences expires the silences created for the work item.
+func (r *Receiver) expireSilences(ctx context.Context, silencer Silencer, project string, id int, tags []string) error {
+	ids := silenceIDs(tags)
+	if len(ids) == 0 {
+		return nil
+	}
+	for _, silenceID := range ids {
+		// The silence may have ended or been expired by hand already.
+		if err := silencer.ExpireSilence(ctx, silenceID); err != nil {
+			level.Warn(r.logger).Log("msg", "failed to expire silence", "id", id, "silence", silenceID, "err", err)
+			continue
+		}
+		level.Info(r.logger).Log("msg", "silence expired", "id", id, "silence", silenceID)
+	}
+	if err := r.replaceTags(ctx, project, id, withoutSilenceIDs(tags)); err != nil {
+		return err
+	}
+	return r.postComment(ctx, project, id, fmt.Sprintf("Expired Alertmanager silence ID %s.", strings.Join(ids, ", ")))
+}
+
+func (r *Receiver) replaceTags(ctx context.Context, project string, id int, tags []string) error {
+	if _, err := r.client.UpdateWorkItem(ctx, workitemtracking.UpdateWorkItemArgs{
+		Id:      &id,
+		Project: &project,
+		Document: &[]webapi.JsonPatchOperation{{
+			Op:    &webapi.OperationValues.Replace,
+			Path:  stringPtr(WorkItemFieldTags.FieldPath()),
+			Value: strings.Join(tags, "; "),
+		}},
+	}); err != nil {
+		return errors.Wrap(err, "update work item tags")
+	}
+	return nil
+}
+
+// silenceClosedStates returns the states in which the work item's silences are expired.
+func (r *Receiver) silenceClosedStates() []string {
+	if len(r.conf.Silence.ClosedStates) > 0 {
+		return r.conf.Silence.ClosedStates
+	}
+	if r.conf.AutoResolve != nil {
+		return []string{r.conf.AutoResolve.State}
+	}
+	return nil
+}
+
+// silenceMatchers returns equality matchers on the labels of the first alert group containing one of the
+// fingerprints. Groups without labels (i.e. not grouped by any label) are matched on the alert's labels instead.
+func silenceMatchers(groups []alertmanager.AlertGroup, fingerprints map[string]bool) []alertmanager.Matcher {
+	for _, group := range groups {
+		for _, alert := range group.Alerts {
+			if !fingerprints[alert.Fingerprint] {
+				continue
+			}
+			labels := group.Labels
+			if len(labels) == 0 {
+				labels = alert.Labels
+			}
+			var matchers []alertmanager.Matcher
+			for _, pair := range labels.SortedPairs() {
+				matchers = append(matchers, alertmanager.Matcher{Name: pair.Name, Value: pair.Value, IsEqual: true})
+			}
+			return matchers
+		}
+	}
+	return nil
+}
+
+// silenceIDs returns the IDs of the silences recorded in the tags.
+func silenceIDs(tags []string) []string {
+	var ids []string
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, silenceIDTagPrefix) {
+			ids = append(ids, strings.TrimPrefix(tag, silenceIDTagPrefix))
+		}
+	}
+	return ids
+}
+
+func withoutSilenceIDs(tags []string) []string {
+	var res []string
+	for _, tag := range tags {
+		if !strings.HasPrefix(tag, silenceIDTagPrefix) {
+			res = append(res, tag)
+		}
+	}
+	return res
+}
+
+func withoutSilenceRequests(tags []string) []string {
+	var res []string
+	for _, tag := range tags {
+		if !hasPrefixFold(tag, silenceRequestTagPrefix) {
+			res = append(res, tag)
+		}
+	}
+	return res
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+// containsFold reports whether the tags contain the tag, compared case-insensitively as Azure DevOps does.
+func containsFold(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,294 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stakater/alert-az-do/pkg/alertmanager"
+	"github.com/stakater/alert-az-do/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSilencer struct {
+	groups         []alertmanager.AlertGroup
+	created        []alertmanager.Silence
+	expired        []string
+	shouldFailList bool
+}
+
+func (m *mockSilencer) ListAlertGroups(_ context.Context, _ string) ([]alertmanager.AlertGroup, error) {
+	if m.shouldFailList {
+		return nil, errors.New("mock list alert groups failed")
+	}
+	return m.groups, nil
+}
+
+func (m *mockSilencer) CreateSilence(_ context.Context, silence alertmanager.Silence) (string, error) {
+	m.created = append(m.created, silence)
+	return fmt.Sprintf("silence-%d", len(m.created)), nil
+}
+
+func (m *mockSilencer) ExpireSilence(_ context.Context, id string) error {
+	m.expired = append(m.expired, id)
+	return nil
+}
+
+func testSilenceReceiver(client *mockWorkItemTrackingClient) *Receiver {
+	duration := 2 * time.Hour
+	conf := testReceiverConfig1()
+	conf.Name = "team-a"
+	conf.AutoResolve = &config.AutoResolve{State: "Closed"}
+	conf.Silence = &config.Silence{AcknowledgedState: "Active", Duration: &duration}
+	return newTestReceiver(client, conf)
+}
+
+func testSilenceData() *alertmanager.Data {
+	return &alertmanager.Data{
+		Status: alertmanager.AlertFiring,
+		Alerts: alertmanager.Alerts{{Status: alertmanager.AlertFiring, Fingerprint: "fp1"}},
+	}
+}
+
+func testSilencer() *mockSilencer {
+	return &mockSilencer{groups: []alertmanager.AlertGroup{
+		{
+			Labels: alertmanager.KV{"alertname": "Other"},
+			Alerts: []alertmanager.GettableAlert{{Fingerprint: "other"}},
+		},
+		{
+			Labels: alertmanager.KV{"alertname": "HighLatency", "service": "api"},
+			Alerts: []alertmanager.GettableAlert{{Fingerprint: "fp1"}, {Fingerprint: "fp2"}},
+		},
+	}}
+}
+
+// testWorkItemUpdatedEvent returns an event as sent by Azure DevOps when the work item state or tags changed, after
+// storing the work item's new fields in the mock.
+func testWorkItemUpdatedEvent(t *testing.T, client *mockWorkItemTrackingClient, state, tags string, stateChanged bool) *WorkItemUpdatedEvent {
+	fields := map[string]interface{}{
+		"System.TeamProject": "TestProject",
+		"System.State":       state,
+		"System.Tags":        tags,
+	}
+	changes := map[string]interface{}{"System.Tags": map[string]interface{}{"newValue": tags}}
+	if stateChanged {
+		changes["System.State"] = map[string]interface{}{"oldValue": "New", "newValue": state}
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"eventType": "workitem.updated",
+		"resource": map[string]interface{}{
+			"workItemId": 1,
+			"fields":     changes,
+			"revision":   map[string]interface{}{"id": 1, "fields": fields},
+		},
+	})
+	require.NoError(t, err)
+
+	var event WorkItemUpdatedEvent
+	require.NoError(t, json.Unmarshal(payload, &event))
+
+	if workItem, ok := client.workItems[1]; ok {
+		for k, v := range fields {
+			(*workItem.Fields)[k] = v
+		}
+	}
+	return &event
+}
+
+func TestWorkItemUpdatedEvent_ReceiverName(t *testing.T) {
+	client := newMockWorkItemTrackingClient()
+	event := testWorkItemUpdatedEvent(t, client, "New", "Fingerprint:fp1; Receiver:team-a", false)
+	require.Equal(t, "team-a", event.ReceiverName())
+
+	event = testWorkItemUpdatedEvent(t, client, "New", "Fingerprint:fp1", false)
+	require.Empty(t, event.ReceiverName())
+}
+
+func TestReceiver_HandleWorkItemUpdate_AcknowledgeAndClose(t *testing.T) {
+	mockClient := newMockWorkItemTrackingClient()
+	receiver := testSilenceReceiver(mockClient)
+	silencer := testSilencer()
+	ctx := context.Background()
+
+	require.NoError(t, receiver.Notify(ctx, &alertmanager.Data{
+		Status:      alertmanager.AlertFiring,
+		Alerts:      alertmanager.Alerts{{Status: alertmanager.AlertFiring, Fingerprint: "fp1"}},
+		GroupLabels: alertmanager.KV{"alertname": "HighLatency"},
+	}))
+	tags := (*mockClient.workItems[1].Fields)["System.Tags"].(string)
+	require.Equal(t, "Fingerprint:fp1; Receiver:team-a", tags)
+
+	// Moving the work item to the acknowledged state silences the alert group.
+	require.NoError(t, receiver.HandleWorkItemUpdate(ctx, testWorkItemUpdatedEvent(t, mockClient, "Active", tags, true), silencer))
+	require.Len(t, silencer.created, 1)
+	silence := silencer.created[0]
+	require.Equal(t, []alertmanager.Matcher{
+		{Name: "alertname", Value: "HighLatency", IsEqual: true},
+		{Name: "service", Value: "api", IsEqual: true},
+	}, silence.Matchers)
+	require.Equal(t, 2*time.Hour, silence.EndsAt.Sub(silence.StartsAt))
+	require.Equal(t, "alert-az-do", silence.CreatedBy)
+	require.Equal(t, "Acknowledged in Azure DevOps work item #1", silence.Comment)
+
+	tags = (*mockClient.workItems[1].Fields)["System.Tags"].(string)
+	require.Equal(t, "Fingerprint:fp1; Receiver:team-a; SilenceID:silence-1", tags)
+	require.Len(t, mockClient.commentCalls, 1)
+	require.Contains(t, *mockClient.commentCalls[0].Request.Text, `(silence ID silence-1), matching alertname="HighLatency", service="api".`)
+
+	// The tag update triggers another event, which must not create another silence.
+	require.NoError(t, receiver.HandleWorkItemUpdate(ctx, testWorkItemUpdatedEvent(t, mockClient, "Active", tags, false), silencer))
+	require.Len(t, silencer.created, 1)
+
+	// Closing the work item expires the silence.
+	require.NoError(t, receiver.HandleWorkItemUpdate(ctx, testWorkItemUpdatedEvent(t, mockClient, "Closed", tags, true), silencer))
+	require.Equal(t, []string{"silence-1"}, silencer.expired)
+	require.Equal(t, "Fingerprint:fp1; Receiver:team-a", (*mockClient.workItems[1].Fields)["System.Tags"])
+	require.Equal(t, "Expired Alertmanager silence ID silence-1.", *mockClient.commentCalls[1].Request.Text)
+}
+
+func TestReceiver_HandleWorkItemUpdate_SilenceTag(t *testing.T) {
+	mockClient := newMockWorkItemTrackingClient()
+	receiver := testSilenceReceiver(mockClient)
+	silencer := testSilencer()
+	ctx := context.Background()
+
+	require.NoError(t, receiver.Notify(ctx, &alertmanager.Data{
+		Status: alertmanager.AlertFiring,
+		Alerts: alertmanager.Alerts{{Status: alertmanager.AlertFiring, Fingerprint: "fp2"}},
+	}))
+
+	event := testWorkItemUpdatedEvent(t, mockClient, "New", "Fingerprint:fp2; Receiver:team-a; SilenceID:old; Silence:30m", false)
+	require.NoError(t, receiver.HandleWorkItemUpdate(ctx, event, silencer))
+	require.Len(t, silencer.created, 1)
+	require.Equal(t, 30*time.Minute, silencer.created[0].EndsAt.Sub(silencer.created[0].StartsAt))
+
+	// The requested silence replaces the earlier one, and the request tag is removed so it can be added again.
+	require.Equal(t, []string{"old"}, silencer.expired)
+	require.Equal(t, "Fingerprint:fp2; Receiver:team-a; SilenceID:silence-1", (*mockClient.workItems[1].Fields)["System.Tags"])
+}
+
+func TestReceiver_HandleWorkItemUpdate_NoActiveAlerts(t *testing.T) {
+	mockClient := newMockWorkItemTrackingClient()
+	receiver := testSilenceReceiver(mockClient)
+	silencer := &mockSilencer{}
+	ctx := context.Background()
+
+	require.NoError(t, receiver.Notify(ctx, testSilenceData()))
+
+	event := testWorkItemUpdatedEvent(t, mockClient, "New", "Fingerprint:fp1; Receiver:team-a; silence:1h", false)
+	require.NoError(t, receiver.HandleWorkItemUpdate(ctx, event, silencer))
+	require.Empty(t, silencer.created)
+	require.Equal(t, "Fingerprint:fp1; Receiver:team-a", (*mockClient.workItems[1].Fields)["System.Tags"])
+	require.Equal(t, "No silence was created: none of the work item's alerts is active in Alertmanager.", *mockClient.commentCalls[0].Request.Text)
+}
+
+func TestReceiver_HandleWorkItemUpdate_Ignored(t *testing.T) {
+	mockClient := newMockWorkItemTrackingClient()
+	receiver := testSilenceReceiver(mockClient)
+	silencer := testSilencer()
+	ctx := context.Background()
+
+	require.NoError(t, receiver.Notify(ctx, testSilenceData()))
+	for _, event := range []func() *WorkItemUpdatedEvent{
+		// Acknowledged state without a state change.
+		func() *WorkItemUpdatedEvent {
+			return testWorkItemUpdatedEvent(t, mockClient, "Active", "Fingerprint:fp1; Receiver:team-a", false)
+		},
+		// Invalid silence duration.
+		func() *WorkItemUpdatedEvent {
+			return testWorkItemUpdatedEvent(t, mockClient, "New", "Fingerprint:fp1; Receiver:team-a; silence:forever", false)
+		},
+		// Closed without silences.
+		func() *WorkItemUpdatedEvent {
+			return testWorkItemUpdatedEvent(t, mockClient, "Closed", "Fingerprint:fp1; Receiver:team-a", true)
+		},
+		// Work item not created by the receiver.
+		func() *WorkItemUpdatedEvent {
+			return testWorkItemUpdatedEvent(t, mockClient, "New", "Fingerprint:fp1; Receiver:team-b; silence:1h", false)
+		},
+	} {
+		require.NoError(t, receiver.HandleWorkItemUpdate(ctx, event(), silencer))
+	}
+	require.Empty(t, silencer.created)
+	require.Empty(t, silencer.expired)
+	require.Empty(t, mockClient.updateCalls)
+
+	receiver.conf.Silence = nil
+	require.NoError(t, receiver.HandleWorkItemUpdate(ctx, testWorkItemUpdatedEvent(t, mockClient, "Active", "Fingerprint:fp1; Receiver:team-a", true), silencer))
+	require.Empty(t, silencer.created)
+}
+
+func TestReceiver_HandleWorkItemUpdate_ForgedEvent(t *testing.T) {
+	mockClient := newMockWorkItemTrackingClient()
+	receiver := testSilenceReceiver(mockClient)
+	silencer := testSilencer()
+	ctx := context.Background()
+
+	require.NoError(t, receiver.Notify(ctx, testSilenceData()))
+
+	// The tags and state of the event are not those of the work item in Azure DevOps.
+	event := testWorkItemUpdatedEvent(t, mockClient, "New", "Fingerprint:fp1; Receiver:team-a", false)
+	event.Resource.Revision.Fields["System.Tags"] = "Fingerprint:fp1; Fingerprint:fp2; Receiver:team-a; silence:8760h"
+	event.Resource.Revision.Fields["System.State"] = "Active"
+	event.Resource.Fields["System.State"] = FieldChange{OldValue: "New", NewValue: "Active"}
+	require.NoError(t, receiver.HandleWorkItemUpdate(ctx, event, silencer))
+	require.Empty(t, silencer.created)
+
+	event.Resource.WorkItemID = 42
+	require.EqualError(t, receiver.HandleWorkItemUpdate(ctx, event, silencer), "get work item: work item 42 not found")
+}
+
+func TestReceiver_HandleWorkItemUpdate_ErrorPaths(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("list alert groups failure", func(t *testing.T) {
+		mockClient := newMockWorkItemTrackingClient()
+		receiver := testSilenceReceiver(mockClient)
+		silencer := &mockSilencer{shouldFailList: true}
+		require.NoError(t, receiver.Notify(ctx, testSilenceData()))
+
+		err := receiver.HandleWorkItemUpdate(ctx, testWorkItemUpdatedEvent(t, mockClient, "Active", "Fingerprint:fp1; Receiver:team-a", true), silencer)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "list alert groups")
+	})
+
+	t.Run("update failure", func(t *testing.T) {
+		mockClient := newMockWorkItemTrackingClient()
+		receiver := testSilenceReceiver(mockClient)
+		require.NoError(t, receiver.Notify(ctx, testSilenceData()))
+		mockClient.shouldFailUpdate = true
+
+		err := receiver.HandleWorkItemUpdate(ctx, testWorkItemUpdatedEvent(t, mockClient, "Active", "Fingerprint:fp1; Receiver:team-a", true), testSilencer())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "update work item tags")
+	})
+}
+
+func TestSilenceMatchers_UngroupedAlerts(t *testing.T) {
+	groups := []alertmanager.AlertGroup{{
+		Alerts: []alertmanager.GettableAlert{{Fingerprint: "fp1", Labels: alertmanager.KV{"alertname": "A", "pod": "p"}}},
+	}}
+	require.Equal(t, []alertmanager.Matcher{
+		{Name: "alertname", Value: "A", IsEqual: true},
+		{Name: "pod", Value: "p", IsEqual: true},
+	}, silenceMatchers(groups, map[string]bool{"fp1": true}))
+	require.Nil(t, silenceMatchers(groups, map[string]bool{"fp2": true}))
+}
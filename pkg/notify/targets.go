@@ -0,0 +1,100 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/stakater/alert-az-do/pkg/alertmanager"
+	"github.com/stakater/alert-az-do/pkg/config"
+)
+
+// targetAttempts is how often a failing target is notified of a notification in total.
+const targetAttempts = 3
+
+// targetBackoff is the delay before retrying the failed targets, doubled on every further retry.
+var targetBackoff = time.Second
+
+// NewReceiverFunc creates the receiver of a target configuration.
+type NewReceiverFunc func(ctx context.Context, logger log.Logger, conf *config.ReceiverConfig) (*Receiver, error)
+
+// TargetsError is returned when some targets of a receiver could not be notified. Failed holds the errors of the
+// failed targets by name; the other targets were notified.
+type TargetsError struct {
+	Targets int
+	Failed  map[string]error
+}
+
+func (e *TargetsError) Error() string {
+	names := make([]string, 0, len(e.Failed))
+	for name := range e.Failed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	errs := make([]string, 0, len(names))
+	for _, name := range names {
+		errs = append(errs, fmt.Sprintf("target %q: %s", name, e.Failed[name]))
+	}
+	return fmt.Sprintf("%d of %d targets failed: %s", len(e.Failed), e.Targets, strings.Join(errs, "; "))
+}
+
+// NotifyTargets notifies the receivers of all targets of the receiver configuration, each creating and updating the
+// work items in its own project. Failed targets are retried with backoff, up to targetAttempts times in total, without
+// notifying the other targets again. The targets still failing then are returned in a *TargetsError.
+func NotifyTargets(ctx context.Context, logger log.Logger, conf *config.ReceiverConfig, data *alertmanager.Data, newReceiver NewReceiverFunc) error {
+	targets := conf.TargetConfigs()
+	pending := targets
+	backoff := targetBackoff
+	for attempt := 1; ; attempt++ {
+		var retry []*config.ReceiverConfig
+		failed := map[string]error{}
+		for _, target := range pending {
+			logger := log.With(logger, "target", target.Target)
+			if err := notifyTarget(ctx, logger, target, data, newReceiver); err != nil {
+				level.Warn(logger).Log("msg", "failed to notify target", "attempt", attempt, "err", err)
+				failed[target.Target] = err
+				retry = append(retry, target)
+			}
+		}
+		if len(retry) == 0 {
+			return nil
+		}
+		if attempt == targetAttempts {
+			return &TargetsError{Targets: len(targets), Failed: failed}
+		}
+
+		select {
+		case <-ctx.Done():
+			return &TargetsError{Targets: len(targets), Failed: failed}
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		pending = retry
+	}
+}
+
+func notifyTarget(ctx context.Context, logger log.Logger, conf *config.ReceiverConfig, data *alertmanager.Data, newReceiver NewReceiverFunc) error {
+	receiver, err := newReceiver(ctx, logger, conf)
+	if err != nil {
+		return err
+	}
+	return receiver.Notify(ctx, data)
+}
@@ -0,0 +1,115 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/pkg/errors"
+	"github.com/stakater/alert-az-do/pkg/alertmanager"
+	"github.com/stakater/alert-az-do/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func testTargetsConfig() *config.ReceiverConfig {
+	conf := testReceiverConfig1()
+	conf.Name = "security"
+	conf.Targets = []*config.Target{
+		{Name: "team"},
+		{Name: "secops", Organization: "secops-org", Project: "SecOps", IssueType: "Incident"},
+	}
+	return conf
+}
+
+func testTargetsData() *alertmanager.Data {
+	return &alertmanager.Data{
+		Status: alertmanager.AlertFiring,
+		Alerts: alertmanager.Alerts{
+			{Status: alertmanager.AlertFiring, Labels: alertmanager.KV{"alertname": "SuspiciousLogin"}, Fingerprint: "fp1"},
+		},
+		GroupLabels: alertmanager.KV{"alertname": "SuspiciousLogin"},
+	}
+}
+
+// testTargetReceivers returns a NewReceiverFunc creating receivers with a mock client per target, and counting the
+// receivers created per target.
+func testTargetReceivers(clients map[string]*mockWorkItemTrackingClient, created map[string]int) NewReceiverFunc {
+	return func(_ context.Context, _ log.Logger, conf *config.ReceiverConfig) (*Receiver, error) {
+		created[conf.Target]++
+		client, ok := clients[conf.Target]
+		if !ok {
+			return nil, errors.New("connection refused")
+		}
+		return newTestReceiver(client, conf), nil
+	}
+}
+
+func TestNotifyTargets(t *testing.T) {
+	clients := map[string]*mockWorkItemTrackingClient{
+		"team":   newMockWorkItemTrackingClient(),
+		"secops": newMockWorkItemTrackingClient(),
+	}
+	created := map[string]int{}
+
+	err := NotifyTargets(context.Background(), log.NewNopLogger(), testTargetsConfig(), testTargetsData(), testTargetReceivers(clients, created))
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"team": 1, "secops": 1}, created)
+
+	require.Len(t, clients["team"].createCalls, 1)
+	require.Equal(t, "TestProject", *clients["team"].createCalls[0].args.Project)
+	require.Equal(t, "Bug", *clients["team"].createCalls[0].args.Type)
+	require.Len(t, clients["secops"].createCalls, 1)
+	require.Equal(t, "SecOps", *clients["secops"].createCalls[0].args.Project)
+	require.Equal(t, "Incident", *clients["secops"].createCalls[0].args.Type)
+}
+
+func TestNotifyTargets_RetriesFailedTargets(t *testing.T) {
+	defer func(backoff time.Duration) { targetBackoff = backoff }(targetBackoff)
+	targetBackoff = 0
+
+	clients := map[string]*mockWorkItemTrackingClient{
+		"team":   newMockWorkItemTrackingClient(),
+		"secops": newMockWorkItemTrackingClient(),
+	}
+	clients["secops"].shouldFailCreate = true
+	created := map[string]int{}
+
+	err := NotifyTargets(context.Background(), log.NewNopLogger(), testTargetsConfig(), testTargetsData(), testTargetReceivers(clients, created))
+	var targetsErr *TargetsError
+	require.ErrorAs(t, err, &targetsErr)
+	require.Equal(t, 2, targetsErr.Targets)
+	require.Len(t, targetsErr.Failed, 1)
+	require.EqualError(t, err, `1 of 2 targets failed: target "secops": create work item: mock create work item failed`)
+
+	// Only the failed target was retried.
+	require.Equal(t, map[string]int{"team": 1, "secops": targetAttempts}, created)
+	require.Len(t, clients["team"].createCalls, 1)
+	require.Len(t, clients["secops"].createCalls, targetAttempts)
+}
+
+func TestNotifyTargets_ConnectionFailure(t *testing.T) {
+	defer func(backoff time.Duration) { targetBackoff = backoff }(targetBackoff)
+	targetBackoff = 0
+
+	clients := map[string]*mockWorkItemTrackingClient{"team": newMockWorkItemTrackingClient()}
+	created := map[string]int{}
+
+	err := NotifyTargets(context.Background(), log.NewNopLogger(), testTargetsConfig(), testTargetsData(), testTargetReceivers(clients, created))
+	require.EqualError(t, err, `1 of 2 targets failed: target "secops": connection refused`)
+	require.Len(t, clients["team"].createCalls, 1)
+}
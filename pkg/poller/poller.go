@@ -0,0 +1,285 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package poller pulls alert groups from the Alertmanager API and turns them into webhook notifications, for setups
+// where Alertmanager cannot reach alert-az-do.
+package poller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/stakater/alert-az-do/pkg/alertmanager"
+)
+
+const (
+	// webhookVersion is the webhook protocol version of the synthesized notifications.
+	webhookVersion = "4"
+
+	// alertStateSuppressed is the state of silenced or inhibited alerts, which Alertmanager does not notify about.
+	alertStateSuppressed = "suppressed"
+)
+
+// GroupLister lists the alert groups of a receiver. It is implemented by alertmanager.Client.
+type GroupLister interface {
+	ListAlertGroups(ctx context.Context, receiver string) ([]alertmanager.AlertGroup, error)
+}
+
+// DispatchFunc handles a synthesized notification, the same way as one received through the webhook.
+type DispatchFunc func(ctx context.Context, data *alertmanager.Data) error
+
+// group is the last notified state of an alert group: its notified firing alerts, by fingerprint.
+type group struct {
+	data   *alertmanager.Data
+	alerts map[string]alertmanager.Alert
+}
+
+// Poller polls the alert groups of the given receivers and dispatches a notification whenever a group changes: when
+// alerts start firing, and when alerts (or the whole group) vanish, which is notified as their resolution.
+type Poller struct {
+	logger      log.Logger
+	lister      GroupLister
+	receivers   []string
+	externalURL string
+	dispatch    DispatchFunc
+
+	groups map[string]*group
+}
+
+// New creates a poller of the alert groups of the given receivers.
+func New(logger log.Logger, lister GroupLister, receivers []string, externalURL string, dispatch DispatchFunc) *Poller {
+	return &Poller{
+		logger:      logger,
+		lister:      lister,
+		receivers:   receivers,
+		externalURL: externalURL,
+		dispatch:    dispatch,
+		groups:      map[string]*group{},
+	}
+}
+
+// Run polls every interval until the context is done.
+func (p *Poller) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := p.Poll(ctx); err != nil {
+			level.Error(p.logger).Log("msg", "failed to poll alert groups", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll polls the alert groups of all receivers once and dispatches the changed groups. A group whose dispatch failed
+// is dispatched again on the next poll.
+func (p *Poller) Poll(ctx context.Context) error {
+	var errs []string
+	for _, receiver := range p.receivers {
+		if err := p.poll(ctx, receiver); err != nil {
+			errs = append(errs, fmt.Sprintf("receiver %q: %s", receiver, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (p *Poller) poll(ctx context.Context, receiver string) error {
+	groups, err := p.lister.ListAlertGroups(ctx, receiver)
+	if err != nil {
+		// Without the current groups, vanished groups cannot be told apart: do not resolve anything.
+		return errors.Wrap(err, "list alert groups")
+	}
+
+	var errs []string
+	seen := map[string]bool{}
+	for _, g := range groups {
+		if g.Receiver.Name != "" && g.Receiver.Name != receiver {
+			continue
+		}
+		key := groupKey(receiver, g.Labels)
+		seen[key] = true
+
+		data := p.notification(receiver, key, g)
+		previous := p.groups[key]
+		if previous != nil {
+			// Notified alerts that vanished from the group are resolved.
+			for fingerprint, alert := range previous.alerts {
+				if !containsAlert(g.Alerts, fingerprint) {
+					alert.Status = alertmanager.AlertResolved
+					alert.EndsAt = time.Now().UTC()
+					data.Alerts = append(data.Alerts, alert)
+				}
+			}
+			sortAlerts(data.Alerts)
+		}
+		if !changed(previous, data) {
+			continue
+		}
+		if len(data.Alerts) == 0 {
+			// The group only has suppressed alerts, which Alertmanager would not notify about either.
+			continue
+		}
+
+		if err := p.dispatch(ctx, data); err != nil {
+			errs = append(errs, fmt.Sprintf("group %s: %s", key, err))
+			continue
+		}
+		level.Debug(p.logger).Log("msg", "alert group dispatched", "receiver", receiver, "group", key, "firing", len(data.Alerts.Firing()), "resolved", len(data.Alerts.Resolved()))
+		p.remember(key, data, previous, g)
+	}
+
+	// Groups that vanished entirely are resolved.
+	for key, previous := range p.groups {
+		if previous.data.Receiver != receiver || seen[key] {
+			continue
+		}
+		data := *previous.data
+		data.Status = alertmanager.AlertResolved
+		data.Alerts = nil
+		now := time.Now().UTC()
+		for _, alert := range previous.alerts {
+			alert.Status = alertmanager.AlertResolved
+			alert.EndsAt = now
+			data.Alerts = append(data.Alerts, alert)
+		}
+		sortAlerts(data.Alerts)
+
+		if err := p.dispatch(ctx, &data); err != nil {
+			errs = append(errs, fmt.Sprintf("group %s: %s", key, err))
+			continue
+		}
+		level.Debug(p.logger).Log("msg", "resolved alert group dispatched", "receiver", receiver, "group", key)
+		delete(p.groups, key)
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// notification synthesizes the webhook notification of the firing alerts of the group. Suppressed alerts are left
+// out, as Alertmanager does not notify about them either.
+func (p *Poller) notification(receiver, key string, g alertmanager.AlertGroup) *alertmanager.Data {
+	data := &alertmanager.Data{
+		Version:     webhookVersion,
+		GroupKey:    key,
+		Receiver:    receiver,
+		Status:      alertmanager.AlertFiring,
+		GroupLabels: g.Labels,
+		ExternalURL: p.externalURL,
+	}
+	for _, a := range g.Alerts {
+		if a.Status.State == alertStateSuppressed {
+			continue
+		}
+		data.Alerts = append(data.Alerts, alertmanager.Alert{
+			Status:       alertmanager.AlertFiring,
+			Labels:       a.Labels,
+			Annotations:  a.Annotations,
+			StartsAt:     a.StartsAt,
+			EndsAt:       a.EndsAt,
+			GeneratorURL: a.GeneratorURL,
+			Fingerprint:  a.Fingerprint,
+		})
+	}
+	sortAlerts(data.Alerts)
+
+	var labels, annotations []alertmanager.KV
+	for _, a := range data.Alerts {
+		labels = append(labels, a.Labels)
+		annotations = append(annotations, a.Annotations)
+	}
+	data.CommonLabels = alertmanager.CommonKV(labels)
+	data.CommonAnnotations = alertmanager.CommonKV(annotations)
+	if len(data.Alerts) == 0 {
+		data.Status = alertmanager.AlertResolved
+	}
+	return data
+}
+
+// remember records the notified state of the group. Only notified alerts are remembered, so that only they are
+// resolved when they vanish: the previously notified alerts that got suppressed since are kept, whereas alerts that
+// were suppressed all along are notified once they become active. Groups without notified alerts are forgotten.
+func (p *Poller) remember(key string, data *alertmanager.Data, previous *group, g alertmanager.AlertGroup) {
+	alerts := map[string]alertmanager.Alert{}
+	for _, a := range data.Alerts.Firing() {
+		alerts[a.Fingerprint] = a
+	}
+	if previous != nil {
+		for fingerprint, alert := range previous.alerts {
+			if _, ok := alerts[fingerprint]; !ok && containsAlert(g.Alerts, fingerprint) {
+				alerts[fingerprint] = alert
+			}
+		}
+	}
+	if len(alerts) == 0 {
+		delete(p.groups, key)
+		return
+	}
+	p.groups[key] = &group{data: data, alerts: alerts}
+}
+
+// changed reports whether the notification differs from the last notified state of the group, i.e. whether alerts
+// started firing, including suppressed alerts that became active, or were resolved.
+func changed(previous *group, data *alertmanager.Data) bool {
+	if previous == nil {
+		return true
+	}
+	if len(data.Alerts.Resolved()) > 0 {
+		return true
+	}
+	for _, a := range data.Alerts.Firing() {
+		if _, ok := previous.alerts[a.Fingerprint]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// groupKey identifies the alert group, in the format used by Alertmanager for webhook notifications.
+func groupKey(receiver string, labels alertmanager.KV) string {
+	var pairs []string
+	for _, pair := range labels.SortedPairs() {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", pair.Name, pair.Value))
+	}
+	return fmt.Sprintf("{}/%s:{%s}", receiver, strings.Join(pairs, ", "))
+}
+
+func containsAlert(alerts []alertmanager.GettableAlert, fingerprint string) bool {
+	for _, a := range alerts {
+		if a.Fingerprint == fingerprint {
+			return true
+		}
+	}
+	return false
+}
+
+func sortAlerts(alerts alertmanager.Alerts) {
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return alerts[i].Fingerprint < alerts[j].Fingerprint
+	})
+}
@@ -0,0 +1,221 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package poller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/stakater/alert-az-do/pkg/alertmanager"
+	"github.com/stretchr/testify/require"
+)
+
+type mockGroupLister struct {
+	groups     map[string][]alertmanager.AlertGroup
+	shouldFail bool
+}
+
+func (m *mockGroupLister) ListAlertGroups(_ context.Context, receiver string) ([]alertmanager.AlertGroup, error) {
+	if m.shouldFail {
+		return nil, errors.New("mock list alert groups failed")
+	}
+	return m.groups[receiver], nil
+}
+
+type dispatcher struct {
+	notifications []*alertmanager.Data
+	shouldFail    bool
+}
+
+func (d *dispatcher) dispatch(_ context.Context, data *alertmanager.Data) error {
+	if d.shouldFail {
+		return errors.New("mock dispatch failed")
+	}
+	d.notifications = append(d.notifications, data)
+	return nil
+}
+
+func alert(fingerprint, state string) alertmanager.GettableAlert {
+	return alertmanager.GettableAlert{
+		Fingerprint: fingerprint,
+		Labels:      alertmanager.KV{"alertname": "HighLatency", "service": "api", "pod": fingerprint},
+		Annotations: alertmanager.KV{"summary": "latency is high"},
+		Status:      alertmanager.AlertStatus{State: state},
+	}
+}
+
+func testGroup(alerts ...alertmanager.GettableAlert) alertmanager.AlertGroup {
+	return alertmanager.AlertGroup{
+		Labels:   alertmanager.KV{"alertname": "HighLatency"},
+		Receiver: alertmanager.Receiver{Name: "team-a"},
+		Alerts:   alerts,
+	}
+}
+
+func TestPoller_Poll(t *testing.T) {
+	lister := &mockGroupLister{groups: map[string][]alertmanager.AlertGroup{}}
+	d := &dispatcher{}
+	p := New(log.NewNopLogger(), lister, []string{"team-a"}, "http://alertmanager:9093", d.dispatch)
+	ctx := context.Background()
+
+	// A new group is dispatched as a firing notification.
+	lister.groups["team-a"] = []alertmanager.AlertGroup{testGroup(alert("fp2", "active"), alert("fp1", "active"))}
+	require.NoError(t, p.Poll(ctx))
+	require.Len(t, d.notifications, 1)
+	data := d.notifications[0]
+	require.Equal(t, "team-a", data.Receiver)
+	require.Equal(t, alertmanager.AlertFiring, data.Status)
+	require.Equal(t, `{}/team-a:{alertname="HighLatency"}`, data.GroupKey)
+	require.Equal(t, alertmanager.KV{"alertname": "HighLatency"}, data.GroupLabels)
+	require.Equal(t, alertmanager.KV{"alertname": "HighLatency", "service": "api"}, data.CommonLabels)
+	require.Equal(t, alertmanager.KV{"summary": "latency is high"}, data.CommonAnnotations)
+	require.Equal(t, "http://alertmanager:9093", data.ExternalURL)
+	require.Equal(t, []string{"Fingerprint:fp1", "Fingerprint:fp2"}, data.Alerts.FiringFingerprints())
+
+	// An unchanged group is not dispatched again.
+	require.NoError(t, p.Poll(ctx))
+	require.Len(t, d.notifications, 1)
+
+	// A vanished alert is resolved.
+	lister.groups["team-a"] = []alertmanager.AlertGroup{testGroup(alert("fp2", "active"))}
+	require.NoError(t, p.Poll(ctx))
+	require.Len(t, d.notifications, 2)
+	data = d.notifications[1]
+	require.Equal(t, alertmanager.AlertFiring, data.Status)
+	require.Equal(t, []string{"Fingerprint:fp2"}, data.Alerts.FiringFingerprints())
+	require.Equal(t, []string{"Fingerprint:fp1"}, data.Alerts.ResolvedFingerprints())
+
+	// A vanished group is resolved as a whole.
+	lister.groups["team-a"] = nil
+	require.NoError(t, p.Poll(ctx))
+	require.Len(t, d.notifications, 3)
+	data = d.notifications[2]
+	require.Equal(t, alertmanager.AlertResolved, data.Status)
+	require.Equal(t, []string{"Fingerprint:fp2"}, data.Alerts.ResolvedFingerprints())
+	require.Empty(t, data.Alerts.Firing())
+
+	require.NoError(t, p.Poll(ctx))
+	require.Len(t, d.notifications, 3)
+}
+
+func TestPoller_Poll_SuppressedAlerts(t *testing.T) {
+	lister := &mockGroupLister{groups: map[string][]alertmanager.AlertGroup{}}
+	d := &dispatcher{}
+	p := New(log.NewNopLogger(), lister, []string{"team-a"}, "", d.dispatch)
+	ctx := context.Background()
+
+	// Groups with suppressed alerts only are not dispatched.
+	lister.groups["team-a"] = []alertmanager.AlertGroup{testGroup(alert("fp1", "suppressed"))}
+	require.NoError(t, p.Poll(ctx))
+	require.Empty(t, d.notifications)
+
+	lister.groups["team-a"] = []alertmanager.AlertGroup{testGroup(alert("fp1", "active"))}
+	require.NoError(t, p.Poll(ctx))
+	require.Len(t, d.notifications, 1)
+
+	// A firing alert that gets silenced is not resolved.
+	lister.groups["team-a"] = []alertmanager.AlertGroup{testGroup(alert("fp1", "suppressed"))}
+	require.NoError(t, p.Poll(ctx))
+	require.Len(t, d.notifications, 1)
+
+	// Suppressed alerts that were never notified are not resolved when they vanish.
+	lister.groups["team-a"] = []alertmanager.AlertGroup{testGroup(alert("fp1", "active"), alert("fp2", "suppressed"))}
+	require.NoError(t, p.Poll(ctx))
+	lister.groups["team-a"] = []alertmanager.AlertGroup{testGroup(alert("fp1", "active"))}
+	require.NoError(t, p.Poll(ctx))
+	require.Len(t, d.notifications, 1)
+}
+
+func TestPoller_Poll_MixedGroup(t *testing.T) {
+	lister := &mockGroupLister{groups: map[string][]alertmanager.AlertGroup{}}
+	d := &dispatcher{}
+	p := New(log.NewNopLogger(), lister, []string{"team-a"}, "", d.dispatch)
+	ctx := context.Background()
+
+	// Only the active alert of a mixed group is notified.
+	lister.groups["team-a"] = []alertmanager.AlertGroup{testGroup(alert("fp1", "active"), alert("fp2", "suppressed"))}
+	require.NoError(t, p.Poll(ctx))
+	require.Len(t, d.notifications, 1)
+	require.Equal(t, []string{"Fingerprint:fp1"}, d.notifications[0].Alerts.FiringFingerprints())
+
+	// The suppressed alert is notified when it becomes active.
+	lister.groups["team-a"] = []alertmanager.AlertGroup{testGroup(alert("fp1", "active"), alert("fp2", "active"))}
+	require.NoError(t, p.Poll(ctx))
+	require.Len(t, d.notifications, 2)
+	require.Equal(t, []string{"Fingerprint:fp1", "Fingerprint:fp2"}, d.notifications[1].Alerts.FiringFingerprints())
+
+	require.NoError(t, p.Poll(ctx))
+	require.Len(t, d.notifications, 2)
+
+	// A notified alert that got suppressed is resolved when it vanishes, an alert suppressed all along is not.
+	lister.groups["team-a"] = []alertmanager.AlertGroup{testGroup(alert("fp1", "suppressed"), alert("fp2", "active"), alert("fp3", "active"), alert("fp4", "suppressed"))}
+	require.NoError(t, p.Poll(ctx))
+	require.Len(t, d.notifications, 3)
+	require.Equal(t, []string{"Fingerprint:fp2", "Fingerprint:fp3"}, d.notifications[2].Alerts.FiringFingerprints())
+	lister.groups["team-a"] = []alertmanager.AlertGroup{testGroup(alert("fp2", "active"), alert("fp3", "active"))}
+	require.NoError(t, p.Poll(ctx))
+	require.Len(t, d.notifications, 4)
+	require.Equal(t, []string{"Fingerprint:fp1"}, d.notifications[3].Alerts.ResolvedFingerprints())
+	lister.groups["team-a"] = nil
+	require.NoError(t, p.Poll(ctx))
+	require.Len(t, d.notifications, 5)
+	require.Equal(t, []string{"Fingerprint:fp2", "Fingerprint:fp3"}, d.notifications[4].Alerts.ResolvedFingerprints())
+}
+
+func TestPoller_Poll_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("list failure resolves nothing", func(t *testing.T) {
+		lister := &mockGroupLister{groups: map[string][]alertmanager.AlertGroup{
+			"team-a": {testGroup(alert("fp1", "active"))},
+		}}
+		d := &dispatcher{}
+		p := New(log.NewNopLogger(), lister, []string{"team-a"}, "", d.dispatch)
+		require.NoError(t, p.Poll(ctx))
+
+		lister.shouldFail = true
+		err := p.Poll(ctx)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `receiver "team-a": list alert groups`)
+		require.Len(t, d.notifications, 1)
+	})
+
+	t.Run("failed dispatch is retried", func(t *testing.T) {
+		lister := &mockGroupLister{groups: map[string][]alertmanager.AlertGroup{
+			"team-a": {testGroup(alert("fp1", "active"))},
+		}}
+		d := &dispatcher{shouldFail: true}
+		p := New(log.NewNopLogger(), lister, []string{"team-a"}, "", d.dispatch)
+
+		err := p.Poll(ctx)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "mock dispatch failed")
+
+		d.shouldFail = false
+		require.NoError(t, p.Poll(ctx))
+		require.Len(t, d.notifications, 1)
+	})
+
+	t.Run("groups of other receivers are ignored", func(t *testing.T) {
+		group := testGroup(alert("fp1", "active"))
+		group.Receiver.Name = "team-b"
+		lister := &mockGroupLister{groups: map[string][]alertmanager.AlertGroup{"team-a": {group}}}
+		d := &dispatcher{}
+		require.NoError(t, New(log.NewNopLogger(), lister, []string{"team-a"}, "", d.dispatch).Poll(ctx))
+		require.Empty(t, d.notifications)
+	})
+}
@@ -0,0 +1,401 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package preflight checks the receivers against Azure DevOps before alerts fire: that their projects, work item
+// types, states and fields exist, and that their credentials can create work items.
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/core"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/webapi"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/workitemtracking"
+	"github.com/pkg/errors"
+	"github.com/stakater/alert-az-do/pkg/azure"
+	"github.com/stakater/alert-az-do/pkg/config"
+	"github.com/stakater/alert-az-do/pkg/notify"
+)
+
+// concurrency is the number of receivers and targets checked at once.
+const concurrency = 4
+
+// checkTimeout bounds the checks of each receiver and target.
+var checkTimeout = 30 * time.Second
+
+// ruleErrorCode identifies the errors of work item rules, e.g. on required fields, in Azure DevOps error messages.
+const ruleErrorCode = "TF401320"
+
+// Client is the part of the Azure DevOps API used by the checks.
+type Client interface {
+	GetProject(context.Context, core.GetProjectArgs) (*core.TeamProject, error)
+	GetWorkItemTypes(context.Context, workitemtracking.GetWorkItemTypesArgs) (*[]workitemtracking.WorkItemType, error)
+	GetWorkItemTypeStates(context.Context, workitemtracking.GetWorkItemTypeStatesArgs) (*[]workitemtracking.WorkItemStateColor, error)
+	GetWorkItemTypeFieldsWithReferences(context.Context, workitemtracking.GetWorkItemTypeFieldsWithReferencesArgs) (*[]workitemtracking.WorkItemTypeFieldWithReferences, error)
+	CreateWorkItem(context.Context, workitemtracking.CreateWorkItemArgs) (*workitemtracking.WorkItem, error)
+}
+
+// ConnectFunc connects to Azure DevOps with the organization and credentials of a receiver configuration.
+type ConnectFunc func(ctx context.Context, logger log.Logger, conf *config.ReceiverConfig) (Client, error)
+
+// client combines the Azure DevOps API clients used by the checks.
+type client struct {
+	workitemtracking.Client
+	core core.Client
+}
+
+// GetProject implements the Client interface.
+func (c *client) GetProject(ctx context.Context, args core.GetProjectArgs) (*core.TeamProject, error) {
+	return c.core.GetProject(ctx, args)
+}
+
+// Connect connects to Azure DevOps with the organization and credentials of the receiver configuration.
+func Connect(ctx context.Context, logger log.Logger, conf *config.ReceiverConfig) (Client, error) {
+	conn, err := azure.GetConnection(ctx, logger, conf)
+	if err != nil {
+		return nil, err
+	}
+	coreClient, err := core.NewClient(ctx, conn)
+	if err != nil {
+		return nil, errors.Wrap(err, "create core client")
+	}
+	witClient, err := workitemtracking.NewClient(ctx, conn)
+	if err != nil {
+		return nil, errors.Wrap(err, "create work item tracking client")
+	}
+	return &client{Client: witClient, core: coreClient}, nil
+}
+
+// Result is the outcome of the checks of a receiver, or of one of its targets.
+type Result struct {
+	Receiver string
+	Target   string
+	// Why the checks were skipped, as a whole or in part, if they were.
+	Skipped  string
+	Problems []string
+}
+
+// Name returns the name of the receiver, followed by the name of the target if any.
+func (r *Result) Name() string {
+	if r.Target != "" {
+		return r.Receiver + "/" + r.Target
+	}
+	return r.Receiver
+}
+
+// OK reports whether the checks found no problem.
+func (r *Result) OK() bool {
+	return len(r.Problems) == 0
+}
+
+func (r *Result) problem(format string, args ...interface{}) {
+	r.Problems = append(r.Problems, fmt.Sprintf(format, args...))
+}
+
+// Failed reports whether the checks found problems in any of the results.
+func Failed(results []*Result) bool {
+	for _, r := range results {
+		if !r.OK() {
+			return true
+		}
+	}
+	return false
+}
+
+// Run checks all receivers of the configuration, each of their targets separately. Up to concurrency receivers and
+// targets are checked at once, each within checkTimeout, so that a slow organization does not hold up the others.
+// The results are in the order of the receivers and targets.
+func Run(ctx context.Context, logger log.Logger, cfg *config.Config, connect ConnectFunc) []*Result {
+	var confs []*config.ReceiverConfig
+	for _, rc := range cfg.Receivers {
+		confs = append(confs, rc.TargetConfigs()...)
+	}
+
+	results := make([]*Result, len(confs))
+	workers := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, conf := range confs {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(i int, conf *config.ReceiverConfig) {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+			ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+			results[i] = Check(ctx, log.With(logger, "receiver", conf.Name), conf, connect)
+		}(i, conf)
+	}
+	wg.Wait()
+	return results
+}
+
+// Check checks the receiver configuration against Azure DevOps: that its projects exist, that its work item types
+// exist in the project, that its states are states of these types, that its fields are fields of its work item type,
+// and that its credentials can create work items. Receivers with a templated organization or credentials name are
+// skipped, as they can only be resolved on a notification. For a templated project, only the other projects are
+// checked, and for a templated work item type, only the projects and the parent.
+func Check(ctx context.Context, logger log.Logger, conf *config.ReceiverConfig, connect ConnectFunc) *Result {
+	result := &Result{Receiver: conf.Name, Target: conf.Target}
+	if conf.TemplatedAccess() {
+		result.Skipped = "templated organization or credentials"
+		return result
+	}
+	conf, err := conf.Access(func(text string) (string, error) {
+		return "", errors.Errorf("cannot render %q without a notification", text)
+	})
+	if err != nil {
+		result.problem("resolve Azure DevOps access: %s", err)
+		return result
+	}
+	client, err := connect(ctx, logger, conf)
+	if err != nil {
+		result.problem("connect to organization %q: %s", conf.Organization, err)
+		return result
+	}
+	c := &checker{ctx: ctx, client: client, conf: conf, result: result}
+	c.run()
+	level.Debug(logger).Log("msg", "preflight checks done", "problems", len(result.Problems))
+	return result
+}
+
+// checker runs the checks of a receiver configuration, recording the problems in result.
+type checker struct {
+	ctx    context.Context
+	client Client
+	conf   *config.ReceiverConfig
+	result *Result
+}
+
+func (c *checker) run() {
+	if c.conf.TemplatedProject() {
+		c.result.Skipped = "templated project, only other_projects checked"
+		c.checkOtherProjects()
+		return
+	}
+	project := c.conf.Project
+	if _, err := c.client.GetProject(c.ctx, core.GetProjectArgs{ProjectId: &project}); err != nil {
+		c.result.problem("project %q: %s", project, err)
+		return
+	}
+	c.checkOtherProjects()
+
+	types, err := c.client.GetWorkItemTypes(c.ctx, workitemtracking.GetWorkItemTypesArgs{Project: &project})
+	if err != nil {
+		c.result.problem("list work item types of project %q: %s", project, err)
+		return
+	}
+	var typeNames []string
+	for _, t := range *types {
+		if t.Name != nil {
+			typeNames = append(typeNames, *t.Name)
+		}
+	}
+
+	if c.conf.TemplatedIssueType() {
+		c.result.Skipped = "templated issue_type, its states and fields not checked"
+	} else if c.checkType("issue_type", c.conf.IssueType, typeNames) {
+		states := map[string][]string{}
+		if c.conf.ReopenState != "" {
+			states["reopen_state"] = []string{c.conf.ReopenState}
+		}
+		if c.conf.SkipReopenState != "" {
+			states["skip_reopen_state"] = []string{c.conf.SkipReopenState}
+		}
+		if c.conf.AutoResolve != nil {
+			states["auto_resolve.state"] = []string{c.conf.AutoResolve.State}
+		}
+		if c.conf.Parent != nil && len(c.conf.Parent.ClosedStates) > 0 {
+			states["parent.closed_states"] = c.conf.Parent.ClosedStates
+		}
+		if c.conf.Silence != nil {
+			if c.conf.Silence.AcknowledgedState != "" {
+				states["silence.acknowledged_state"] = []string{c.conf.Silence.AcknowledgedState}
+			}
+			states["silence.closed_states"] = c.conf.Silence.ClosedStates
+		}
+		if c.conf.Escalation != nil && c.conf.Escalation.SLA != nil && c.conf.Escalation.SLA.State != "" {
+			states["escalation.sla.state"] = []string{c.conf.Escalation.SLA.State}
+		}
+		c.checkStates(c.conf.IssueType, states)
+		c.checkFields()
+		c.checkCreate()
+	}
+
+	if c.conf.Parent != nil && c.checkType("parent.issue_type", c.conf.Parent.IssueType, typeNames) && c.conf.Parent.AutoClose != nil {
+		c.checkStates(c.conf.Parent.IssueType, map[string][]string{"parent.auto_close.state": {c.conf.Parent.AutoClose.State}})
+	}
+}
+
+// checkOtherProjects checks that the other projects exist.
+func (c *checker) checkOtherProjects() {
+	for _, other := range c.conf.OtherProjects {
+		if _, err := c.client.GetProject(c.ctx, core.GetProjectArgs{ProjectId: &other}); err != nil {
+			c.result.problem("other project %q: %s", other, err)
+		}
+	}
+}
+
+// checkType checks that the work item type set in the key exists, reporting whether it does.
+func (c *checker) checkType(key, name string, names []string) bool {
+	if containsFold(names, name) {
+		return true
+	}
+	sort.Strings(names)
+	c.result.problem("%s: work item type %q not found in project %q (available: %s)", key, name, c.conf.Project, strings.Join(names, ", "))
+	return false
+}
+
+// checkStates checks that the states set in the keys are states of the work item type.
+func (c *checker) checkStates(workItemType string, states map[string][]string) {
+	if len(states) == 0 {
+		return
+	}
+	list, err := c.client.GetWorkItemTypeStates(c.ctx, workitemtracking.GetWorkItemTypeStatesArgs{Project: &c.conf.Project, Type: &workItemType})
+	if err != nil {
+		c.result.problem("list states of work item type %q: %s", workItemType, err)
+		return
+	}
+	var names []string
+	for _, s := range *list {
+		if s.Name != nil {
+			names = append(names, *s.Name)
+		}
+	}
+	keys := make([]string, 0, len(states))
+	for key := range states {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, state := range states[key] {
+			if !containsFold(names, state) {
+				c.result.problem("%s: %q is not a state of work item type %q (states: %s)", key, state, workItemType, strings.Join(names, ", "))
+			}
+		}
+	}
+}
+
+// checkFields checks that the fields set by the receiver, by reference or display name, are fields of its work item type.
+func (c *checker) checkFields() {
+	fields := map[string]string{}
+	for key := range c.conf.Fields {
+		fields["fields."+key] = strings.TrimPrefix(key, "/fields/")
+	}
+	if c.conf.Priority != "" {
+		fields["priority"] = notify.WorkItemFieldPriority.String()
+	}
+	if len(fields) == 0 {
+		return
+	}
+	list, err := c.client.GetWorkItemTypeFieldsWithReferences(c.ctx, workitemtracking.GetWorkItemTypeFieldsWithReferencesArgs{Project: &c.conf.Project, Type: &c.conf.IssueType})
+	if err != nil {
+		c.result.problem("list fields of work item type %q: %s", c.conf.IssueType, err)
+		return
+	}
+	var names []string
+	for _, f := range *list {
+		if f.ReferenceName != nil {
+			names = append(names, *f.ReferenceName)
+		}
+		// Fields may be set by display name as well.
+		if f.Name != nil {
+			names = append(names, *f.Name)
+		}
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !containsFold(names, fields[key]) {
+			c.result.problem("%s: %q is not a field of work item type %q", key, fields[key], c.conf.IssueType)
+		}
+	}
+}
+
+// checkCreate checks that the credentials can create work items, validating the creation of a work item without
+// saving it. Errors of work item rules are ignored, as the fields they are about are only rendered on notifications.
+func (c *checker) checkCreate() {
+	validateOnly := true
+	_, err := c.client.CreateWorkItem(c.ctx, workitemtracking.CreateWorkItemArgs{
+		Document: &[]webapi.JsonPatchOperation{{
+			Op:    &webapi.OperationValues.Add,
+			Path:  stringPtr(notify.WorkItemFieldTitle.FieldPath()),
+			Value: "alert-az-do preflight check",
+		}},
+		Project:      &c.conf.Project,
+		Type:         &c.conf.IssueType,
+		ValidateOnly: &validateOnly,
+	})
+	if err != nil && !strings.Contains(err.Error(), ruleErrorCode) {
+		c.result.problem("cannot create work items of type %q in project %q: %s", c.conf.IssueType, c.conf.Project, err)
+	}
+}
+
+// Report writes the results, one line per receiver followed by its problems.
+func Report(w io.Writer, results []*Result) {
+	for _, r := range results {
+		switch {
+		case !r.OK():
+			fmt.Fprintf(w, "%s: FAILED\n", r.Name())
+			for _, p := range r.Problems {
+				fmt.Fprintf(w, "  - %s\n", p)
+			}
+		case r.Skipped != "":
+			fmt.Fprintf(w, "%s: SKIPPED (%s)\n", r.Name(), r.Skipped)
+		default:
+			fmt.Fprintf(w, "%s: OK\n", r.Name())
+		}
+	}
+}
+
+// Log logs the results, one entry per receiver and problem.
+func Log(logger log.Logger, results []*Result) {
+	for _, r := range results {
+		switch {
+		case !r.OK():
+			for _, p := range r.Problems {
+				level.Error(logger).Log("msg", "preflight check failed", "receiver", r.Name(), "problem", p)
+			}
+		case r.Skipped != "":
+			level.Info(logger).Log("msg", "preflight checks skipped", "receiver", r.Name(), "reason", r.Skipped)
+		default:
+			level.Info(logger).Log("msg", "preflight checks passed", "receiver", r.Name())
+		}
+	}
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
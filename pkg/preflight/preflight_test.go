@@ -0,0 +1,317 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/core"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/workitemtracking"
+	"github.com/stakater/alert-az-do/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient is an Azure DevOps organization with projects holding work item types with their states and fields.
+type fakeClient struct {
+	projects  map[string]map[string]*fakeType
+	createErr error
+
+	// Receivers are checked concurrently.
+	mtx     sync.Mutex
+	created []workitemtracking.CreateWorkItemArgs
+}
+
+type fakeType struct {
+	states []string
+	fields []string
+}
+
+// workItemType returns the work item type of a project, matching its name case-insensitively as Azure DevOps does.
+func (f *fakeClient) workItemType(project, name string) *fakeType {
+	for n, t := range f.projects[project] {
+		if strings.EqualFold(n, name) {
+			return t
+		}
+	}
+	return &fakeType{}
+}
+
+func (f *fakeClient) GetProject(_ context.Context, args core.GetProjectArgs) (*core.TeamProject, error) {
+	if _, ok := f.projects[*args.ProjectId]; !ok {
+		return nil, errors.New("project does not exist")
+	}
+	return &core.TeamProject{Name: args.ProjectId}, nil
+}
+
+func (f *fakeClient) GetWorkItemTypes(_ context.Context, args workitemtracking.GetWorkItemTypesArgs) (*[]workitemtracking.WorkItemType, error) {
+	var types []workitemtracking.WorkItemType
+	for name := range f.projects[*args.Project] {
+		name := name
+		types = append(types, workitemtracking.WorkItemType{Name: &name})
+	}
+	return &types, nil
+}
+
+func (f *fakeClient) GetWorkItemTypeStates(_ context.Context, args workitemtracking.GetWorkItemTypeStatesArgs) (*[]workitemtracking.WorkItemStateColor, error) {
+	var states []workitemtracking.WorkItemStateColor
+	for _, name := range f.workItemType(*args.Project, *args.Type).states {
+		name := name
+		states = append(states, workitemtracking.WorkItemStateColor{Name: &name})
+	}
+	return &states, nil
+}
+
+func (f *fakeClient) GetWorkItemTypeFieldsWithReferences(_ context.Context, args workitemtracking.GetWorkItemTypeFieldsWithReferencesArgs) (*[]workitemtracking.WorkItemTypeFieldWithReferences, error) {
+	var fields []workitemtracking.WorkItemTypeFieldWithReferences
+	for _, name := range f.workItemType(*args.Project, *args.Type).fields {
+		name := name
+		displayName := name[strings.LastIndex(name, ".")+1:]
+		fields = append(fields, workitemtracking.WorkItemTypeFieldWithReferences{ReferenceName: &name, Name: &displayName})
+	}
+	return &fields, nil
+}
+
+func (f *fakeClient) CreateWorkItem(_ context.Context, args workitemtracking.CreateWorkItemArgs) (*workitemtracking.WorkItem, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.created = append(f.created, args)
+	return nil, f.createErr
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{projects: map[string]map[string]*fakeType{
+		"AB": {
+			"Bug": {
+				states: []string{"New", "Active", "Resolved", "Closed"},
+				fields: []string{"System.Title", "System.Description", "System.AreaPath", "Microsoft.VSTS.Common.Priority", "Custom.Team"},
+			},
+			"Feature": {states: []string{"New", "Active", "Closed"}},
+		},
+		"OTHER": {},
+	}}
+}
+
+func TestCheck(t *testing.T) {
+	cfg, err := config.Load(`
+defaults:
+  organization: contoso
+  personal_access_token: token
+  project: AB
+  issue_type: Bug
+  summary: s
+  reopen_state: Active
+  reopen_duration: 0h
+receivers:
+  - name: ok
+    other_projects: [OTHER]
+    priority: '1'
+    fields:
+      System.AreaPath: 'AB\Alerts'
+      custom.team: '{{ .CommonLabels.team }}'
+      Team: '{{ .CommonLabels.team }}'
+    auto_resolve: {state: Closed}
+    parent:
+      key: '{{ .GroupLabels.alertname }}'
+      issue_type: Feature
+      auto_close: {state: Closed}
+  - name: typos
+    other_projects: [MISSING]
+    issue_type: bug
+    reopen_state: Reopened
+    skip_reopen_state: Removed
+    fields:
+      System.AreaPth: 'AB'
+    auto_resolve: {state: Done}
+    parent: {key: k, issue_type: Epic}
+  - name: unknown-type
+    issue_type: Incident
+  - name: unknown-project
+    project: XY
+  - name: tenants
+    organization: '{{ .CommonLabels.tenant }}'
+template: test.tmpl
+`)
+	require.NoError(t, err)
+
+	client := newFakeClient()
+	results := Run(context.Background(), log.NewNopLogger(), cfg, func(_ context.Context, _ log.Logger, conf *config.ReceiverConfig) (Client, error) {
+		require.Equal(t, "contoso", conf.Organization)
+		return client, nil
+	})
+	require.Len(t, results, 5)
+
+	require.Equal(t, &Result{Receiver: "ok"}, results[0])
+	require.Len(t, client.created, 2)
+	var types []string
+	for _, created := range client.created {
+		require.True(t, *created.ValidateOnly)
+		types = append(types, *created.Type)
+	}
+	require.ElementsMatch(t, []string{"Bug", "bug"}, types)
+
+	require.Equal(t, []string{
+		`other project "MISSING": project does not exist`,
+		`auto_resolve.state: "Done" is not a state of work item type "bug" (states: New, Active, Resolved, Closed)`,
+		`reopen_state: "Reopened" is not a state of work item type "bug" (states: New, Active, Resolved, Closed)`,
+		`skip_reopen_state: "Removed" is not a state of work item type "bug" (states: New, Active, Resolved, Closed)`,
+		`fields.System.AreaPth: "System.AreaPth" is not a field of work item type "bug"`,
+		`parent.issue_type: work item type "Epic" not found in project "AB" (available: Bug, Feature)`,
+	}, results[1].Problems)
+	require.Equal(t, []string{`issue_type: work item type "Incident" not found in project "AB" (available: Bug, Feature)`}, results[2].Problems)
+	require.Equal(t, []string{`project "XY": project does not exist`}, results[3].Problems)
+	require.Equal(t, "templated organization or credentials", results[4].Skipped)
+	require.True(t, results[4].OK())
+	require.True(t, Failed(results))
+
+	var b bytes.Buffer
+	Report(&b, []*Result{results[0], results[3], results[4]})
+	require.Equal(t, `ok: OK
+unknown-project: FAILED
+  - project "XY": project does not exist
+tenants: SKIPPED (templated organization or credentials)
+`, b.String())
+}
+
+func TestCheck_Templated(t *testing.T) {
+	cfg, err := config.Load(`
+defaults:
+  organization: contoso
+  personal_access_token: token
+  project: AB
+  issue_type: Bug
+  summary: s
+  reopen_state: Active
+  reopen_duration: 0h
+receivers:
+  - name: projects
+    project: '{{ .CommonLabels.project }}'
+    other_projects: [OTHER, MISSING]
+  - name: types
+    issue_type: '{{ if eq .CommonLabels.severity "critical" }}Incident{{ else }}Bug{{ end }}'
+    reopen_state: Reopened
+    auto_resolve: {state: Closed}
+    parent: {key: k, issue_type: Feature, auto_close: {state: Closed}}
+template: test.tmpl
+`)
+	require.NoError(t, err)
+
+	client := newFakeClient()
+	results := Run(context.Background(), log.NewNopLogger(), cfg, func(context.Context, log.Logger, *config.ReceiverConfig) (Client, error) {
+		return client, nil
+	})
+	require.Len(t, results, 2)
+	require.Equal(t, "templated project, only other_projects checked", results[0].Skipped)
+	require.Equal(t, []string{`other project "MISSING": project does not exist`}, results[0].Problems)
+	require.Equal(t, "templated issue_type, its states and fields not checked", results[1].Skipped)
+	require.True(t, results[1].OK())
+	require.Empty(t, client.created)
+
+	var b bytes.Buffer
+	Report(&b, results)
+	require.Equal(t, `projects: FAILED
+  - other project "MISSING": project does not exist
+types: SKIPPED (templated issue_type, its states and fields not checked)
+`, b.String())
+}
+
+func TestCheck_Create(t *testing.T) {
+	cfg, err := config.Load(`
+receivers:
+  - name: r
+    organization: contoso
+    personal_access_token: token
+    project: AB
+    issue_type: Bug
+    summary: s
+    reopen_state: Active
+    reopen_duration: 0h
+    targets:
+      - name: team
+      - name: secops
+        project: OTHER
+template: test.tmpl
+`)
+	require.NoError(t, err)
+	client := newFakeClient()
+	connect := func(context.Context, log.Logger, *config.ReceiverConfig) (Client, error) { return client, nil }
+
+	// Rule errors are about fields rendered on notifications only.
+	client.createErr = errors.New("TF401320: Rule Error for field Team. Error code: Required, HasValues, LimitedToValues.")
+	results := Run(context.Background(), log.NewNopLogger(), cfg, connect)
+	require.Len(t, results, 2)
+	require.Equal(t, "r/team", results[0].Name())
+	require.True(t, results[0].OK())
+	require.Equal(t, []string{`issue_type: work item type "Bug" not found in project "OTHER" (available: )`}, results[1].Problems)
+
+	client.createErr = errors.New("TF237111: The current user does not have permissions to save work items under the specified area path.")
+	result := Check(context.Background(), log.NewNopLogger(), cfg.Receivers[0].TargetConfigs()[0], connect)
+	require.Equal(t, []string{`cannot create work items of type "Bug" in project "AB": TF237111: The current user does not have permissions to save work items under the specified area path.`}, result.Problems)
+
+	result = Check(context.Background(), log.NewNopLogger(), cfg.Receivers[0], func(context.Context, log.Logger, *config.ReceiverConfig) (Client, error) {
+		return nil, errors.New("unauthorized")
+	})
+	require.Equal(t, []string{`connect to organization "contoso": unauthorized`}, result.Problems)
+}
+
+func TestRun_CheckTimeout(t *testing.T) {
+	defer func(timeout time.Duration) { checkTimeout = timeout }(checkTimeout)
+	checkTimeout = 10 * time.Millisecond
+
+	cfg, err := config.Load(`
+defaults:
+  personal_access_token: token
+  project: AB
+  issue_type: Bug
+  summary: s
+  reopen_state: Active
+  reopen_duration: 0h
+receivers:
+  - name: slow-1
+    organization: slow
+  - name: slow-2
+    organization: slow
+  - name: slow-3
+    organization: slow
+  - name: slow-4
+    organization: slow
+  - name: ok
+    organization: contoso
+template: test.tmpl
+`)
+	require.NoError(t, err)
+	client := newFakeClient()
+
+	// The slow organizations use up the time of their own checks only.
+	results := Run(context.Background(), log.NewNopLogger(), cfg, func(ctx context.Context, _ log.Logger, conf *config.ReceiverConfig) (Client, error) {
+		if conf.Organization == "slow" {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return client, nil
+	})
+	require.Len(t, results, 5)
+	for _, result := range results[:4] {
+		require.Equal(t, []string{`connect to organization "slow": context deadline exceeded`}, result.Problems)
+	}
+	require.Equal(t, "ok", results[4].Receiver)
+	require.True(t, results[4].OK())
+}
@@ -0,0 +1,186 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+// descriptions holds the descriptions of the configuration types, by type name, and of their properties, by
+// <type name>.<property> or by property for the descriptions shared by all properties of that name.
+var descriptions = map[string]string{
+	// Shared by several types.
+	"tenant_id":                  "Microsoft Entra tenant ID of the service principal.",
+	"client_id":                  "Client ID of the service principal or of the user-assigned managed identity.",
+	"subscription_id":            "Azure subscription ID.",
+	"client_secret":              "Client secret of the service principal, or an azurekeyvault://<vault>/<secret>[/<version>] reference.",
+	"client_secret_file":         "File holding the client secret, read again on every connection.",
+	"personal_access_token":      "Azure DevOps personal access token, or an azurekeyvault://<vault>/<secret>[/<version>] reference.",
+	"personal_access_token_file": "File holding the personal access token, read again on every connection.",
+	"credentials":                "Name of the credentials to use from the credentials section, instead of inline credentials. Templated.",
+	"auth":                       "Explicit authentication profile, instead of the method implied by the credential fields that are set.",
+	"organization":               "Azure DevOps organization. Templated.",
+	"project":                    "Azure DevOps project to create the work items in.",
+	"issue_type":                 "Type of the work items to create, e.g. Bug, Task or Issue.",
+	"fields":                     "Standard or custom field values to set on the work items, by field reference name. Values are templated.",
+	"closed_states":              "States in which a work item counts as closed. Optional (default: the auto_resolve state).",
+
+	"Config":                "The alert-az-do configuration file.",
+	"Config.defaults":       "Defaults applied to all receivers where not explicitly overridden.",
+	"Config.receivers":      "Receivers, each matching the Alertmanager receiver of the same name.",
+	"Config.template":       "File, glob pattern or list of files and glob patterns containing template definitions, parsed in order. Relative to the configuration file.",
+	"Config.receiver_files": "Glob patterns of files holding further receivers, each with a receivers list only. Relative to the configuration file.",
+	"Config.credentials":    "Named credentials, referenced by receivers, targets and the defaults.",
+	"Config.route":          "Routing of notifications to receivers by label matchers. Optional (default: the receiver named like the Alertmanager receiver).",
+	"Config.alertmanager":   "Alertmanager API, e.g. for creating silences.",
+	"Config.polling":        "Pull alerts from the Alertmanager API instead of receiving webhooks. Requires the alertmanager section.",
+	"Config.service_hook":   "Basic authentication of the Azure DevOps service hook endpoint (/hooks/azure-devops).",
+	"Config.inputs":         "Generic JSON webhook inputs, receiving JSON documents at /input/<name>.",
+	"Config.cloudevents":    "CloudEvents endpoint (/cloudevents).",
+
+	"ReceiverConfig":                   "A receiver, creating and updating Azure DevOps work items for the notifications of an Alertmanager receiver.",
+	"ReceiverConfig.name":              "Name of the receiver, matching the Alertmanager receiver name.",
+	"ReceiverConfig.azure":             "Azure cloud, authority host, workload identity token file and token scope of the credentials.",
+	"ReceiverConfig.other_projects":    "Further projects of the receiver. Only checked to exist by the preflight checks, work items are not searched in them.",
+	"ReceiverConfig.summary":           "Title of the work items. Templated.",
+	"ReceiverConfig.reopen_state":      "State to transition into when reopening a closed work item.",
+	"ReceiverConfig.reopen_duration":   "Required in the receiver or the defaults, but not used: closed work items are always reopened.",
+	"ReceiverConfig.priority":          "Priority of the work items. Templated.",
+	"ReceiverConfig.description":       "Description of the work items. Templated.",
+	"ReceiverConfig.skip_reopen_state": "Do not reopen work items in this state.",
+	"ReceiverConfig.components":        "Not used by Azure DevOps, accepted for compatibility.",
+	"ReceiverConfig.static_labels":     "Accepted for compatibility, not used: no tags are added besides the receiver and alert tags.",
+	"ReceiverConfig.add_group_labels":  "Accepted for compatibility, not used: the group labels are not added as tags.",
+	"ReceiverConfig.update_in_comment": "Additionally add the comment \"Issue updated with new alert data\" to the work items on every update. The work items are updated either way. Optional (default: false).",
+	"ReceiverConfig.auto_resolve":      "Resolve the work items when their alerts are resolved.",
+	"ReceiverConfig.links":             "Hyperlink relations added to the work items, e.g. to Prometheus, Alertmanager or runbooks.",
+	"ReceiverConfig.attach_payload":    "Attach the raw Alertmanager payload to the work items on create and on each update.",
+	"ReceiverConfig.parent":            "Group the work items under a parent work item, e.g. per alertname or service.",
+	"ReceiverConfig.correlations":      "Cross-link the work items of alerts sharing labels that fire within a time window.",
+	"ReceiverConfig.escalation":        "Raise the priority of work items on more severe alerts and when they are not picked up in time.",
+	"ReceiverConfig.silence":           "Create Alertmanager silences when work items are acknowledged. Requires the alertmanager section.",
+	"ReceiverConfig.reconcile":         "Auto-resolve work items whose alerts are not active in Alertmanager anymore. Requires the alertmanager section and auto_resolve.",
+	"ReceiverConfig.include":           "Only handle the alerts matching all of these Alertmanager-style matchers.",
+	"ReceiverConfig.exclude":           "Ignore the alerts matching any of these Alertmanager-style matchers.",
+	"ReceiverConfig.relabel_configs":   "Prometheus-style relabel rules applied to the alert labels before templating and fingerprinting. Not with silence, reconcile or polling.",
+	"ReceiverConfig.targets":           "Create work items in each of these organizations and projects, instead of the receiver's own.",
+	"ReceiverConfig.template":          "Template files or glob patterns of the receiver, layered over the global templates. Not allowed in the defaults.",
+
+	"Credentials": "Named Azure DevOps credentials, holding exactly one authentication method.",
+
+	"AuthConfig":                           "Explicit authentication profile.",
+	"AuthConfig.type":                      "Authentication method.",
+	"AuthConfig.tenant_id":                 "Microsoft Entra tenant ID. Optional for workload_identity, azure_cli and default_chain.",
+	"AuthConfig.client_id":                 "Client ID of the service principal, of the workload identity or of the user-assigned managed identity.",
+	"AuthConfig.certificate_path":          "PEM or PKCS#12 file holding the client certificate and its private key, reloaded when it changes.",
+	"AuthConfig.certificate_password":      "Password of the certificate, or an azurekeyvault://<vault>/<secret>[/<version>] reference.",
+	"AuthConfig.certificate_password_file": "File holding the password of the certificate, reloaded when it changes.",
+
+	"AzureConfig":                      "Azure environment the credentials authenticate in.",
+	"AzureConfig.cloud":                "Sovereign cloud. Optional (default: AzurePublic).",
+	"AzureConfig.authority_host":       "Microsoft Entra ID authority host, overriding the one of the cloud. Optional (default: AZURE_AUTHORITY_HOST).",
+	"AzureConfig.federated_token_file": "Projected service account token of workload identity. Optional (default: AZURE_FEDERATED_TOKEN_FILE).",
+	"AzureConfig.scope":                "Scope of the Azure DevOps tokens. Optional (default: the Azure DevOps resource).",
+
+	"Target":        "An Azure DevOps organization and project the receiver creates work items in. Settings not set are taken from the receiver.",
+	"Target.name":   "Unique name of the target.",
+	"Target.fields": "Field values merged with the receiver's, the target's taking precedence. Values are templated.",
+
+	"AutoResolve":       "Resolution of the work items.",
+	"AutoResolve.state": "State to transition the work items into.",
+
+	"AttachPayload":                 "Attachment of the raw Alertmanager payload.",
+	"AttachPayload.file_name":       "Attachment file name. Templated. Optional (default: alertmanager-payload.json).",
+	"AttachPayload.max_attachments": "Keep at most this many payload attachments, removing the oldest ones. Optional (default: 0, unlimited).",
+
+	"Parent":             "Parent work item, created when missing.",
+	"Parent.key":         "Key selecting the parent work item. Templated.",
+	"Parent.issue_type":  "Type of the parent work item.",
+	"Parent.summary":     "Title of the parent work item. Templated. Optional (default: the rendered key).",
+	"Parent.description": "Description of the parent work item. Templated.",
+	"Parent.auto_close":  "Close the parent work item once all of its children are closed. Requires auto_resolve.",
+
+	"Correlation":         "Rule for cross-linking the work items of correlated notifications.",
+	"Correlation.labels":  "Common labels whose values correlated notifications share.",
+	"Correlation.window":  "Maximum time between the creation of correlated work items.",
+	"Correlation.comment": "Comment posted on the correlated work items. Templated.",
+
+	"Escalation":            "Escalation of the work items.",
+	"Escalation.label":      "Alert label whose values map to priorities.",
+	"Escalation.priorities": "Priorities by value of the label, 1 being the highest. Priorities are never lowered.",
+	"Escalation.sla":        "Escalate work items still in their initial state after a given duration.",
+
+	"SLA":           "Escalation of the work items not picked up in time.",
+	"SLA.state":     "Initial state of the work items. Optional (default: New).",
+	"SLA.after":     "Time after which work items still in the initial state are escalated.",
+	"SLA.priority":  "Priority of escalated work items.",
+	"SLA.assign_to": "Assignee of escalated work items.",
+	"SLA.interval":  "How often overdue work items are looked up. Optional (default: 5m).",
+
+	"Silence":                    "Alertmanager silences created from the Azure DevOps service hook.",
+	"Silence.acknowledged_state": "Silence the alert group when the work item moves to this state.",
+	"Silence.duration":           "Duration of the silences.",
+	"Silence.closed_states":      "Expire the silences when the work item moves to one of these states. Optional (default: the auto_resolve state).",
+	"Silence.created_by":         "Author of the silences. Optional (default: alert-az-do).",
+
+	"Reconcile":          "Reconciliation of the open work items with the active alerts.",
+	"Reconcile.interval": "How often the work items are reconciled. Optional (default: 10m).",
+
+	"AlertmanagerConfig":     "Alertmanager API access.",
+	"AlertmanagerConfig.url": "URL of Alertmanager.",
+
+	"PollingConfig":           "Polling of the Alertmanager API.",
+	"PollingConfig.interval":  "How often the alert groups are pulled. Optional (default: 1m).",
+	"PollingConfig.receivers": "Receivers to poll the alert groups of. Optional (default: all receivers).",
+
+	"ServiceHookConfig":          "Azure DevOps service hook endpoint.",
+	"ServiceHookConfig.username": "User name of the basic authentication of the service hooks.",
+	"ServiceHookConfig.password": "Password of the basic authentication of the service hooks.",
+
+	"InputConfig":               "Generic JSON webhook input. Mapped values are JSONPath expressions (starting with '$') or templates applied to the document.",
+	"InputConfig.name":          "Name of the input, received at /input/<name>.",
+	"InputConfig.receiver":      "Receiver notified of the mapped alerts.",
+	"InputConfig.alerts":        "JSONPath selecting an array whose elements are the alerts. Optional (default: the document is a single alert).",
+	"InputConfig.status":        "Status of the alert, firing or resolved. Optional (default: firing).",
+	"InputConfig.fingerprint":   "Identity of the alert, hashed into its fingerprint. Optional (default: the labels).",
+	"InputConfig.labels":        "Labels of the alert. At least one is required.",
+	"InputConfig.annotations":   "Annotations of the alert.",
+	"InputConfig.starts_at":     "Start time of the alert, an RFC 3339 time or a Unix timestamp.",
+	"InputConfig.ends_at":       "End time of the alert, an RFC 3339 time or a Unix timestamp.",
+	"InputConfig.generator_url": "URL of the source of the alert.",
+	"InputConfig.group_by":      "Labels of the alert group. Optional (default: [alertname]).",
+
+	"CloudEventsConfig":                    "CloudEvents endpoint.",
+	"CloudEventsConfig.receiver_extension": "Extension attribute naming the receiver of the event. Optional (default: receiver).",
+	"CloudEventsConfig.default_receiver":   "Receiver of events without the extension attribute.",
+	"CloudEventsConfig.firing_types":       "Event types of firing alerts. Events of other types are rejected when set.",
+	"CloudEventsConfig.resolved_types":     "Event types of resolved alerts. Optional (default: types ending in '.resolved').",
+	"CloudEventsConfig.annotation_fields":  "Fields of the event data that become annotations instead of labels.",
+
+	"Route":          "Node of the routing tree.",
+	"Route.receiver": "Receiver of the matching notifications. Optional (default: the receiver of the parent route).",
+	"Route.matchers": "Alertmanager-style matchers (=, !=, =~, !~) on the common and group labels.",
+	"Route.continue": "Go on matching the following routes. Optional (default: false).",
+	"Route.routes":   "Child routes.",
+
+	"Link":           "Hyperlink relation added to the work items.",
+	"Link.url":       "URL of the link. Templated.",
+	"Link.comment":   "Comment of the link. Templated.",
+	"Link.per_alert": "Render the link for every alert instead of once per notification. Optional (default: false).",
+
+	"RelabelConfig":               "Prometheus-style relabel rule.",
+	"RelabelConfig.source_labels": "Labels whose values are concatenated and matched against the regex.",
+	"RelabelConfig.separator":     "Separator of the concatenated values. Optional (default: ;).",
+	"RelabelConfig.regex":         "Regular expression, anchored at both ends. Optional (default: (.*)).",
+	"RelabelConfig.target_label":  "Label written by the replace action.",
+	"RelabelConfig.replacement":   "Replacement of the replace and labelmap actions, with $1 style references. Optional (default: $1).",
+	"RelabelConfig.action":        "Action of the rule. Optional (default: replace).",
+}
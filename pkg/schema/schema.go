@@ -0,0 +1,212 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package schema generates the JSON Schema of the alert-az-do configuration file from the configuration types, for
+// editors and CI pipelines to validate and autocomplete configuration files.
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/stakater/alert-az-do/pkg/config"
+	"github.com/stakater/alert-az-do/pkg/notify"
+)
+
+// draft is the JSON Schema dialect of the generated schema, the one most editors support.
+const draft = "http://json-schema.org/draft-07/schema#"
+
+// durationPattern matches the Go durations of the configuration file, e.g. 1h30m.
+const durationPattern = `^(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`
+
+// Schema is a JSON Schema, or one of its subschemas.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	Definitions          map[string]*Schema `json:"definitions,omitempty"`
+}
+
+// required lists the properties required by the configuration types. Most receiver fields are not, as they may be
+// inherited from the defaults.
+var required = map[string][]string{
+	"Config":             {"template"},
+	"AuthConfig":         {"type"},
+	"Target":             {"name"},
+	"AutoResolve":        {"state"},
+	"Link":               {"url"},
+	"Parent":             {"key", "issue_type"},
+	"Correlation":        {"labels", "window"},
+	"AlertmanagerConfig": {"url"},
+	"InputConfig":        {"name", "receiver", "labels"},
+}
+
+// typeSchemas are the schemas of the types with custom YAML unmarshaling.
+var typeSchemas = map[reflect.Type]func() *Schema{
+	reflect.TypeOf(time.Duration(0)): func() *Schema {
+		return &Schema{Type: "string", Pattern: durationPattern}
+	},
+	reflect.TypeOf(config.Secret("")): func() *Schema {
+		return &Schema{Type: "string"}
+	},
+	reflect.TypeOf(config.Regexp{}): func() *Schema {
+		return &Schema{Type: "string", Format: "regex"}
+	},
+	reflect.TypeOf(config.Matchers{}): func() *Schema {
+		return &Schema{Type: "array", Items: &Schema{Type: "string"}}
+	},
+	reflect.TypeOf(config.Templates{}): func() *Schema {
+		return &Schema{OneOf: []*Schema{{Type: "string"}, {Type: "array", Items: &Schema{Type: "string"}}}}
+	},
+	reflect.TypeOf(config.AuthType("")): func() *Schema {
+		return enum(string(config.AuthServicePrincipalSecret), string(config.AuthServicePrincipalCertificate),
+			string(config.AuthWorkloadIdentity), string(config.AuthManagedIdentity), string(config.AuthAzureCLI),
+			string(config.AuthPAT), string(config.AuthDefaultChain))
+	},
+	reflect.TypeOf(config.RelabelAction("")): func() *Schema {
+		return enum(string(config.RelabelReplace), string(config.RelabelKeep), string(config.RelabelDrop),
+			string(config.RelabelLabelDrop), string(config.RelabelLabelMap))
+	},
+}
+
+// propertySchemas are the schemas of the properties whose values are constrained beyond their Go types.
+var propertySchemas = map[string]func() *Schema{
+	"AzureConfig.cloud": func() *Schema {
+		return enum(config.CloudAzurePublic, config.CloudAzureChina, config.CloudAzureUSGovernment)
+	},
+	"AzureConfig.authority_host": func() *Schema { return &Schema{Type: "string", Format: "uri"} },
+	"ReceiverConfig.fields":      fieldsSchema,
+	"Target.fields":              fieldsSchema,
+}
+
+// enum returns the schema of a string taking one of the given values.
+func enum(values ...string) *Schema {
+	return &Schema{Type: "string", Enum: values}
+}
+
+// fieldsSchema returns the schema of work item fields, listing the known Azure DevOps fields for autocompletion.
+// Custom fields are allowed as well.
+func fieldsSchema() *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}, AdditionalProperties: true}
+	for _, f := range notify.AllWorkItemFields {
+		s.Properties[f.String()] = &Schema{}
+	}
+	return s
+}
+
+// Generate returns the JSON Schema of the configuration file.
+func Generate() *Schema {
+	g := &generator{defs: map[string]*Schema{}}
+	s := g.object(reflect.TypeOf(config.Config{}))
+	s.Schema = draft
+	s.Title = "alert-az-do configuration"
+	// Receivers must be named, unlike the defaults sharing their type.
+	s.Properties["receivers"].Items = &Schema{AllOf: []*Schema{s.Properties["receivers"].Items}, Required: []string{"name"}}
+	s.Definitions = g.defs
+	return s
+}
+
+// JSON returns the JSON Schema of the configuration file, indented.
+func JSON() ([]byte, error) {
+	return json.MarshalIndent(Generate(), "", "  ")
+}
+
+// generator generates the schemas of Go types, collecting the schemas of structs as definitions.
+type generator struct {
+	defs map[string]*Schema
+}
+
+// schema returns the schema of t, a reference for structs.
+func (g *generator) schema(t reflect.Type) *Schema {
+	if f, ok := typeSchemas[t]; ok {
+		return f()
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.schema(t.Elem())
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if _, ok := g.defs[t.Name()]; !ok {
+			// Registered before generating the properties, for recursive types such as Route.
+			g.defs[t.Name()] = &Schema{}
+			*g.defs[t.Name()] = *g.object(t)
+		}
+		return &Schema{Ref: "#/definitions/" + t.Name()}
+	}
+	// Any value, e.g. of interface{} fields.
+	return &Schema{}
+}
+
+// object returns the schema of the struct t, with the properties of its YAML fields. Unknown properties are rejected,
+// as checkOverflow does when loading the configuration.
+func (g *generator) object(t reflect.Type) *Schema {
+	s := &Schema{
+		Type:                 "object",
+		Description:          descriptions[t.Name()],
+		Properties:           map[string]*Schema{},
+		Required:             required[t.Name()],
+		AdditionalProperties: false,
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+		if !f.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		var p *Schema
+		if ps, ok := propertySchemas[t.Name()+"."+name]; ok {
+			p = ps()
+		} else {
+			p = g.schema(f.Type)
+		}
+		p.Description = description(t.Name(), name)
+		s.Properties[name] = p
+	}
+	return s
+}
+
+// description returns the description of the property of a type, falling back to the description shared by the
+// properties of that name.
+func description(typeName, property string) string {
+	if d, ok := descriptions[typeName+"."+property]; ok {
+		return d
+	}
+	return descriptions[property]
+}
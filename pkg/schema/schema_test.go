@@ -0,0 +1,118 @@
+// Copyright 2025 Stakater AB
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestGenerate(t *testing.T) {
+	s := Generate()
+	require.Equal(t, draft, s.Schema)
+	require.Equal(t, []string{"template"}, s.Required)
+	require.Equal(t, false, s.AdditionalProperties)
+	require.Equal(t, []string{"name"}, s.Properties["receivers"].Items.Required)
+	require.Equal(t, "#/definitions/ReceiverConfig", s.Properties["defaults"].Ref)
+
+	receiver := s.Definitions["ReceiverConfig"]
+	require.Equal(t, false, receiver.AdditionalProperties)
+	require.NotContains(t, receiver.Properties, "Target")
+	require.Contains(t, receiver.Properties["fields"].Properties, "System.Title")
+	require.Contains(t, receiver.Properties["fields"].Properties, "Microsoft.VSTS.Common.Priority")
+	require.Equal(t, true, receiver.Properties["fields"].AdditionalProperties)
+	require.Equal(t, "string", receiver.Properties["reopen_duration"].Type)
+	require.Equal(t, "#/definitions/AuthConfig", receiver.Properties["auth"].Ref)
+	require.Len(t, receiver.Properties["template"].OneOf, 2)
+
+	require.Equal(t, []string{"service_principal_secret", "service_principal_certificate", "workload_identity", "managed_identity", "azure_cli", "pat", "default_chain"},
+		s.Definitions["AuthConfig"].Properties["type"].Enum)
+	require.Equal(t, []string{"AzurePublic", "AzureChina", "AzureUSGovernment"}, s.Definitions["AzureConfig"].Properties["cloud"].Enum)
+	require.Equal(t, "#/definitions/Route", s.Definitions["Route"].Properties["routes"].Items.Ref)
+	require.Equal(t, "#/definitions/Credentials", s.Properties["credentials"].AdditionalProperties.(*Schema).Ref)
+
+	b, err := JSON()
+	require.NoError(t, err)
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &doc))
+	require.Contains(t, doc, "definitions")
+}
+
+func TestGenerate_Descriptions(t *testing.T) {
+	s := Generate()
+	objects := map[string]*Schema{"Config": s}
+	for name, def := range s.Definitions {
+		objects[name] = def
+	}
+	for name, object := range objects {
+		require.NotEmpty(t, object.Description, name)
+		for property, p := range object.Properties {
+			require.NotEmpty(t, p.Description, "%s.%s", name, property)
+		}
+	}
+}
+
+func TestGenerate_Example(t *testing.T) {
+	b, err := os.ReadFile("../../examples/alert-az-do.yml")
+	require.NoError(t, err)
+	var example interface{}
+	require.NoError(t, yaml.Unmarshal(b, &example))
+
+	s := Generate()
+	require.Empty(t, unknownKeys(s, s, example, ""))
+	require.Equal(t, []string{".receivers[].area_path"}, unknownKeys(s, s, map[string]interface{}{
+		"receivers": []interface{}{map[string]interface{}{"name": "r", "area_path": "a"}},
+	}, ""))
+}
+
+// unknownKeys returns the paths of the keys of the objects of v that are not properties of s.
+func unknownKeys(root, s *Schema, v interface{}, path string) []string {
+	if s.Ref != "" {
+		s = root.Definitions[strings.TrimPrefix(s.Ref, "#/definitions/")]
+	}
+	var unknown []string
+	for _, sub := range s.AllOf {
+		unknown = append(unknown, unknownKeys(root, sub, v, path)...)
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if p, ok := s.Properties[key]; ok {
+				unknown = append(unknown, unknownKeys(root, p, value, path+"."+key)...)
+				continue
+			}
+			switch ap := s.AdditionalProperties.(type) {
+			case *Schema:
+				unknown = append(unknown, unknownKeys(root, ap, value, path+"."+key)...)
+			case bool:
+				if !ap {
+					unknown = append(unknown, path+"."+key)
+				}
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for _, item := range v {
+				unknown = append(unknown, unknownKeys(root, s.Items, item, path+"[]")...)
+			}
+		}
+	}
+	return unknown
+}
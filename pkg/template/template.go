@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	html "html/template"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"text/template"
@@ -33,6 +34,9 @@ import (
 type Template struct {
 	tmpl   *template.Template
 	logger log.Logger
+
+	// Templates of the receivers with template files of their own, layered over these templates.
+	receivers map[string]*Template
 }
 
 var funcs = template.FuncMap{
@@ -70,14 +74,66 @@ var funcs = template.FuncMap{
 
 // LoadTemplate reads and parses all templates defined in the given file and constructs a alert-az-do.Template.
 func LoadTemplate(path string, logger log.Logger) (*Template, error) {
-	level.Debug(logger).Log("msg", "loading templates", "path", path)
-	tmpl, err := template.New("").Option("missingkey=zero").Funcs(funcs).ParseFiles(path)
+	return LoadTemplates([]string{path}, logger)
+}
+
+// LoadTemplates reads and parses all templates defined in the given files or the files matching the given glob
+// patterns, in order, and constructs a alert-az-do.Template. Templates defined again in later files replace the earlier
+// definitions.
+func LoadTemplates(patterns []string, logger log.Logger) (*Template, error) {
+	level.Debug(logger).Log("msg", "loading templates", "paths", strings.Join(patterns, ","))
+	tmpl, err := parseFiles(template.New("").Option("missingkey=zero").Funcs(funcs), patterns)
 	if err != nil {
 		return nil, err
 	}
 	return &Template{tmpl: tmpl, logger: logger}, nil
 }
 
+// AddReceiver layers the templates defined in the given files or the files matching the given glob patterns over t, for
+// the receiver with the given name. Templates defined in them replace those of t for the receiver only.
+func (t *Template) AddReceiver(name string, patterns []string) error {
+	level.Debug(t.logger).Log("msg", "loading receiver templates", "receiver", name, "paths", strings.Join(patterns, ","))
+	tmpl, err := t.tmpl.Clone()
+	if err != nil {
+		return errors.Wrap(err, "clone tmpl")
+	}
+	if tmpl, err = parseFiles(tmpl, patterns); err != nil {
+		return errors.Wrapf(err, "receiver %q", name)
+	}
+	if t.receivers == nil {
+		t.receivers = map[string]*Template{}
+	}
+	t.receivers[name] = &Template{tmpl: tmpl, logger: t.logger}
+	return nil
+}
+
+// ForReceiver returns the templates of the receiver with the given name: t, with the receiver's own templates layered
+// over it, if any.
+func (t *Template) ForReceiver(name string) *Template {
+	if rt, ok := t.receivers[name]; ok {
+		return rt
+	}
+	return t
+}
+
+// parseFiles parses the given files or the files matching the given glob patterns into tmpl. Patterns must match at
+// least one file.
+func parseFiles(tmpl *template.Template, patterns []string) (*template.Template, error) {
+	var files []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "template pattern %s", pattern)
+		}
+		if len(matches) == 0 {
+			// Let ParseFiles report the missing file.
+			matches = []string{pattern}
+		}
+		files = append(files, matches...)
+	}
+	return tmpl.ParseFiles(files...)
+}
+
 func SimpleTemplate() *Template {
 	return &Template{logger: log.NewNopLogger(), tmpl: template.New("").Option("missingkey=zero").Funcs(funcs)}
 }
@@ -86,6 +142,25 @@ func SimpleTemplate() *Template {
 // defined in t.tmpl (so they may be referenced and used) and applies the resulting template to the specified data
 // object, returning the output as a string .
 func (t *Template) Execute(text string, data interface{}) (string, error) {
+	return t.execute(text, data, "missingkey=zero")
+}
+
+// ExecuteStrict is Execute failing on map keys missing from the data, which Execute renders as their zero value, that
+// is "<no value>" for maps of interface values such as decoded JSON documents. IsMissingKey tells these errors apart.
+func (t *Template) ExecuteStrict(text string, data interface{}) (string, error) {
+	return t.execute(text, data, "missingkey=error")
+}
+
+// IsMissingKey reports whether the error of ExecuteStrict is caused by a map key missing from the data.
+func IsMissingKey(err error) bool {
+	var execErr template.ExecError
+	return errors.As(err, &execErr) && strings.Contains(execErr.Error(), missingKeyMessage)
+}
+
+// missingKeyMessage is the error message of text/template for missing map keys with the missingkey=error option.
+const missingKeyMessage = "map has no entry for key"
+
+func (t *Template) execute(text string, data interface{}, missingKey string) (string, error) {
 	level.Debug(t.logger).Log("msg", "executing template", "template", text)
 	if !strings.Contains(text, "{{") {
 		level.Debug(t.logger).Log("msg", "returning unchanged")
@@ -97,7 +172,7 @@ func (t *Template) Execute(text string, data interface{}) (string, error) {
 		// There is literally no return flow in Clone that returns error.
 		return "", errors.Wrap(err, "parse clone tmpl")
 	}
-	tmpl, err = tmpl.New("").Parse(text)
+	tmpl, err = tmpl.Option(missingKey).New("").Parse(text)
 	if err != nil {
 		return "", errors.Wrapf(err, "parse template %s", text)
 	}
@@ -78,6 +78,50 @@ func TestLoadTemplate_InvalidTemplateContent(t *testing.T) {
 	require.Nil(t, tmpl)
 }
 
+func TestLoadTemplates_Globs(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "base.tmpl"), []byte(`{{ define "azdo.summary" }}base{{ end }}{{ define "azdo.description" }}base{{ end }}`), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(tmpDir, "lib"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "lib", "a.tmpl"), []byte(`{{ define "lib.a" }}a{{ end }}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "lib", "b.tmpl"), []byte(`{{ define "lib.b" }}b{{ end }}{{ define "azdo.description" }}lib{{ end }}`), 0644))
+
+	tmpl, err := LoadTemplates([]string{filepath.Join(tmpDir, "base.tmpl"), filepath.Join(tmpDir, "lib", "*.tmpl")}, log.NewNopLogger())
+	require.NoError(t, err)
+	result, err := tmpl.Execute(`{{ template "azdo.summary" . }} {{ template "lib.a" . }} {{ template "lib.b" . }} {{ template "azdo.description" . }}`, nil)
+	require.NoError(t, err)
+	require.Equal(t, "base a b lib", result)
+
+	_, err = LoadTemplates([]string{filepath.Join(tmpDir, "missing", "*.tmpl")}, log.NewNopLogger())
+	require.Error(t, err)
+}
+
+func TestTemplate_ForReceiver(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalFile := filepath.Join(tmpDir, "global.tmpl")
+	teamAFile := filepath.Join(tmpDir, "team-a.tmpl")
+	teamBFile := filepath.Join(tmpDir, "team-b.tmpl")
+	require.NoError(t, os.WriteFile(globalFile, []byte(`{{ define "azdo.summary" }}global{{ end }}{{ define "azdo.description" }}global{{ end }}`), 0644))
+	require.NoError(t, os.WriteFile(teamAFile, []byte(`{{ define "azdo.description" }}team a{{ end }}`), 0644))
+	require.NoError(t, os.WriteFile(teamBFile, []byte(`{{ define "azdo.description" }}team b{{ end }}`), 0644))
+
+	tmpl, err := LoadTemplate(globalFile, log.NewNopLogger())
+	require.NoError(t, err)
+	require.NoError(t, tmpl.AddReceiver("team-a", []string{teamAFile}))
+	require.NoError(t, tmpl.AddReceiver("team-b", []string{teamBFile}))
+	require.Error(t, tmpl.AddReceiver("team-c", []string{filepath.Join(tmpDir, "missing.tmpl")}))
+
+	for receiver, expected := range map[string]string{
+		"team-a": "global team a",
+		"team-b": "global team b",
+		"shared": "global global",
+	} {
+		result, err := tmpl.ForReceiver(receiver).Execute(`{{ template "azdo.summary" . }} {{ template "azdo.description" . }}`, nil)
+		require.NoError(t, err)
+		require.Equal(t, expected, result, receiver)
+	}
+	require.Same(t, tmpl, tmpl.ForReceiver("team-c"))
+}
+
 func TestSimpleTemplate(t *testing.T) {
 	tmpl := SimpleTemplate()
 
@@ -293,6 +337,63 @@ func TestTemplate_Execute_WithAlertmanagerData(t *testing.T) {
 	}
 }
 
+func TestTemplate_Execute_WithGrafanaData(t *testing.T) {
+	tmpl := SimpleTemplate()
+
+	data := &alertmanager.Data{
+		Status: alertmanager.AlertFiring,
+		OrgID:  1,
+		State:  "alerting",
+		Title:  "[FIRING:1] HighCPU",
+		Alerts: alertmanager.Alerts{
+			{
+				Status:       alertmanager.AlertFiring,
+				Fingerprint:  "test-fp",
+				Labels:       alertmanager.KV{"alertname": "HighCPU"},
+				Values:       map[string]float64{"B": 92.5, "C": 1},
+				ValueString:  "[ var='B' labels={} value=92.5 ]",
+				DashboardURL: "http://grafana/d/dash",
+				PanelURL:     "http://grafana/d/dash?viewPanel=2",
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{
+			name:     "grafana detection",
+			template: `{{ if .IsGrafana }}{{ .Title }} ({{ .State }}){{ end }}`,
+			expected: "[FIRING:1] HighCPU (alerting)",
+		},
+		{
+			name:     "panel link",
+			template: `{{ range .Alerts }}<a href="{{ .PanelURL }}">panel</a>{{ end }}`,
+			expected: `<a href="http://grafana/d/dash?viewPanel=2">panel</a>`,
+		},
+		{
+			name:     "evaluated values",
+			template: `{{ range .Alerts }}{{ range $ref, $value := .Values }}{{ $ref }}={{ $value }} {{ end }}{{ end }}`,
+			expected: "B=92.5 C=1 ",
+		},
+		{
+			name:     "single value",
+			template: `{{ (index .Alerts 0).Values.B }}`,
+			expected: "92.5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tmpl.Execute(tt.template, data)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
 func TestTemplate_Execute_InvalidTemplate(t *testing.T) {
 	tmpl := SimpleTemplate()
 
@@ -429,6 +530,28 @@ func TestTemplate_Execute_MissingKeyHandling(t *testing.T) {
 	}
 }
 
+func TestTemplate_ExecuteStrict(t *testing.T) {
+	tmpl := SimpleTemplate()
+	data := map[string]interface{}{"present": "value"}
+
+	result, err := tmpl.ExecuteStrict("Value: '{{ .present }}'", data)
+	require.NoError(t, err)
+	require.Equal(t, "Value: 'value'", result)
+
+	_, err = tmpl.ExecuteStrict("Value: '{{ .missing }}'", data)
+	require.Error(t, err)
+	require.True(t, IsMissingKey(err))
+
+	_, err = tmpl.ExecuteStrict(`{{ template "undefined" . }}`, data)
+	require.Error(t, err)
+	require.False(t, IsMissingKey(err))
+
+	// Execute is not affected.
+	result, err = tmpl.Execute("Value: '{{ .missing }}'", map[string]string{})
+	require.NoError(t, err)
+	require.Equal(t, "Value: ''", result)
+}
+
 // Add a separate test for struct field access (which still causes errors)
 func TestTemplate_Execute_StructFieldAccess(t *testing.T) {
 	tmpl := SimpleTemplate()